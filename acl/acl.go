@@ -0,0 +1,296 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+// Package acl implements a five-tuple packet classifier for firewall
+// rules and access control lists on top of bart routing tables.
+//
+// A rule matches on the source and destination prefix, the IP protocol
+// and the source and destination port ranges. The rules are ordered,
+// the first matching rule wins:
+//
+//	c, err := acl.New(
+//	    acl.Rule[string]{Dst: netip.MustParsePrefix("10.0.0.0/8"), Proto: 6, DstPorts: acl.PortRange{Lo: 443, Hi: 443}, Value: "allow"},
+//	    acl.Rule[string]{Value: "deny"},
+//	)
+//	if err != nil { ... }
+//
+//	action, ok := c.Lookup(acl.Packet{Src: src, Dst: dst, Proto: 6, SrcPort: 51000, DstPort: 443})
+//
+// The classifier uses the bit vector scheme of Lakshman and Stiliadis:
+// every dimension is searched on its own, the prefix dimensions with a
+// bart table, the port dimensions with a binary search over the
+// elementary intervals. Each search returns the precomputed set of rules
+// matching in this dimension, the first rule in the intersection of all
+// five sets is the result. A lookup is allocation-free, the memory grows
+// with the number of distinct prefixes and port ranges times the number
+// of rules.
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math/bits"
+	"net/netip"
+	"slices"
+
+	"github.com/admpub/bart"
+)
+
+// ErrInvalidRule is returned by [New] for a malformed rule.
+var ErrInvalidRule = errors.New("acl: invalid rule")
+
+// PortRange is an inclusive range of transport layer ports.
+// The zero value matches any port.
+type PortRange struct {
+	Lo uint16
+	Hi uint16
+}
+
+// isAny reports whether the range is the zero value.
+func (r PortRange) isAny() bool {
+	return r == PortRange{}
+}
+
+// Rule is a classification rule. The zero value of a field is a wildcard,
+// a Rule with only a Value matches every packet.
+type Rule[V any] struct {
+	Src      netip.Prefix // source prefix, invalid matches any
+	Dst      netip.Prefix // destination prefix, invalid matches any
+	Proto    uint8        // IP protocol number, 0 matches any
+	SrcPorts PortRange    // source ports, the zero value matches any
+	DstPorts PortRange    // destination ports, the zero value matches any
+	Value    V
+}
+
+// Packet is the five-tuple of a packet to classify.
+type Packet struct {
+	Src     netip.Addr
+	Dst     netip.Addr
+	Proto   uint8
+	SrcPort uint16
+	DstPort uint16
+}
+
+// Classifier is an immutable, compiled list of rules.
+// It is safe for concurrent use by any number of readers.
+type Classifier[V any] struct {
+	rules []Rule[V]
+
+	src   *bart.Table[bitmap]
+	dst   *bart.Table[bitmap]
+	proto [256]bitmap
+	sport portDim
+	dport portDim
+}
+
+// New compiles the rules into a classifier, the first matching rule
+// wins. It returns an error wrapping [ErrInvalidRule] if a port range is
+// inverted or the source and destination prefix are of different
+// address families.
+func New[V any](rules ...Rule[V]) (*Classifier[V], error) {
+	for i, r := range rules {
+		if r.SrcPorts.Lo > r.SrcPorts.Hi || r.DstPorts.Lo > r.DstPorts.Hi {
+			return nil, fmt.Errorf("%w: rule %d, inverted port range", ErrInvalidRule, i)
+		}
+		if r.Src.IsValid() && r.Dst.IsValid() && r.Src.Addr().Is4() != r.Dst.Addr().Is4() {
+			return nil, fmt.Errorf("%w: rule %d, mixed address families", ErrInvalidRule, i)
+		}
+	}
+
+	c := &Classifier[V]{rules: slices.Clone(rules)}
+	n := len(rules)
+
+	c.src = newPrefixDim(n, func(i int) netip.Prefix { return rules[i].Src })
+	c.dst = newPrefixDim(n, func(i int) netip.Prefix { return rules[i].Dst })
+	c.sport = newPortDim(n, func(i int) PortRange { return rules[i].SrcPorts })
+	c.dport = newPortDim(n, func(i int) PortRange { return rules[i].DstPorts })
+
+	wildcard := newBitmap(n)
+	for i, r := range rules {
+		if r.Proto == 0 {
+			wildcard.set(i)
+		}
+	}
+	for proto := range c.proto {
+		c.proto[proto] = wildcard
+	}
+	var own [256]bool
+	for i, r := range rules {
+		if r.Proto == 0 {
+			continue
+		}
+		if !own[r.Proto] {
+			c.proto[r.Proto] = slices.Clone(wildcard)
+			own[r.Proto] = true
+		}
+		c.proto[r.Proto].set(i)
+	}
+
+	return c, nil
+}
+
+// Len returns the number of rules.
+func (c *Classifier[V]) Len() int {
+	return len(c.rules)
+}
+
+// Rule returns the rule with index i.
+func (c *Classifier[V]) Rule(i int) Rule[V] {
+	return c.rules[i]
+}
+
+// Lookup returns the value of the first rule matching the packet.
+func (c *Classifier[V]) Lookup(pkt Packet) (val V, ok bool) {
+	sets, ok := c.sets(pkt)
+	if !ok {
+		return
+	}
+
+	for w := range sets[0] {
+		if word := sets.and(w); word != 0 {
+			return c.rules[w<<6+bits.TrailingZeros64(word)].Value, true
+		}
+	}
+	return val, false
+}
+
+// Matches returns an iterator over the indices and values of all rules
+// matching the packet, in rule order.
+func (c *Classifier[V]) Matches(pkt Packet) iter.Seq2[int, V] {
+	return func(yield func(int, V) bool) {
+		sets, ok := c.sets(pkt)
+		if !ok {
+			return
+		}
+
+		for w := range sets[0] {
+			for word := sets.and(w); word != 0; word &= word - 1 {
+				i := w<<6 + bits.TrailingZeros64(word)
+				if !yield(i, c.rules[i].Value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// dimSets are the matching rule sets of the five dimensions.
+type dimSets [5]bitmap
+
+// and returns word w of the intersection of all sets.
+func (s *dimSets) and(w int) uint64 {
+	return s[0][w] & s[1][w] & s[2][w] & s[3][w] & s[4][w]
+}
+
+// sets searches all dimensions, ok is false if an address matches no rule.
+func (c *Classifier[V]) sets(pkt Packet) (s dimSets, ok bool) {
+	if s[0], ok = c.src.Lookup(pkt.Src); !ok {
+		return
+	}
+	if s[1], ok = c.dst.Lookup(pkt.Dst); !ok {
+		return
+	}
+	s[2] = c.proto[pkt.Proto]
+	s[3] = c.sport.lookup(pkt.SrcPort)
+	s[4] = c.dport.lookup(pkt.DstPort)
+	return s, true
+}
+
+// bitmap is a set of rule indices.
+type bitmap []uint64
+
+func newBitmap(n int) bitmap {
+	return make(bitmap, (n+63)>>6)
+}
+
+func (b bitmap) set(i int) {
+	b[i>>6] |= 1 << (i & 63)
+}
+
+func (b bitmap) or(o bitmap) {
+	for w := range b {
+		b[w] |= o[w]
+	}
+}
+
+// newPrefixDim builds a prefix dimension, the longest prefix match for
+// an address returns all rules with a prefix covering the address.
+// A wildcard is stored as default route for both address families.
+func newPrefixDim(n int, prefixOf func(int) netip.Prefix) *bart.Table[bitmap] {
+	var exact bart.Table[bitmap]
+
+	add := func(pfx netip.Prefix, i int) {
+		bm, ok := exact.Get(pfx)
+		if !ok {
+			bm = newBitmap(n)
+			exact.Insert(pfx, bm)
+		}
+		bm.set(i)
+	}
+
+	for i := range n {
+		pfx := prefixOf(i)
+		if !pfx.IsValid() {
+			add(netip.MustParsePrefix("0.0.0.0/0"), i)
+			add(netip.MustParsePrefix("::/0"), i)
+			continue
+		}
+		add(pfx.Masked(), i)
+	}
+
+	// fold the rules of all supernets into every prefix, the LPM
+	// returns the complete set of matching rules
+	d := new(bart.Table[bitmap])
+	for pfx := range exact.All() {
+		bm := newBitmap(n)
+		for _, super := range exact.Supernets(pfx) {
+			bm.or(super)
+		}
+		d.Insert(pfx, bm)
+	}
+
+	return d
+}
+
+// portDim is a port range dimension, split into elementary intervals
+// with the set of rules matching each interval.
+type portDim struct {
+	starts []int // sorted start ports of the elementary intervals, starts[0] == 0
+	sets   []bitmap
+}
+
+func newPortDim(n int, rangeOf func(int) PortRange) portDim {
+	starts := []int{0}
+	for i := range n {
+		if r := rangeOf(i); !r.isAny() {
+			starts = append(starts, int(r.Lo), int(r.Hi)+1)
+		}
+	}
+	slices.Sort(starts)
+	starts = slices.Compact(starts)
+	if starts[len(starts)-1] > 0xffff {
+		starts = starts[:len(starts)-1]
+	}
+
+	d := portDim{starts: starts, sets: make([]bitmap, len(starts))}
+	for k, start := range starts {
+		bm := newBitmap(n)
+		for i := range n {
+			if r := rangeOf(i); r.isAny() || int(r.Lo) <= start && start <= int(r.Hi) {
+				bm.set(i)
+			}
+		}
+		d.sets[k] = bm
+	}
+
+	return d
+}
+
+func (d *portDim) lookup(port uint16) bitmap {
+	k, found := slices.BinarySearch(d.starts, int(port))
+	if !found {
+		k--
+	}
+	return d.sets[k]
+}
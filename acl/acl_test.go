@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package acl
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+)
+
+var (
+	mpp = netip.MustParsePrefix
+	mpa = netip.MustParseAddr
+)
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(
+		Rule[string]{Dst: mpp("10.0.0.0/8"), Proto: 6, DstPorts: PortRange{443, 443}, Value: "https"},
+		Rule[string]{Src: mpp("192.168.0.0/16"), Dst: mpp("10.1.0.0/16"), Value: "lan"},
+		Rule[string]{Proto: 17, SrcPorts: PortRange{1024, 65535}, DstPorts: PortRange{53, 53}, Value: "dns"},
+		Rule[string]{Src: mpp("2001:db8::/32"), Value: "v6"},
+		Rule[string]{Dst: mpp("0.0.0.0/0"), Value: "deny"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pkt  Packet
+		want string
+		ok   bool
+	}{
+		{Packet{mpa("192.168.1.1"), mpa("10.1.2.3"), 6, 50000, 443}, "https", true},
+		{Packet{mpa("192.168.1.1"), mpa("10.1.2.3"), 6, 50000, 80}, "lan", true},
+		{Packet{mpa("172.16.0.1"), mpa("10.1.2.3"), 6, 50000, 80}, "deny", true},
+		{Packet{mpa("172.16.0.1"), mpa("8.8.8.8"), 17, 40000, 53}, "dns", true},
+		{Packet{mpa("172.16.0.1"), mpa("8.8.8.8"), 17, 53, 53}, "deny", true},
+		{Packet{mpa("2001:db8::1"), mpa("2001:db9::1"), 6, 1, 2}, "v6", true},
+		{Packet{mpa("2001:db9::1"), mpa("2001:db8::1"), 6, 1, 2}, "", false},
+		{Packet{Dst: mpa("10.0.0.1")}, "", false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := c.Lookup(tt.pkt); got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%v), got: (%q, %v), want: (%q, %v)", tt.pkt, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	var got []int
+	for i := range c.Matches(Packet{mpa("192.168.1.1"), mpa("10.1.2.3"), 6, 50000, 443}) {
+		got = append(got, i)
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 4 {
+		t.Errorf("Matches, got: %v, want: [0 1 4]", got)
+	}
+
+	if c.Len() != 5 || c.Rule(2).Value != "dns" {
+		t.Errorf("Len or Rule, unexpected result")
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(Rule[int]{DstPorts: PortRange{80, 79}}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("inverted port range, got: %v, want: %v", err, ErrInvalidRule)
+	}
+	if _, err := New(Rule[int]{Src: mpp("10.0.0.0/8"), Dst: mpp("::/0")}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("mixed address families, got: %v, want: %v", err, ErrInvalidRule)
+	}
+
+	c, err := New[int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Lookup(Packet{Src: mpa("10.0.0.1"), Dst: mpa("10.0.0.2")}); ok {
+		t.Error("empty classifier, expected no match")
+	}
+}
+
+// compare the classifier with a linear search over random rules
+func TestLookupRandom(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	randPrefix := func() netip.Prefix {
+		if prng.IntN(4) == 0 {
+			return netip.Prefix{}
+		}
+		ip := netip.AddrFrom4([4]byte{10, byte(prng.IntN(4)), byte(prng.IntN(4)), 0})
+		return netip.PrefixFrom(ip, 8+prng.IntN(17)).Masked()
+	}
+	randPorts := func() PortRange {
+		if prng.IntN(3) == 0 {
+			return PortRange{}
+		}
+		lo := uint16(prng.IntN(100))
+		return PortRange{lo, lo + uint16(prng.IntN(50))}
+	}
+
+	rules := make([]Rule[int], 200)
+	for i := range rules {
+		rules[i] = Rule[int]{
+			Src:      randPrefix(),
+			Dst:      randPrefix(),
+			Proto:    uint8(prng.IntN(3)),
+			SrcPorts: randPorts(),
+			DstPorts: randPorts(),
+			Value:    i,
+		}
+	}
+
+	c, err := New(rules...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	portMatch := func(r PortRange, port uint16) bool {
+		return r.isAny() || r.Lo <= port && port <= r.Hi
+	}
+	pfxMatch := func(pfx netip.Prefix, ip netip.Addr) bool {
+		return !pfx.IsValid() || pfx.Contains(ip)
+	}
+
+	for range 10_000 {
+		pkt := Packet{
+			Src:     netip.AddrFrom4([4]byte{10, byte(prng.IntN(4)), byte(prng.IntN(4)), 1}),
+			Dst:     netip.AddrFrom4([4]byte{10, byte(prng.IntN(4)), byte(prng.IntN(4)), 1}),
+			Proto:   uint8(prng.IntN(3)),
+			SrcPort: uint16(prng.IntN(160)),
+			DstPort: uint16(prng.IntN(160)),
+		}
+
+		want, wantOK := -1, false
+		for i, r := range rules {
+			if pfxMatch(r.Src, pkt.Src) && pfxMatch(r.Dst, pkt.Dst) &&
+				(r.Proto == 0 || r.Proto == pkt.Proto) &&
+				portMatch(r.SrcPorts, pkt.SrcPort) && portMatch(r.DstPorts, pkt.DstPort) {
+				want, wantOK = i, true
+				break
+			}
+		}
+
+		if got, ok := c.Lookup(pkt); ok != wantOK || ok && got != want {
+			t.Fatalf("Lookup(%v), got: (%d, %v), want: (%d, %v)", pkt, got, ok, want, wantOK)
+		}
+	}
+}
+
+func TestLookupAllocs(t *testing.T) {
+	c, err := New(
+		Rule[int]{Dst: mpp("10.0.0.0/8"), Proto: 6, DstPorts: PortRange{443, 443}, Value: 1},
+		Rule[int]{Value: 2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := Packet{mpa("192.168.1.1"), mpa("10.1.2.3"), 6, 50000, 443}
+	if allocs := testing.AllocsPerRun(100, func() { c.Lookup(pkt) }); allocs != 0 {
+		t.Errorf("Lookup, got: %v allocs, want: 0", allocs)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+
+	"github.com/admpub/bart/internal/value"
+)
+
+// Aggregate returns a new table with the minimal set of prefixes that is
+// lookup-equivalent to t, the classic route summarization:
+//
+//   - a prefix with a value mergeable with the value of its closest
+//     covering prefix is redundant and removed,
+//   - two sibling prefixes with mergeable values are replaced by their
+//     parent prefix, e.g. 10.0.0.0/25 and 10.0.0.128/25 by 10.0.0.0/24.
+//
+// The rules are applied repeatedly, bottom-up, until nothing is left
+// to merge. The receiver is not modified.
+//
+// merge reports whether two values are mergeable and returns the merged
+// value, e.g. to combine next hops with different metrics. If merge is
+// nil, equal values are mergeable, compared with their Equal method,
+// if implemented, or reflect.DeepEqual. With a custom merge, the result
+// is only equivalent in the sense of merge.
+func Aggregate[V any](t *Table[V], merge func(a, b V) (V, bool)) *Table[V] {
+	if t == nil {
+		return new(Table[V])
+	}
+	if merge == nil {
+		merge = func(a, b V) (V, bool) { return a, value.Equal(a, b) }
+	}
+
+	result := t.Clone()
+
+	// the prefixes bucketed by length, buckets are processed from
+	// the longest to the shortest length, merged parents are appended
+	// to the bucket of the next round
+	var byLen [129][]netip.Prefix
+	for pfx := range result.All() {
+		byLen[pfx.Bits()] = append(byLen[pfx.Bits()], pfx)
+	}
+
+	for bits := len(byLen) - 1; bits > 0; bits-- {
+		for _, pfx := range byLen[bits] {
+			val, ok := result.Get(pfx)
+			if !ok {
+				// already merged with its sibling
+				continue
+			}
+
+			parent := netip.PrefixFrom(pfx.Addr(), bits-1).Masked()
+
+			// redundant, the closest covering prefix has a mergeable value
+			if super, superVal, ok := result.LookupPrefixLPM(parent); ok {
+				if merged, ok := merge(superVal, val); ok {
+					result.Delete(pfx)
+					result.Insert(super, merged)
+					continue
+				}
+			}
+
+			sibling, _ := splitPrefix(parent)
+			if sibling == pfx {
+				_, sibling = splitPrefix(parent)
+			}
+
+			sibVal, ok := result.Get(sibling)
+			if !ok {
+				continue
+			}
+
+			merged, ok := merge(val, sibVal)
+			if !ok {
+				continue
+			}
+
+			// the value of an existing parent is fully shadowed by the siblings
+			if _, exists := result.Get(parent); !exists {
+				byLen[bits-1] = append(byLen[bits-1], parent)
+			}
+
+			result.Delete(pfx)
+			result.Delete(sibling)
+			result.Insert(parent, merged)
+		}
+
+		byLen[bits] = nil
+	}
+
+	return result
+}
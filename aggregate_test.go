@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   []string // prefix=value
+		want []string
+	}{
+		{
+			name: "siblings",
+			in:   []string{"10.0.0.0/26=1", "10.0.0.64/26=1", "10.0.0.128/26=1", "10.0.0.192/26=1"},
+			want: []string{"10.0.0.0/24=1"},
+		},
+		{
+			name: "redundant",
+			in:   []string{"10.0.0.0/8=1", "10.1.0.0/16=1", "10.1.1.0/24=2", "10.1.1.128/25=1"},
+			want: []string{"10.0.0.0/8=1", "10.1.1.0/24=2", "10.1.1.128/25=1"},
+		},
+		{
+			name: "shadowed parent",
+			in:   []string{"10.0.0.0/24=2", "10.0.0.0/25=1", "10.0.0.128/25=1"},
+			want: []string{"10.0.0.0/24=1"},
+		},
+		{
+			name: "merged parent is redundant",
+			in:   []string{"10.0.0.0/16=1", "10.0.0.0/25=1", "10.0.0.128/25=1", "10.0.1.0/24=2"},
+			want: []string{"10.0.0.0/16=1", "10.0.1.0/24=2"},
+		},
+		{
+			name: "not mergeable",
+			in:   []string{"10.0.0.0/25=1", "10.0.0.128/25=2", "10.0.1.0/25=1", "2001:db8::/33=1", "2001:db8:8000::/33=1"},
+			want: []string{"10.0.0.0/25=1", "10.0.0.128/25=2", "10.0.1.0/25=1", "2001:db8::/32=1"},
+		},
+		{
+			name: "default routes",
+			in:   []string{"0.0.0.0/1=1", "128.0.0.0/1=1", "::/0=1", "::/1=1"},
+			want: []string{"0.0.0.0/0=1", "::/0=1"},
+		},
+	}
+
+	parse := func(entries []string) *Table[int] {
+		tbl := new(Table[int])
+		for _, e := range entries {
+			var val int
+			i := len(e) - 1
+			for e[i] != '=' {
+				i--
+			}
+			for _, c := range e[i+1:] {
+				val = val*10 + int(c-'0')
+			}
+			tbl.Insert(mpp(e[:i]), val)
+		}
+		return tbl
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			in := parse(tt.in)
+			got := Aggregate(in, nil)
+			want := parse(tt.want)
+
+			if !got.Equal(want) {
+				t.Errorf("Aggregate, got:\n%s\nwant:\n%s", got, want)
+			}
+			if in.Size() != len(tt.in) {
+				t.Error("Aggregate, receiver modified")
+			}
+		})
+	}
+
+	if got := Aggregate[int](nil, nil); got.Size() != 0 {
+		t.Error("Aggregate(nil), expected empty table")
+	}
+}
+
+func TestAggregateMerge(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[[]string])
+	tbl.Insert(mpp("10.0.0.0/25"), []string{"a"})
+	tbl.Insert(mpp("10.0.0.128/25"), []string{"b"})
+	tbl.Insert(mpp("10.0.1.0/24"), []string{"c"})
+
+	// merge everything, collect the values
+	got := Aggregate(tbl, func(a, b []string) ([]string, bool) {
+		return slices.Sorted(slices.Values(append(slices.Clone(a), b...))), true
+	})
+
+	if val, ok := got.Get(mpp("10.0.0.0/23")); got.Size() != 1 || !ok || !slices.Equal(val, []string{"a", "b", "c"}) {
+		t.Errorf("Aggregate with merge, got:\n%s", got)
+	}
+}
+
+// the aggregated table is lookup-equivalent and not larger
+func TestAggregateEquivalent(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	for range 20 {
+		tbl := new(Table[int])
+		for range 500 {
+			ip := netip.AddrFrom4([4]byte{10, 0, byte(prng.IntN(4)), byte(prng.IntN(256))})
+			pfx := netip.PrefixFrom(ip, 18+prng.IntN(15)).Masked()
+			tbl.Insert(pfx, prng.IntN(2))
+		}
+
+		agg := Aggregate(tbl, nil)
+		if agg.Size() > tbl.Size() {
+			t.Fatalf("Aggregate, got size: %d, original: %d", agg.Size(), tbl.Size())
+		}
+
+		// exhaustive over 10.0.0.0/22 plus its neighbors
+		for i := range 4 * 256 * 3 {
+			ip := netip.AddrFrom4([4]byte{10, 0, byte(i / 256), byte(i)})
+			wantVal, wantOK := tbl.Lookup(ip)
+			if val, ok := agg.Lookup(ip); val != wantVal || ok != wantOK {
+				t.Fatalf("Lookup(%s), got: (%d, %v), want: (%d, %v)", ip, val, ok, wantVal, wantOK)
+			}
+		}
+
+		// idempotent
+		if again := Aggregate(agg, nil); !again.Equal(agg) {
+			t.Fatalf("Aggregate, not idempotent")
+		}
+	}
+}
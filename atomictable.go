@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"sync"
+	"sync/atomic"
+)
+
+// AtomicTable is a [Table] for dataplane-style usage: any number of
+// readers call Lookup, Contains, ... concurrently with writers, without
+// any locks on the read path.
+//
+// All updates are applied with the persistent (copy-on-write) methods,
+// only the nodes along the path to the modified prefix are copied, and
+// the new version is published with an atomic pointer swap. Readers
+// always see a consistent version, either before or after an update.
+// Writers are serialized with a mutex.
+//
+// Values of a pointer type V should implement the Cloner interface,
+// see the package documentation.
+//
+// The zero value is ready to use. An AtomicTable must not be copied after
+// first use.
+type AtomicTable[V any] struct {
+	mu  sync.Mutex // serializes the writers
+	ptr atomic.Pointer[Table[V]]
+}
+
+// Load returns the current version of the table, e.g. for iterations
+// or set operations on a consistent snapshot. It is never nil.
+//
+// The returned table is shared and must not be modified in-place,
+// use the persistent methods or [Table.Clone] first.
+func (a *AtomicTable[V]) Load() *Table[V] {
+	if t := a.ptr.Load(); t != nil {
+		return t
+	}
+	return new(Table[V])
+}
+
+// Store publishes t as the new version, e.g. after a full reload.
+// A nil t clears the table.
+//
+// The table t is taken over, it must not be modified in-place afterwards.
+func (a *AtomicTable[V]) Store(t *Table[V]) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.ptr.Store(t)
+}
+
+// update applies fn to the current version and publishes the result.
+func (a *AtomicTable[V]) update(fn func(cur *Table[V]) *Table[V]) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.ptr.Store(fn(a.Load()))
+}
+
+// Insert adds or updates a prefix-value pair, see [Table.InsertPersist].
+func (a *AtomicTable[V]) Insert(pfx netip.Prefix, val V) {
+	a.update(func(cur *Table[V]) *Table[V] {
+		return cur.InsertPersist(pfx, val)
+	})
+}
+
+// Delete removes the exact prefix pfx, see [Table.DeletePersist].
+func (a *AtomicTable[V]) Delete(pfx netip.Prefix) {
+	a.update(func(cur *Table[V]) *Table[V] {
+		return cur.DeletePersist(pfx)
+	})
+}
+
+// Modify inserts, updates or deletes the prefix pfx, see [Table.ModifyPersist].
+// The callback is called with the writer lock held and must not call
+// the mutating methods of a.
+func (a *AtomicTable[V]) Modify(pfx netip.Prefix, cb func(val V, ok bool) (newVal V, del bool)) {
+	a.update(func(cur *Table[V]) *Table[V] {
+		return cur.ModifyPersist(pfx, cb)
+	})
+}
+
+// Batch applies all mutations in fn as one new version, readers never
+// observe a partially applied batch. The callback is called with the
+// writer lock held and must not call the mutating methods of a.
+func (a *AtomicTable[V]) Batch(fn func(tx *Tx[V])) {
+	a.update(func(cur *Table[V]) *Table[V] {
+		tx := &Tx[V]{base: cur, tbl: cur}
+		fn(tx)
+		return tx.tbl
+	})
+}
+
+// Begin starts an optimistic transaction on the current version,
+// no lock is held while the transaction is open. Commit publishes the
+// batch atomically, but fails with [ErrTxConflict] if another writer
+// changed the table in the meantime; the caller may retry the batch.
+//
+// Use [AtomicTable.Batch] to apply a batch under the writer lock
+// without conflicts.
+func (a *AtomicTable[V]) Begin() *Tx[V] {
+	base := a.ptr.Load()
+
+	cur := base
+	if cur == nil {
+		cur = new(Table[V])
+	}
+
+	return &Tx[V]{
+		base: cur,
+		tbl:  cur,
+		commit: func(next *Table[V]) error {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+
+			if a.ptr.Load() != base {
+				return ErrTxConflict
+			}
+			a.ptr.Store(next)
+			return nil
+		},
+	}
+}
+
+// Get returns the value of the exact prefix pfx, see [Table.Get].
+func (a *AtomicTable[V]) Get(pfx netip.Prefix) (val V, exists bool) {
+	return a.Load().Get(pfx)
+}
+
+// Contains reports whether any stored prefix matches ip, see [Table.Contains].
+func (a *AtomicTable[V]) Contains(ip netip.Addr) bool {
+	return a.Load().Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (a *AtomicTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return a.Load().Lookup(ip)
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (a *AtomicTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	return a.Load().LookupPrefix(pfx)
+}
+
+// LookupPrefixLPM is like LookupPrefix, but also returns the matching
+// prefix, see [Table.LookupPrefixLPM].
+func (a *AtomicTable[V]) LookupPrefixLPM(pfx netip.Prefix) (lpmPfx netip.Prefix, val V, ok bool) {
+	return a.Load().LookupPrefixLPM(pfx)
+}
+
+// Size returns the prefix count of the current version.
+func (a *AtomicTable[V]) Size() int {
+	return a.Load().Size()
+}
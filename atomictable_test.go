@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAtomicTable(t *testing.T) {
+	t.Parallel()
+
+	var a AtomicTable[int]
+	if a.Size() != 0 || a.Contains(mpa("10.0.0.1")) {
+		t.Fatal("zero value, expected empty table")
+	}
+
+	a.Insert(mpp("10.0.0.0/8"), 1)
+	snap := a.Load()
+
+	a.Insert(mpp("10.1.0.0/16"), 2)
+	a.Modify(mpp("10.0.0.0/8"), func(val int, _ bool) (int, bool) { return val + 10, false })
+
+	if val, ok := a.Lookup(mpa("10.1.2.3")); !ok || val != 2 {
+		t.Errorf("Lookup, got: (%d, %v), want: (2, true)", val, ok)
+	}
+	if val, ok := a.Get(mpp("10.0.0.0/8")); !ok || val != 11 {
+		t.Errorf("Get, got: (%d, %v), want: (11, true)", val, ok)
+	}
+
+	// the snapshot is immutable
+	if snap.Size() != 1 {
+		t.Errorf("snapshot modified, got size: %d, want: 1", snap.Size())
+	}
+	if val, _ := snap.Get(mpp("10.0.0.0/8")); val != 1 {
+		t.Errorf("snapshot modified, got value: %d, want: 1", val)
+	}
+
+	a.Batch(func(tx *Tx[int]) {
+		tx.Delete(mpp("10.0.0.0/8"))
+		tx.Insert(mpp("2001:db8::/32"), 3)
+	})
+	if a.Size() != 2 || a.Contains(mpa("10.2.0.1")) {
+		t.Errorf("Batch, got size: %d, want: 2", a.Size())
+	}
+
+	a.Delete(mpp("10.1.0.0/16"))
+	if pfx, val, ok := a.LookupPrefixLPM(mpp("2001:db8:1::/48")); !ok || val != 3 || pfx != mpp("2001:db8::/32") {
+		t.Errorf("LookupPrefixLPM, got: (%s, %d, %v)", pfx, val, ok)
+	}
+
+	a.Store(nil)
+	if a.Size() != 0 {
+		t.Errorf("Store(nil), got size: %d, want: 0", a.Size())
+	}
+}
+
+// readers must never observe a partially applied batch
+func TestAtomicTableConcurrent(t *testing.T) {
+	t.Parallel()
+
+	pfxA, ipA := mpp("10.0.0.0/8"), mpa("10.0.0.1")
+	pfxB, ipB := mpp("2001:db8::/32"), mpa("2001:db8::1")
+
+	var a AtomicTable[int]
+	var done atomic.Bool
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() {
+				snap := a.Load()
+				valA, okA := snap.Lookup(ipA)
+				valB, okB := snap.Lookup(ipB)
+				if okA != okB || valA != valB {
+					t.Errorf("partial batch observed: (%d, %v) != (%d, %v)", valA, okA, valB, okB)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := range 2_000 {
+		a.Batch(func(tx *Tx[int]) {
+			if i%2 == 0 {
+				tx.Insert(pfxA, i)
+				tx.Insert(pfxB, i)
+				return
+			}
+			tx.Delete(pfxA)
+			tx.Delete(pfxB)
+		})
+	}
+
+	done.Store(true)
+	wg.Wait()
+}
+
+func TestAtomicTableBegin(t *testing.T) {
+	t.Parallel()
+
+	var a AtomicTable[int]
+
+	tx := a.Begin()
+	tx.Insert(mpp("10.0.0.0/8"), 1)
+	tx.Insert(mpp("2001:db8::/32"), 2)
+
+	if a.Size() != 0 {
+		t.Fatal("Tx, mutations visible before Commit")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit, unexpected error: %v", err)
+	}
+	if a.Size() != 2 {
+		t.Fatalf("Commit, got size: %d, want: 2", a.Size())
+	}
+
+	// conflicting writer
+	tx = a.Begin()
+	tx.Delete(mpp("10.0.0.0/8"))
+	a.Insert(mpp("192.168.0.0/16"), 3)
+
+	if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+		t.Fatalf("Commit, got: %v, want: %v", err, ErrTxConflict)
+	}
+	if a.Size() != 3 {
+		t.Fatalf("Commit with conflict, got size: %d, want: 3", a.Size())
+	}
+
+	tx = a.Begin()
+	tx.Delete(mpp("10.0.0.0/8"))
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback, unexpected error: %v", err)
+	}
+	if !a.Contains(mpa("10.0.0.1")) {
+		t.Fatal("Rollback, mutation visible")
+	}
+}
@@ -8,6 +8,7 @@ package bart
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"iter"
@@ -52,6 +53,58 @@ func (t *Table[V]) insert(pfx netip.Prefix, val V) {
 	t.sizeUpdate(is4, 1)
 }
 
+// InsertBulk inserts all prefix-value pairs of entries, e.g. to load a
+// full BGP table. For large input this is much faster than repeated
+// calls to Insert: the entries are distributed per stride in a radix
+// pass and every trie node is built in one go, without shifting the
+// elements of the sparse node slices on every insert.
+//
+// As with Insert, invalid prefixes are ignored and prefixes are
+// canonicalized. For duplicate prefixes the last value in entries wins.
+// The entries slice is not modified.
+func (t *Table[V]) InsertBulk(entries []PrefixValue[V]) {
+	if len(entries) == 0 {
+		return
+	}
+
+	n4 := 0
+	for _, e := range entries {
+		if e.Prefix.Addr().Is4() {
+			n4++
+		}
+	}
+
+	pfxs4, vals4 := make([]netip.Prefix, 0, n4), make([]V, 0, n4)
+	pfxs6, vals6 := make([]netip.Prefix, 0, len(entries)-n4), make([]V, 0, len(entries)-n4)
+
+	for _, e := range entries {
+		if !e.Prefix.IsValid() {
+			continue
+		}
+
+		pfx := e.Prefix.Masked()
+		if pfx.Addr().Is4() {
+			pfxs4 = append(pfxs4, pfx)
+			vals4 = append(vals4, e.Value)
+			continue
+		}
+		pfxs6 = append(pfxs6, pfx)
+		vals6 = append(vals6, e.Value)
+	}
+
+	o := new(Table[V])
+	o.size4 = o.root4.InsertBulk(pfxs4, vals4, make([]netip.Prefix, len(pfxs4)), make([]V, len(vals4)), 0)
+	o.size6 = o.root6.InsertBulk(pfxs6, vals6, make([]netip.Prefix, len(pfxs6)), make([]V, len(vals6)), 0)
+
+	// fast path, just take over the new trie
+	if t.size4 == 0 && t.size6 == 0 {
+		t.moveFrom(o)
+		return
+	}
+
+	t.Union(o)
+}
+
 // insertPersist is similar to insert but the receiver isn't modified.
 //
 // All nodes touched during insert are cloned and a new Table is returned.
@@ -128,6 +181,22 @@ func (t *Table[V]) Delete(pfx netip.Prefix) {
 	}
 }
 
+// GetAndDelete removes the exact prefix pfx from the table in-place and
+// returns the removed value, all in a single traversal.
+//
+// This is useful when resources attached to a route, e.g. next-hop
+// reference counts, must be released when the route is evicted.
+//
+// If pfx does not exist or pfx is invalid, the zero value for V and
+// exists=false is returned and the table is left unchanged.
+func (t *Table[V]) GetAndDelete(pfx netip.Prefix) (val V, exists bool) {
+	t.Modify(pfx, func(oldVal V, ok bool) (_ V, del bool) {
+		val, exists = oldVal, ok
+		return oldVal, true
+	})
+	return val, exists
+}
+
 // Get performs an exact-prefix lookup and returns whether the exact
 // prefix exists. The prefix is canonicalized (Masked) before lookup.
 //
@@ -208,6 +277,25 @@ func (t *Table[V]) DeletePersist(pfx netip.Prefix) *Table[V] {
 	return pt
 }
 
+// Update inserts or updates the value for pfx with the value returned by cb
+// and returns this new value. This is a read-modify-write in a single
+// traversal, e.g. for counters or aggregated values.
+//
+// The callback is called with the current value (or zero if not found)
+// and a boolean indicating whether the prefix exists:
+//
+//	// count the hits per prefix
+//	tbl.Update(pfx, func(val int, _ bool) int { return val + 1 })
+//
+// If pfx is invalid, cb is not called and the zero value is returned.
+func (t *Table[V]) Update(pfx netip.Prefix, cb func(val V, ok bool) V) (newVal V) {
+	t.Modify(pfx, func(val V, ok bool) (_ V, del bool) {
+		newVal = cb(val, ok)
+		return newVal, false
+	})
+	return newVal
+}
+
 // ModifyPersist is similar to Modify but the receiver isn't modified and
 // a new *Table is returned.
 func (t *Table[V]) ModifyPersist(pfx netip.Prefix, cb func(_ V, ok bool) (_ V, del bool)) *Table[V] {
@@ -283,6 +371,127 @@ func (t *Table[V]) Supernets(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
 	}
 }
 
+// LookupAll returns an iterator over all routes matching ip, not only the
+// longest prefix match.
+//
+// The iteration order is reverse-CIDR: from the longest prefix match (LPM),
+// e.g. a host route, towards the least-specific route, e.g. the default route.
+// This is equivalent to [Table.Supernets] of the host prefix of ip.
+//
+// Example:
+//
+//	for pfx, val := range table.LookupAll(netip.MustParseAddr("192.0.2.1")) {
+//	    fmt.Println("Matched by:", pfx, "->", val)
+//	}
+//
+// The iteration can be stopped early by breaking from the range loop.
+// Returns an empty iterator if ip is invalid.
+func (t *Table[V]) LookupAll(ip netip.Addr) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		if t == nil {
+			return
+		}
+		if !ip.IsValid() {
+			return
+		}
+
+		ip = ip.WithZone("")
+		n := t.rootNodeByVersion(ip.Is4())
+
+		n.Supernets(netip.PrefixFrom(ip, ip.BitLen()), yield)
+	}
+}
+
+// LookupPair performs the longest prefix match for both address families
+// in one call, e.g. for happy-eyeballs style decisions or dual-stack
+// policy checks, see [Table.Lookup].
+//
+// The addresses are looked up as given, an invalid address,
+// e.g. the zero value for a single-stack host, is not found.
+func (t *Table[V]) LookupPair(ip4, ip6 netip.Addr) (val4 V, ok4 bool, val6 V, ok6 bool) {
+	val4, ok4 = t.Lookup(ip4)
+	val6, ok6 = t.Lookup(ip6)
+	return val4, ok4, val6, ok6
+}
+
+// LookupBatch performs the longest prefix match for all addresses in ips
+// in one call, e.g. for flow processors, and stores the results in out
+// and ok at the same positions, see [Table.Lookup].
+//
+// The addresses are looked up in input order. Sorting or grouping them
+// by the first stride costs more than it saves in cache misses, the
+// upper trie levels are hot in the cache anyway.
+//
+// The slices out and ok must be at least as long as ips, otherwise
+// LookupBatch panics. No allocations are made.
+func (t *Table[V]) LookupBatch(ips []netip.Addr, out []V, ok []bool) {
+	if len(out) < len(ips) || len(ok) < len(ips) {
+		panic("bart: LookupBatch: out or ok shorter than ips")
+	}
+	out, ok = out[:len(ips)], ok[:len(ips)] // BCE
+
+	for i, ip := range ips {
+		out[i], ok[i] = t.Lookup(ip)
+	}
+}
+
+// ContainsBatch reports for all addresses in ips whether any stored
+// prefix covers the address and stores the results in out at the same
+// positions, e.g. for ACL-style checks, see [Table.Contains].
+// No values are accessed.
+//
+// The slice out must be at least as long as ips, otherwise
+// ContainsBatch panics. No allocations are made.
+func (t *Table[V]) ContainsBatch(ips []netip.Addr, out []bool) {
+	if len(out) < len(ips) {
+		panic("bart: ContainsBatch: out shorter than ips")
+	}
+	out = out[:len(ips)] // BCE
+
+	for i, ip := range ips {
+		out[i] = t.Contains(ip)
+	}
+}
+
+// LookupTrace is like [Table.Lookup], but reports every step of
+// the lookup to trace: each stride visited with the octet and prefix
+// index tested, path-compressed leaves and fringes, and the backtracking
+// through the visited nodes in search of the longest-prefix-match.
+//
+// It is meant for debugging and latency analysis, not for the fast path.
+// A nil trace is allowed, an invalid ip returns the zero value and false
+// without any steps.
+func (t *Table[V]) LookupTrace(ip netip.Addr, trace func(TraceStep)) (val V, ok bool) {
+	if t == nil || !ip.IsValid() {
+		return val, ok
+	}
+
+	cb := func(nodes.TraceEvent) {}
+	if trace != nil {
+		cb = func(e nodes.TraceEvent) {
+			trace(TraceStep{
+				Kind:   TraceKind(e.Kind),
+				Depth:  e.Depth,
+				Octet:  e.Octet,
+				Idx:    e.Idx,
+				Node:   e.Node,
+				Prefix: e.Prefix,
+				Hit:    e.Hit,
+			})
+		}
+	}
+
+	ip = ip.WithZone("")
+	return t.rootNodeByVersion(ip.Is4()).LookupTrace(ip, cb)
+}
+
+// ContainsPair reports for both address families in one call
+// whether any stored prefix covers the address, see [Table.Contains]
+// and [Table.LookupPair].
+func (t *Table[V]) ContainsPair(ip4, ip6 netip.Addr) (ok4, ok6 bool) {
+	return t.Contains(ip4), t.Contains(ip6)
+}
+
 // Subnets returns an iterator over all subnets of the given prefix
 // in natural CIDR sort order. This includes prefixes of the same length
 // (exact match) and longer (more specific) prefixes that are contained
@@ -313,6 +522,39 @@ func (t *Table[V]) Subnets(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
 	}
 }
 
+// WouldShadow returns an iterator over the existing more-specific entries
+// a proposed new aggregate pfx would cover, in natural CIDR sort order,
+// e.g. for validation UIs to warn before an insert. An existing entry for
+// pfx itself is not included.
+//
+// See also [Table.WouldBeShadowedBy] for the converse direction.
+func (t *Table[V]) WouldShadow(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		pfx = pfx.Masked()
+		for sub, val := range t.Subnets(pfx) {
+			if sub != pfx && !yield(sub, val) {
+				return
+			}
+		}
+	}
+}
+
+// WouldBeShadowedBy returns an iterator over the existing less-specific
+// entries covering a proposed new prefix pfx, from the most-specific
+// to the least-specific entry. Lookups for the addresses of pfx would
+// move from the first of these entries to pfx. An existing entry for
+// pfx itself is not included.
+func (t *Table[V]) WouldBeShadowedBy(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		pfx = pfx.Masked()
+		for super, val := range t.Supernets(pfx) {
+			if super != pfx && !yield(super, val) {
+				return
+			}
+		}
+	}
+}
+
 // OverlapsPrefix reports whether any prefix in the routing table overlaps with
 // the given prefix. Two prefixes overlap if they share any IP addresses.
 //
@@ -373,6 +615,49 @@ func (t *Table[V]) Overlaps6(o *Table[V]) bool {
 	return t.root6.Overlaps(&o.root6, 0)
 }
 
+// OverlapsIn reports whether the receiver and the other table overlap
+// anywhere inside the region pfx.
+//
+// A route covering the whole region is checked with OverlapsPrefix.
+// Otherwise both tries are only descended along pfx to the node owning
+// the region and compared in lockstep from there, as in
+// [Table.Overlaps], the routes outside the region are never visited.
+//
+// If pfx is invalid or o is nil, false is returned.
+func (t *Table[V]) OverlapsIn(pfx netip.Prefix, o *Table[V]) bool {
+	if o == nil || !pfx.IsValid() {
+		return false
+	}
+
+	// canonicalize the prefix
+	pfx = pfx.Masked()
+
+	// A route covering the whole region overlaps with
+	// everything the other table holds inside the region.
+	if _, ok := t.LookupPrefix(pfx); ok {
+		return o.OverlapsPrefix(pfx)
+	}
+	if _, ok := o.LookupPrefix(pfx); ok {
+		return t.OverlapsPrefix(pfx)
+	}
+
+	// No route covers the whole region, all remaining
+	// candidates are subnets of pfx.
+	is4 := pfx.Addr().Is4()
+
+	n := t.rootNodeByVersion(is4).SubtreeView(pfx)
+	if n.IsEmpty() {
+		return false
+	}
+
+	m := o.rootNodeByVersion(is4).SubtreeView(pfx)
+	if m.IsEmpty() {
+		return false
+	}
+
+	return n.Overlaps(m, 0)
+}
+
 // Union merges another routing table into the receiver table, modifying it in-place.
 //
 // All prefixes and values from the other table (o) are inserted into the receiver.
@@ -435,6 +720,123 @@ func (t *Table[V]) UnionPersist(o *Table[V]) *Table[V] {
 	return pt
 }
 
+// Intersect returns a new table with the prefixes present in both t and o,
+// e.g. for "routes present in RIB and FIB" consistency reports.
+// The receiver and o are not modified.
+//
+// The value for a common prefix is picked by merge, with the value
+// of t as a and the value of o as b. If merge is nil the value of t is used.
+func (t *Table[V]) Intersect(o *Table[V], merge func(pfx netip.Prefix, a, b V) V) *Table[V] {
+	res := new(Table[V])
+	if t == nil || o == nil {
+		return res
+	}
+
+	cloneFn := value.CloneFnFactory[V]()
+
+	// iterate over the smaller table
+	small, large, swapped := t, o, false
+	if o.Size() < t.Size() {
+		small, large, swapped = o, t, true
+	}
+
+	for pfx, sv := range small.All() {
+		lv, ok := large.Get(pfx)
+		if !ok {
+			continue
+		}
+
+		a, b := sv, lv
+		if swapped {
+			a, b = lv, sv
+		}
+
+		val := a
+		if merge != nil {
+			val = merge(pfx, a, b)
+		} else if cloneFn != nil {
+			val = cloneFn(val)
+		}
+
+		res.insert(pfx, val)
+	}
+
+	return res
+}
+
+// Difference returns a new table with the prefixes of t not present in o,
+// by exact prefix, e.g. to reconcile routes: t.Difference(o) are the
+// routes to install, o.Difference(t) the stale routes to remove.
+// The receiver and o are not modified.
+func (t *Table[V]) Difference(o *Table[V]) *Table[V] {
+	if t == nil {
+		return new(Table[V])
+	}
+	if o == nil || o.Size() == 0 {
+		return t.Clone()
+	}
+
+	return t.Filter(func(pfx netip.Prefix, _ V) bool {
+		_, ok := o.Get(pfx)
+		return !ok
+	})
+}
+
+// SymmetricDifference returns the prefixes present in exactly one of t and o,
+// by exact prefix, split by origin, e.g. for "what changed between these
+// RIB snapshots" tooling. The receiver and o are not modified.
+func (t *Table[V]) SymmetricDifference(o *Table[V]) (onlyT, onlyO *Table[V]) {
+	return t.Difference(o), o.Difference(t)
+}
+
+// UnionWith is like [Table.Union] but duplicates are resolved by merge,
+// with the value of the receiver as existing and the value of o as
+// incoming, e.g. to accumulate next-hop sets or keep the lowest metric.
+// If merge is nil, UnionWith is equivalent to [Table.Union].
+//
+// Values of o are cloned before insertion or merge,
+// if V implements the Clone method, see [Table.Clone].
+//
+// For o == t every prefix is a duplicate and its value is merged
+// with a clone of itself.
+func (t *Table[V]) UnionWith(o *Table[V], merge func(existing, incoming V) V) {
+	if merge == nil {
+		t.Union(o)
+		return
+	}
+	if o == nil || o.Size() == 0 {
+		return
+	}
+
+	cloneFn := value.CloneFnFactory[V]()
+
+	entries := o.All()
+	if o == t {
+		// snapshot, t is modified during the iteration
+		snapshot := t.AppendEntries(nil)
+		entries = func(yield func(netip.Prefix, V) bool) {
+			for _, e := range snapshot {
+				if !yield(e.Prefix, e.Value) {
+					return
+				}
+			}
+		}
+	}
+
+	for pfx, incoming := range entries {
+		if cloneFn != nil {
+			incoming = cloneFn(incoming)
+		}
+
+		t.Modify(pfx, func(existing V, ok bool) (_ V, del bool) {
+			if !ok {
+				return incoming, false
+			}
+			return merge(existing, incoming), false
+		})
+	}
+}
+
 // Equal checks whether two tables are structurally and semantically equal.
 // It ensures both trees (IPv4-based and IPv6-based) have the same sizes and
 // recursively compares their root nodes.
@@ -496,6 +898,208 @@ func (t *Table[V]) Clone() *Table[V] {
 	return c
 }
 
+// Filter returns a new table containing only the prefixes for which
+// pred returns true, the receiver is not modified.
+//
+// Depending on the selectivity of pred, the result is either built
+// from the matching entries or the table is cloned wholesale and
+// the rejected entries are deleted, whichever is cheaper.
+func (t *Table[V]) Filter(pred func(pfx netip.Prefix, val V) bool) *Table[V] {
+	if t == nil {
+		return nil
+	}
+
+	var keep, drop []netip.Prefix
+	var vals []V
+	for pfx, val := range t.All() {
+		if pred(pfx, val) {
+			keep = append(keep, pfx)
+			vals = append(vals, val)
+		} else {
+			drop = append(drop, pfx)
+		}
+	}
+
+	if len(drop) < len(keep) {
+		c := t.Clone()
+		for _, pfx := range drop {
+			c.Delete(pfx)
+		}
+		return c
+	}
+
+	cloneFn := value.CloneFnFactory[V]()
+
+	c := new(Table[V])
+	for i, pfx := range keep {
+		val := vals[i]
+		if cloneFn != nil {
+			val = cloneFn(val)
+		}
+		c.insert(pfx, val)
+	}
+
+	return c
+}
+
+// CloneSubtree returns a new table containing only the entries covered
+// by pfx, including pfx itself, e.g. to hand per-region or per-customer
+// slices of a table to workers. The result is independent of the receiver,
+// the values are cloned as in [Table.Clone].
+//
+// The trie is copied node by node: the path down to the node owning pfx
+// is copied, the covered subtrees below are cloned, no prefix is
+// reinserted. An invalid pfx returns an empty table.
+func (t *Table[V]) CloneSubtree(pfx netip.Prefix) *Table[V] {
+	if t == nil {
+		return nil
+	}
+
+	c := new(Table[V])
+	if !pfx.IsValid() {
+		return c
+	}
+	pfx = pfx.Masked()
+
+	cloneFn := value.CloneFnFactory[V]()
+
+	if pfx.Addr().Is4() {
+		root, size := t.root4.CloneSubtree(cloneFn, pfx)
+		c.root4 = *root
+		c.size4 = size
+	} else {
+		root, size := t.root6.CloneSubtree(cloneFn, pfx)
+		c.root6 = *root
+		c.size6 = size
+	}
+
+	return c
+}
+
+// Clear removes all prefixes from the table. The table itself remains
+// valid and keeps its identity, callers holding a pointer to it,
+// e.g. as a field embedded in another struct, see the empty table.
+//
+// Tables derived by Clone or the persistent methods are not affected.
+func (t *Table[V]) Clear() {
+	if t == nil {
+		return
+	}
+
+	var zero Table[V]
+	t.root4 = zero.root4
+	t.root6 = zero.root6
+
+	t.size4 = 0
+	t.size6 = 0
+}
+
+// ReplaceRoot4 replaces the IPv4 routes of the receiver with a copy of
+// the IPv4 routes of from in a single step, the IPv6 routes are unchanged,
+// e.g. to republish IPv4 and IPv6 feeds with different update cadences
+// independently, without rebuilding the whole table.
+// A nil from removes all IPv4 routes.
+//
+// The nodes of from are deep copied, the values are cloned if V implements
+// the Cloner interface, both tables remain independent. To publish a new
+// version for lock-free readers without a copy, see [Table.ReplaceRoot4Persist].
+func (t *Table[V]) ReplaceRoot4(from *Table[V]) {
+	if from == nil {
+		from = new(Table[V])
+	}
+	t.root4 = *from.root4.CloneRec(value.CloneFnFactory[V]())
+	t.size4 = from.size4
+}
+
+// ReplaceRoot6 is like [Table.ReplaceRoot4] but for the IPv6 routes.
+func (t *Table[V]) ReplaceRoot6(from *Table[V]) {
+	if from == nil {
+		from = new(Table[V])
+	}
+	t.root6 = *from.root6.CloneRec(value.CloneFnFactory[V]())
+	t.size6 = from.size6
+}
+
+// ReplaceRoot4Persist is similar to ReplaceRoot4 but the receiver isn't
+// modified, a new table with the IPv4 routes of from and the IPv6 routes
+// of t is returned, e.g. to swap the next version into an atomic.Pointer.
+// A nil from removes all IPv4 routes.
+//
+// Only the root nodes are copied, like for the other persistent methods
+// all other nodes are shared with t and from. Neither table may be
+// modified in-place afterwards, use the persistent methods.
+func (t *Table[V]) ReplaceRoot4Persist(from *Table[V]) *Table[V] {
+	if t == nil {
+		t = new(Table[V])
+	}
+	if from == nil {
+		from = new(Table[V])
+	}
+
+	cloneFn := value.CloneFnFactory[V]()
+
+	pt := &Table[V]{
+		size4: from.size4,
+		size6: t.size6,
+	}
+	pt.root4 = *from.root4.CloneFlat(cloneFn)
+	pt.root6 = *t.root6.CloneFlat(cloneFn)
+
+	return pt
+}
+
+// ReplaceRoot6Persist is like [Table.ReplaceRoot4Persist] but for the IPv6 routes.
+func (t *Table[V]) ReplaceRoot6Persist(from *Table[V]) *Table[V] {
+	if t == nil {
+		t = new(Table[V])
+	}
+	if from == nil {
+		from = new(Table[V])
+	}
+
+	cloneFn := value.CloneFnFactory[V]()
+
+	pt := &Table[V]{
+		size4: t.size4,
+		size6: from.size6,
+	}
+	pt.root4 = *t.root4.CloneFlat(cloneFn)
+	pt.root6 = *from.root6.CloneFlat(cloneFn)
+
+	return pt
+}
+
+// moveFrom takes over the tries of o without a copy,
+// o is owned by the caller and must not be used afterwards.
+func (t *Table[V]) moveFrom(o *Table[V]) {
+	t.root4 = o.root4
+	t.root6 = o.root6
+	t.size4 = o.size4
+	t.size6 = o.size6
+}
+
+// ResetValues replaces the value of every prefix with fn(pfx), in place.
+//
+// Only the values are rewritten, the structure of the trie is unchanged.
+// This is far cheaper than rebuilding the table, e.g. to re-derive the
+// payloads between measurement intervals.
+//
+// Like [Table.Insert] this modifies nodes in place, which are
+// shared with tables derived by the persistent methods.
+func (t *Table[V]) ResetValues(fn func(pfx netip.Prefix) V) {
+	cb := func(pfx netip.Prefix, _ V) V { return fn(pfx) }
+	t.root4.ResetValuesRec(stridePath{}, 0, true, cb)
+	t.root6.ResetValuesRec(stridePath{}, 0, false, cb)
+}
+
+// Fill sets the value of every prefix to val, in place,
+// e.g. for zeroing counters. See also [Table.ResetValues].
+func (t *Table[V]) Fill(val V) {
+	cb := func(netip.Prefix, V) V { return val }
+	t.root4.ResetValuesRec(stridePath{}, 0, true, cb)
+	t.root6.ResetValuesRec(stridePath{}, 0, false, cb)
+}
+
 // Size returns the prefix count.
 func (t *Table[V]) Size() int {
 	return t.size4 + t.size6
@@ -511,6 +1115,176 @@ func (t *Table[V]) Size6() int {
 	return t.size6
 }
 
+// Stats returns statistics about the trie structure, per address family,
+// e.g. to evaluate the memory consumption for a given set of prefixes
+// or to compare the trie shape with other implementations.
+// The statistics are computed by walking the trie once.
+func (t *Table[V]) Stats() Stats {
+	if t == nil {
+		return Stats{}
+	}
+
+	return Stats{
+		IPv4: t.familyStats(true),
+		IPv6: t.familyStats(false),
+	}
+}
+
+// familyStats walks the trie of the given address family.
+func (t *Table[V]) familyStats(is4 bool) FamilyStats {
+	n := t.rootNodeByVersion(is4)
+
+	s := FamilyStats{Size: t.size6}
+	if is4 {
+		s.Size = t.size4
+	}
+
+	if !n.IsEmpty() {
+		n.OccupancyRec(stridePath{}, 0, is4, s.addNode)
+	}
+	s.finish()
+
+	return s
+}
+
+// Dump returns a machine-readable tree of the inner trie nodes with
+// their depth, octet path, set prefix indexes and child octets, e.g.
+// as JSON for external tools. The values are not included.
+//
+// For a human-readable tree of the prefixes see [Table.Fprint].
+func (t *Table[V]) Dump() TrieDump {
+	var d TrieDump
+	if t == nil {
+		return d
+	}
+
+	if t.size4 > 0 {
+		n := newTrieNode(t.root4.DumpTrieRec(stridePath{}, 0, true), true)
+		d.IPv4 = &n
+	}
+	if t.size6 > 0 {
+		n := newTrieNode(t.root6.DumpTrieRec(stridePath{}, 0, false), false)
+		d.IPv6 = &n
+	}
+
+	return d
+}
+
+// Validate checks the internal invariants of the table and returns
+// a descriptive error on the first violation, e.g. as self-check after
+// bulk operations in long-running processes. A valid table returns nil.
+//
+// Checked are the consistency of the bitsets, item slices and counters
+// of all nodes, the absence of empty inner nodes, canonical (masked)
+// prefixes in the path-compressed leaves at their proper position and
+// the prefix counts per address family.
+func (t *Table[V]) Validate() error {
+	if t == nil {
+		return nil
+	}
+
+	for _, is4 := range []bool{true, false} {
+		family, size := "IPv6", t.size6
+		if is4 {
+			family, size = "IPv4", t.size4
+		}
+
+		count, err := t.rootNodeByVersion(is4).ValidateRec(stridePath{}, 0, is4)
+		if err != nil {
+			return fmt.Errorf("bart: invalid %s trie: %w", family, err)
+		}
+		if count != size {
+			return fmt.Errorf("bart: invalid %s trie: size %d, but %d prefixes stored", family, size, count)
+		}
+	}
+
+	return nil
+}
+
+// MemoryFootprint estimates the heap bytes used by the table, e.g. for
+// capacity planning. The trie is walked once and the node structs, the
+// capacities of the node slices, the leaves and fringes are summed up.
+//
+// The values are counted with their direct size, memory referenced by
+// the values, e.g. the contents of strings, is not included, nor the
+// rounding of the allocator to size classes.
+func (t *Table[V]) MemoryFootprint() int64 {
+	if t == nil {
+		return 0
+	}
+
+	return int64(t.root4.MemoryRec() + t.root6.MemoryRec())
+}
+
+// Compact shrinks the table after heavy deletion and returns the
+// reclaimed heap bytes, as estimated by [Table.MemoryFootprint].
+//
+// The trie is walked once bottom-up, remaining single-entry nodes are
+// collapsed into path-compressed leaves and fringes, empty nodes are
+// removed and the node slices are right-sized to their length.
+// The prefixes and values are unchanged.
+//
+// Nodes shared with persistent versions of the table, see InsertPersist,
+// are modified in-place; Compact a [Table.Clone] in this case.
+func (t *Table[V]) Compact() (reclaimed int64) {
+	if t == nil {
+		return 0
+	}
+
+	before := t.MemoryFootprint()
+
+	t.root4.CompactRec(stridePath{}, 0, true)
+	t.root6.CompactRec(stridePath{}, 0, false)
+
+	return before - t.MemoryFootprint()
+}
+
+// String implements [fmt.Stringer] with a short summary of the table:
+// the prefix and node counts and the trie depth per address family.
+// The node counts are computed by walking the trie, see [Table.Stats].
+//
+//	IPv4: 1086 prefixes, 204 nodes, 733 leaves, 39 fringes, depth 3; IPv6: 0 prefixes, 0 nodes, 0 leaves, 0 fringes, depth 0
+func (t *Table[V]) String() string {
+	if t == nil {
+		return "<nil>"
+	}
+
+	s := t.Stats()
+	return fmt.Sprintf("IPv4: %s, depth %d; IPv6: %s, depth %d",
+		s.IPv4, s.IPv4.MaxDepth, s.IPv6, s.IPv6.MaxDepth)
+}
+
+// DumpString returns the full tree of the table as printed by
+// [Table.Fprint], e.g. for debug logs.
+func (t *Table[V]) DumpString() string {
+	w := new(strings.Builder)
+	_ = t.Fprint(w)
+
+	return w.String()
+}
+
+// Occupancy returns an iterator over the occupied slots of all trie nodes,
+// IPv4 before IPv6, each family in depth-first pre-order.
+//
+// This helps to understand the density of the trie for a given set of
+// prefixes, e.g. as input for heat-map visualizations.
+func (t *Table[V]) Occupancy() iter.Seq[NodeOccupancy] {
+	return func(yield func(NodeOccupancy) bool) {
+		if t == nil {
+			return
+		}
+
+		cb := func(o nodes.Occupancy) bool { return yield(newNodeOccupancy(o)) }
+
+		if !t.root4.IsEmpty() && !t.root4.OccupancyRec(stridePath{}, 0, true, cb) {
+			return
+		}
+		if !t.root6.IsEmpty() {
+			t.root6.OccupancyRec(stridePath{}, 0, false, cb)
+		}
+	}
+}
+
 // All returns an iterator over all prefix–value pairs in the table.
 //
 // The entries from both IPv4 and IPv6 subtries are yielded using an internal recursive traversal.
@@ -546,6 +1320,28 @@ func (t *Table[V]) All() iter.Seq2[netip.Prefix, V] {
 	}
 }
 
+// AppendEntries appends all prefix–value pairs to dst and returns the
+// extended slice, in the unspecified order of [Table.All].
+//
+// No intermediate allocations are made, with a pooled dst of sufficient
+// capacity the table is materialized without any allocation, e.g. for hot
+// paths handing the table over to other subsystems.
+func (t *Table[V]) AppendEntries(dst []PrefixValue[V]) []PrefixValue[V] {
+	if t == nil {
+		return dst
+	}
+
+	yield := func(pfx netip.Prefix, val V) bool {
+		dst = append(dst, PrefixValue[V]{Prefix: pfx, Value: val})
+		return true
+	}
+
+	t.root4.AllRec(stridePath{}, 0, true, yield)
+	t.root6.AllRec(stridePath{}, 0, false, yield)
+
+	return dst
+}
+
 // All4 is like [Table.All] but only for the v4 routing table.
 func (t *Table[V]) All4() iter.Seq2[netip.Prefix, V] {
 	return func(yield func(netip.Prefix, V) bool) {
@@ -636,6 +1432,13 @@ func (t *Table[V]) AllSorted6() iter.Seq2[netip.Prefix, V] {
 //	   │  └─ 2001:db8::/32 (V)
 //	   └─ fe80::/10 (V)
 func (t *Table[V]) Fprint(w io.Writer) error {
+	return t.FprintFunc(w, nil)
+}
+
+// FprintFunc is like [Table.Fprint], but the payload is formatted
+// by format, e.g. to print only some fields of a struct value.
+// A nil format prints the default formatted payload.
+func (t *Table[V]) FprintFunc(w io.Writer, format func(pfx netip.Prefix, val V) string) error {
 	if w == nil {
 		return fmt.Errorf("nil writer")
 	}
@@ -644,12 +1447,12 @@ func (t *Table[V]) Fprint(w io.Writer) error {
 	}
 
 	// v4
-	if err := t.fprint(w, true); err != nil {
+	if err := t.fprint(w, true, format); err != nil {
 		return err
 	}
 
 	// v6
-	if err := t.fprint(w, false); err != nil {
+	if err := t.fprint(w, false, format); err != nil {
 		return err
 	}
 
@@ -657,7 +1460,7 @@ func (t *Table[V]) Fprint(w io.Writer) error {
 }
 
 // fprint is the version dependent adapter to fprintRec.
-func (t *Table[V]) fprint(w io.Writer, is4 bool) error {
+func (t *Table[V]) fprint(w io.Writer, is4 bool, format func(netip.Prefix, V) string) error {
 	n := t.rootNodeByVersion(is4)
 	if n.IsEmpty() {
 		return nil
@@ -674,7 +1477,7 @@ func (t *Table[V]) fprint(w io.Writer, is4 bool) error {
 		Is4:  is4,
 	}
 
-	return n.FprintRec(w, startParent, "")
+	return n.FprintFuncRec(w, startParent, "", format)
 }
 
 // MarshalText implements the [encoding.TextMarshaler] interface,
@@ -711,6 +1514,79 @@ func (t *Table[V]) MarshalJSON() ([]byte, error) {
 	return buf, nil
 }
 
+// MarshalJSONFlat encodes the table as flat list of prefix-value pairs
+// in CIDR sort order, e.g. for API responses:
+//
+//	[{"prefix":"10.0.0.0/8","value":...}, ...]
+//
+// The values are encoded by encoding/json. An empty table is encoded
+// as empty list, a nil table as null. The result is accepted by
+// [Table.UnmarshalJSON].
+func (t *Table[V]) MarshalJSONFlat() ([]byte, error) {
+	if t == nil {
+		return []byte("null"), nil
+	}
+
+	entries := make([]PrefixValue[V], 0, t.Size())
+	for pfx, val := range t.AllSorted() {
+		entries = append(entries, PrefixValue[V]{Prefix: pfx, Value: val})
+	}
+
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON replaces the content of the table with the decoded
+// prefixes and values, the values are decoded by encoding/json.
+//
+// Two formats are accepted, the hierarchical format of [Table.MarshalJSON]
+// and the flat list format
+//
+//	[{"prefix":"10.0.0.0/8","value":...}, ...]
+//
+// as produced by encoding the result of [Table.AppendEntries].
+// On error the table is left unchanged.
+func (t *Table[V]) UnmarshalJSON(data []byte) error {
+	if t == nil {
+		return errors.New("bart: UnmarshalJSON on nil table")
+	}
+
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var entries []PrefixValue[V]
+
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+	} else {
+		var dump struct {
+			Ipv4 []DumpListNode[V] `json:"ipv4"`
+			Ipv6 []DumpListNode[V] `json:"ipv6"`
+		}
+		if err := json.Unmarshal(data, &dump); err != nil {
+			return err
+		}
+		entries = appendDumpList(entries, dump.Ipv4)
+		entries = appendDumpList(entries, dump.Ipv6)
+	}
+
+	for _, e := range entries {
+		if !e.Prefix.IsValid() {
+			return errors.New("bart: UnmarshalJSON, missing or invalid prefix")
+		}
+	}
+
+	t.Clear()
+	for _, e := range entries {
+		t.insert(e.Prefix, e.Value)
+	}
+
+	return nil
+}
+
 // DumpList4 dumps the ipv4 tree into a list of roots and their subnets.
 // It can be used to analyze the tree or build the text or JSON serialization.
 func (t *Table[V]) DumpList4() []DumpListNode[V] {
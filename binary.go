@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+)
+
+// The binary snapshot format, all entries in natural CIDR sort order:
+//
+//	magic    [4]byte  "BART"
+//	version  byte     1
+//	count    uvarint  number of entries
+//	entries  count times:
+//	  addrLen  byte     4 or 16
+//	  addr     [addrLen]byte
+//	  bits     byte
+//	  valLen   uvarint
+//	  val      [valLen]byte, opaque encoded value
+const (
+	binaryMagic   = "BART"
+	binaryVersion = 1
+
+	// sanity limit for a single encoded value, protects against
+	// allocations driven by corrupt or hostile input
+	binaryMaxValueLen = 1 << 24
+)
+
+// WriteBinary writes a binary snapshot of t to w, every value is
+// encoded with encode. A nil table is written as empty snapshot.
+//
+// The snapshot can be loaded with [LoadBinaryAs], also into a table
+// with a different value type.
+//
+// The snapshot is deterministic: semantically equal tables, regardless
+// of their history of inserts and deletes, produce byte-for-byte identical
+// snapshots, provided encode is canonical, i.e. equal values are encoded
+// to equal bytes. Snapshots can therefore be content-addressed and
+// deduplicated, e.g. by a hash over the written bytes. This does not hold
+// for the structural encoding of [Table.MarshalBinary].
+func WriteBinary[V any](w io.Writer, t *Table[V], encode func(V) ([]byte, error)) error {
+	bw := bufio.NewWriter(w)
+
+	var buf [binary.MaxVarintLen64]byte
+
+	bw.WriteString(binaryMagic)
+	bw.WriteByte(binaryVersion)
+
+	var size int
+	if t != nil {
+		size = t.Size()
+	}
+	bw.Write(binary.AppendUvarint(buf[:0], uint64(size)))
+
+	if t != nil {
+		for pfx, val := range t.AllSorted() {
+			raw, err := encode(val)
+			if err != nil {
+				return fmt.Errorf("bart: encode value of %s: %w", pfx, err)
+			}
+
+			addr := pfx.Addr().AsSlice()
+			bw.WriteByte(byte(len(addr)))
+			bw.Write(addr)
+			bw.WriteByte(byte(pfx.Bits()))
+			bw.Write(binary.AppendUvarint(buf[:0], uint64(len(raw))))
+			bw.Write(raw)
+		}
+	}
+
+	// bufio.Writer errors are sticky, checked once on flush
+	return bw.Flush()
+}
+
+// LoadBinaryAs reads a binary snapshot written by [WriteBinary] and
+// returns a new table with value type W. Every raw value is converted
+// with convert, without an intermediate decode into the value type
+// of the writer. This allows warm migrations between value types.
+//
+// The raw bytes are only valid during the call of convert,
+// they must be copied if retained.
+func LoadBinaryAs[W any](r io.Reader, convert func(raw []byte) (W, error)) (*Table[W], error) {
+	br := bufio.NewReader(r)
+
+	var hdr [len(binaryMagic) + 1]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, fmt.Errorf("bart: read header: %w", err)
+	}
+	if string(hdr[:len(binaryMagic)]) != binaryMagic {
+		return nil, errors.New("bart: not a binary snapshot")
+	}
+	if hdr[len(binaryMagic)] != binaryVersion {
+		return nil, fmt.Errorf("bart: unsupported snapshot version %d", hdr[len(binaryMagic)])
+	}
+
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("bart: read count: %w", err)
+	}
+
+	tbl := new(Table[W])
+
+	var raw []byte
+	for i := range count {
+		pfx, valLen, err := readBinaryEntryHead(br)
+		if err != nil {
+			return nil, fmt.Errorf("bart: entry %d: %w", i, err)
+		}
+
+		if uint64(cap(raw)) < valLen {
+			raw = make([]byte, valLen)
+		}
+		raw = raw[:valLen]
+		if _, err := io.ReadFull(br, raw); err != nil {
+			return nil, fmt.Errorf("bart: entry %d: read value: %w", i, err)
+		}
+
+		val, err := convert(raw)
+		if err != nil {
+			return nil, fmt.Errorf("bart: convert value of %s: %w", pfx, err)
+		}
+
+		tbl.Insert(pfx, val)
+	}
+
+	return tbl, nil
+}
+
+// byteReader is the input of readBinaryEntryHead.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// readBinaryEntryHead reads the prefix and the value length of an entry.
+func readBinaryEntryHead(br byteReader) (pfx netip.Prefix, valLen uint64, err error) {
+	addrLen, err := br.ReadByte()
+	if err != nil {
+		return pfx, 0, err
+	}
+	if addrLen != 4 && addrLen != 16 {
+		return pfx, 0, fmt.Errorf("invalid address length %d", addrLen)
+	}
+
+	var addr [16]byte
+	if _, err = io.ReadFull(br, addr[:addrLen]); err != nil {
+		return pfx, 0, err
+	}
+
+	bits, err := br.ReadByte()
+	if err != nil {
+		return pfx, 0, err
+	}
+
+	ip, _ := netip.AddrFromSlice(addr[:addrLen])
+	pfx = netip.PrefixFrom(ip, int(bits))
+	if !pfx.IsValid() || pfx != pfx.Masked() {
+		return pfx, 0, fmt.Errorf("invalid prefix %s/%d", ip, bits)
+	}
+
+	if valLen, err = binary.ReadUvarint(br); err != nil {
+		return pfx, 0, err
+	}
+	if valLen > binaryMaxValueLen {
+		return pfx, 0, fmt.Errorf("value length %d exceeds limit", valLen)
+	}
+
+	return pfx, valLen, nil
+}
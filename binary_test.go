@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestBinaryLoadAs(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	// old value type: decimal strings
+	old := new(Table[string])
+	want := new(Table[int])
+	for i, pfx := range random.RealWorldPrefixes(prng, 2_000) {
+		old.Insert(pfx, strconv.Itoa(i))
+		want.Insert(pfx, i)
+	}
+
+	var buf bytes.Buffer
+	err := WriteBinary(&buf, old, func(s string) ([]byte, error) { return []byte(s), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// new value type: int
+	got, err := LoadBinaryAs(bytes.NewReader(buf.Bytes()), func(raw []byte) (int, error) {
+		return strconv.Atoi(string(raw))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(want) {
+		t.Fatal("LoadBinaryAs, not equal to the converted table")
+	}
+}
+
+func TestBinaryEmpty(t *testing.T) {
+	t.Parallel()
+
+	raw := func(b []byte) ([]byte, error) { return b, nil }
+
+	for _, tbl := range []*Table[[]byte]{nil, new(Table[[]byte])} {
+		var buf bytes.Buffer
+		if err := WriteBinary(&buf, tbl, raw); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := LoadBinaryAs(&buf, func(b []byte) ([]byte, error) { return bytes.Clone(b), nil })
+		if err != nil || got.Size() != 0 {
+			t.Fatalf("LoadBinaryAs of empty snapshot, got: %v, %v", got, err)
+		}
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("2001:db8::/32"), 2)
+
+	errEncode := errors.New("encode")
+	if err := WriteBinary(new(bytes.Buffer), tbl, func(int) ([]byte, error) { return nil, errEncode }); !errors.Is(err, errEncode) {
+		t.Fatalf("WriteBinary, got err: %v, want: %v", err, errEncode)
+	}
+
+	var buf bytes.Buffer
+	_ = WriteBinary(&buf, tbl, func(v int) ([]byte, error) { return []byte{byte(v)}, nil })
+	snapshot := buf.Bytes()
+
+	conv := func(raw []byte) (int, error) { return int(raw[0]), nil }
+
+	if got, err := LoadBinaryAs(bytes.NewReader(snapshot), conv); err != nil || !got.Equal(tbl) {
+		t.Fatalf("LoadBinaryAs, got: %v, err: %v", got, err)
+	}
+
+	errConvert := errors.New("convert")
+	if _, err := LoadBinaryAs(bytes.NewReader(snapshot), func([]byte) (int, error) { return 0, errConvert }); !errors.Is(err, errConvert) {
+		t.Fatalf("LoadBinaryAs, got err: %v, want: %v", err, errConvert)
+	}
+
+	corrupt := func(i int, b byte) []byte {
+		c := bytes.Clone(snapshot)
+		c[i] = b
+		return c
+	}
+
+	tests := map[string][]byte{
+		"empty":         nil,
+		"magic":         corrupt(0, 'X'),
+		"version":       corrupt(4, 99),
+		"address len":   corrupt(6, 5),
+		"prefix bits":   corrupt(11, 33),
+		"not masked":    corrupt(10, 1),
+		"truncated":     snapshot[:len(snapshot)-1],
+		"missing entry": snapshot[:12],
+	}
+
+	for name, data := range tests {
+		if _, err := LoadBinaryAs(bytes.NewReader(data), conv); err == nil {
+			t.Errorf("LoadBinaryAs(%s), expected error", name)
+		}
+	}
+}
+
+func TestBinaryDeterministic(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, 2_000)
+
+	encode := func(v int) ([]byte, error) { return []byte(strconv.Itoa(v)), nil }
+
+	// same content, different history
+	a := new(Table[int])
+	for i, pfx := range pfxs {
+		a.Insert(pfx, i)
+	}
+
+	b := new(Table[int])
+	for i := len(pfxs) - 1; i >= 0; i-- {
+		b.Insert(pfxs[i], -1)
+	}
+	for i := len(pfxs) - 1; i >= 0; i-- {
+		b.Insert(pfxs[i], i)
+	}
+	for _, pfx := range random.RealWorldPrefixes(prng, 500) {
+		if _, ok := a.Get(pfx); !ok {
+			b.Insert(pfx, 0)
+			b.Delete(pfx)
+		}
+	}
+
+	var bufA, bufB bytes.Buffer
+	if err := WriteBinary(&bufA, a, encode); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteBinary(&bufB, b, encode); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(bufA.Bytes(), bufB.Bytes()) {
+		t.Fatal("WriteBinary, snapshots of equal tables differ")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/admpub/bart/internal/nodes"
+)
+
+// The structural binary format, the trie is serialized as is:
+//
+//	magic    [4]byte  "BART"
+//	version  byte     2
+//	root4    node
+//	root6    node
+//
+// See nodes.AppendBinaryRec for the encoding of the nodes.
+const binaryVersionTrie = 2
+
+// MarshalBinary implements the [encoding.BinaryMarshaler] interface,
+// see [Table.MarshalBinaryWith] for the format.
+//
+// The values are encoded with their MarshalBinary method, if V implements
+// [encoding.BinaryMarshaler], strings and byte slices are written as is,
+// int, uint and uintptr values as varints, all other values must be
+// fixed-size values for [binary.Append].
+func (t *Table[V]) MarshalBinary() ([]byte, error) {
+	return t.MarshalBinaryWith(encodeBinaryValue[V])
+}
+
+// UnmarshalBinary implements the [encoding.BinaryUnmarshaler] interface,
+// the content of the table is replaced. The values are decoded
+// as the counterpart of [Table.MarshalBinary].
+func (t *Table[V]) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalBinaryWith(data, decodeBinaryValue[V])
+}
+
+// MarshalBinaryWith returns a space-efficient structural encoding of the
+// table, every value is encoded with encode.
+//
+// The bitsets and packed slices of the trie nodes are serialized directly,
+// not prefix by prefix. Decoding with [Table.UnmarshalBinaryWith] restores
+// the trie without any insert, this is much faster than rebuilding large
+// tables.
+func (t *Table[V]) MarshalBinaryWith(encode func(V) ([]byte, error)) ([]byte, error) {
+	if t == nil {
+		t = new(Table[V])
+	}
+
+	buf := append([]byte(binaryMagic), binaryVersionTrie)
+
+	var err error
+	if buf, err = t.root4.AppendBinaryRec(buf, encode); err != nil {
+		return nil, fmt.Errorf("bart: encode value: %w", err)
+	}
+	if buf, err = t.root6.AppendBinaryRec(buf, encode); err != nil {
+		return nil, fmt.Errorf("bart: encode value: %w", err)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinaryWith replaces the content of the table with the structural
+// encoding of [Table.MarshalBinaryWith], every value is decoded with decode.
+// On error the table is left unchanged.
+//
+// The raw bytes are only valid during the call of decode,
+// they must be copied if retained.
+func (t *Table[V]) UnmarshalBinaryWith(data []byte, decode func(raw []byte) (V, error)) error {
+	if t == nil {
+		return errors.New("bart: UnmarshalBinary on nil table")
+	}
+
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return errors.New("bart: not a binary snapshot")
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersionTrie {
+		return fmt.Errorf("bart: unsupported snapshot version %d", v)
+	}
+
+	dec := nodes.BinaryDecoder[V]{Data: data[len(binaryMagic)+1:], Decode: decode}
+
+	var root4, root6 nodes.BartNode[V]
+	if err := dec.DecodeRec(&root4, nodes.StridePath{}, 0, true); err != nil {
+		return fmt.Errorf("bart: decode ipv4 trie: %w", err)
+	}
+	size4 := dec.Count
+
+	if err := dec.DecodeRec(&root6, nodes.StridePath{}, 0, false); err != nil {
+		return fmt.Errorf("bart: decode ipv6 trie: %w", err)
+	}
+	size6 := dec.Count - size4
+
+	if len(dec.Data) != 0 {
+		return fmt.Errorf("bart: %w: trailing data", nodes.ErrCorrupt)
+	}
+
+	t.root4, t.root6 = root4, root6
+	t.size4, t.size6 = size4, size6
+
+	return nil
+}
+
+// encodeBinaryValue is the default value encoder of [Table.MarshalBinary].
+func encodeBinaryValue[V any](val V) ([]byte, error) {
+	switch v := any(val).(type) {
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case int:
+		return binary.AppendVarint(nil, int64(v)), nil
+	case uint:
+		return binary.AppendUvarint(nil, uint64(v)), nil
+	case uintptr:
+		return binary.AppendUvarint(nil, uint64(v)), nil
+	default:
+		return binary.Append(nil, binary.LittleEndian, val)
+	}
+}
+
+// decodeBinaryValue is the default value decoder of [Table.UnmarshalBinary].
+func decodeBinaryValue[V any](raw []byte) (val V, err error) {
+	switch p := any(&val).(type) {
+	case encoding.BinaryUnmarshaler:
+		err = p.UnmarshalBinary(raw)
+	case *string:
+		*p = string(raw)
+	case *[]byte:
+		*p = bytes.Clone(raw)
+	case *int:
+		x, n := binary.Varint(raw)
+		if *p = int(x); n <= 0 || n != len(raw) || int64(*p) != x {
+			err = errors.New("invalid varint value")
+		}
+	case *uint:
+		x, n := binary.Uvarint(raw)
+		if *p = uint(x); n <= 0 || n != len(raw) || uint64(*p) != x {
+			err = errors.New("invalid varint value")
+		}
+	case *uintptr:
+		x, n := binary.Uvarint(raw)
+		if *p = uintptr(x); n <= 0 || n != len(raw) || uint64(*p) != x {
+			err = errors.New("invalid varint value")
+		}
+	default:
+		var n int
+		if n, err = binary.Decode(raw, binary.LittleEndian, &val); err == nil && n != len(raw) {
+			err = errors.New("invalid value length")
+		}
+	}
+	return val, err
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"net/netip"
+	"strconv"
+	"testing"
+
+	"github.com/admpub/bart/internal/nodes"
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestBinaryTrieRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(Table[int64])
+	for i, pfx := range random.RealWorldPrefixes(prng, n) {
+		tbl.Insert(pfx, int64(i))
+	}
+
+	data, err := tbl.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := new(Table[int64])
+	got.Insert(mpp("0.0.0.0/0"), -1) // replaced
+
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(tbl) || got.Size4() != tbl.Size4() || got.Size6() != tbl.Size6() {
+		t.Fatal("UnmarshalBinary, not equal to the marshaled table")
+	}
+	if got.root4.StatsRec() != tbl.root4.StatsRec() || got.root6.StatsRec() != tbl.root6.StatsRec() {
+		t.Fatal("UnmarshalBinary, trie structure differs")
+	}
+
+	// the decoded table is fully functional
+	for range n {
+		ip := random.IP(prng)
+		want, wantOK := tbl.Lookup(ip)
+		val, ok := got.Lookup(ip)
+		if val != want || ok != wantOK {
+			t.Fatalf("Lookup(%s), got: (%d, %v), want: (%d, %v)", ip, val, ok, want, wantOK)
+		}
+	}
+
+	got.Insert(mpp("10.0.0.0/8"), 42)
+	got.Delete(mpp("10.0.0.0/8"))
+}
+
+func TestBinaryTrieValues(t *testing.T) {
+	t.Parallel()
+
+	strs := new(Table[string])
+	strs.Insert(mpp("10.0.0.0/8"), "a")
+	strs.Insert(mpp("10.1.2.0/24"), "")
+	strs.Insert(mpp("2001:db8::/32"), "c")
+
+	data, err := strs.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotStrs := new(Table[string])
+	if err := gotStrs.UnmarshalBinary(data); err != nil || !gotStrs.Equal(strs) {
+		t.Fatalf("string values, err: %v", err)
+	}
+
+	// BinaryMarshaler values
+	addrs := new(Table[netip.Addr])
+	addrs.Insert(mpp("10.0.0.0/8"), mpa("192.0.2.1"))
+	addrs.Insert(mpp("::/0"), mpa("2001:db8::1"))
+
+	data, err = addrs.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotAddrs := new(Table[netip.Addr])
+	if err := gotAddrs.UnmarshalBinary(data); err != nil || !gotAddrs.Equal(addrs) {
+		t.Fatalf("BinaryMarshaler values, err: %v", err)
+	}
+
+	// int values, not fixed-size for binary.Append
+	ints := new(Table[int])
+	ints.Insert(mpp("10.0.0.0/8"), -1)
+	ints.Insert(mpp("10.1.2.0/24"), 1<<40)
+	ints.Insert(mpp("2001:db8::/32"), 0)
+
+	data, err = ints.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotInts := new(Table[int])
+	if err := gotInts.UnmarshalBinary(data); err != nil || !gotInts.Equal(ints) {
+		t.Fatalf("int values, err: %v", err)
+	}
+
+	uints := new(Table[uint])
+	uints.Insert(mpp("10.0.0.0/8"), 1<<40)
+
+	data, err = uints.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotUints := new(Table[uint])
+	if err := gotUints.UnmarshalBinary(data); err != nil || !gotUints.Equal(uints) {
+		t.Fatalf("uint values, err: %v", err)
+	}
+
+	// custom codec
+	data, err = strs.MarshalBinaryWith(func(s string) ([]byte, error) { return []byte(strconv.Quote(s)), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotStrs = new(Table[string])
+	err = gotStrs.UnmarshalBinaryWith(data, func(raw []byte) (string, error) { return strconv.Unquote(string(raw)) })
+	if err != nil || !gotStrs.Equal(strs) {
+		t.Fatalf("custom codec, err: %v", err)
+	}
+
+	// unsupported value type
+	anys := new(Table[any])
+	anys.Insert(mpp("10.0.0.0/8"), []int{1})
+	if _, err := anys.MarshalBinary(); err == nil {
+		t.Error("MarshalBinary of any values, expected error")
+	}
+}
+
+func TestBinaryTrieErrors(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[uint16])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.2.0/24"), 2)
+	tbl.Insert(mpp("2001:db8::/32"), 3)
+
+	data, err := tbl.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := tbl.Clone()
+
+	// every truncation is detected
+	for i := range len(data) {
+		if err := got.UnmarshalBinary(data[:i]); err == nil {
+			t.Fatalf("UnmarshalBinary of %d/%d bytes, expected error", i, len(data))
+		}
+	}
+
+	if err := got.UnmarshalBinary(append(data, 0)); !errors.Is(err, nodes.ErrCorrupt) {
+		t.Errorf("trailing data, got: %v", err)
+	}
+
+	// the prefix-by-prefix snapshot format is rejected
+	bad := append([]byte(nil), data...)
+	bad[len(binaryMagic)] = binaryVersion
+	if err := got.UnmarshalBinary(bad); err == nil {
+		t.Error("wrong version, expected error")
+	}
+
+	if !got.Equal(tbl) {
+		t.Fatal("failed UnmarshalBinary modified the table")
+	}
+
+	var nilTbl *Table[uint16]
+	if err := nilTbl.UnmarshalBinary(data); err == nil {
+		t.Error("UnmarshalBinary on nil table, expected error")
+	}
+	if data, err := nilTbl.MarshalBinary(); err != nil || new(Table[uint16]).UnmarshalBinary(data) != nil {
+		t.Errorf("MarshalBinary of nil table, err: %v", err)
+	}
+}
+
+func TestBinaryTrieCorrupt(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[uint16])
+	tbl.Insert(mpp("10.1.2.0/24"), 1)
+	tbl.Insert(mpp("10.2.0.0/24"), 2)
+	tbl.Insert(mpp("2001:db8::/32"), 3)
+
+	data, err := tbl.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the leaf 10.1.2.0/24 at depth 1 is encoded as addr and bits
+	at := bytes.Index(data, []byte{10, 1, 2, 0, 24})
+	if at < 0 {
+		t.Fatal("leaf not found in encoding")
+	}
+
+	tests := []struct {
+		name string
+		leaf []byte
+	}{
+		{"default route", []byte{0, 0, 0, 0, 0}},
+		{"too short for depth", []byte{10, 1, 0, 0, 16}},
+		{"off the node path", []byte{11, 1, 2, 0, 24}},
+		{"wrong child slot", []byte{10, 3, 2, 0, 24}},
+		{"not masked", []byte{10, 1, 2, 1, 24}},
+		{"invalid bits", []byte{10, 1, 2, 0, 33}},
+	}
+
+	for _, tt := range tests {
+		bad := bytes.Clone(data)
+		copy(bad[at:], tt.leaf)
+
+		got := tbl.Clone()
+		if err := got.UnmarshalBinary(bad); !errors.Is(err, nodes.ErrCorrupt) {
+			t.Errorf("%s, got: %v, want: %v", tt.name, err, nodes.ErrCorrupt)
+		}
+		if !got.Equal(tbl) {
+			t.Errorf("%s, failed UnmarshalBinary modified the table", tt.name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+// Package cbor provides a compact CBOR (RFC 8949) encoding of bart
+// routing tables, e.g. for streaming large tables over constrained links
+// where JSON is too verbose. The package has no dependencies outside
+// the standard library.
+//
+// The logical model is the flat JSON format of [bart.Table.UnmarshalJSON],
+// a list of prefix-value pairs in natural CIDR sort order, each pair
+// encoded as a two element array:
+//
+//	[ [prefix, value], [prefix, value], ... ]
+//
+// The prefixes are encoded as RFC 9164 IP prefixes, tag 52 for IPv4
+// and tag 54 for IPv6. The values are encoded by reflection, see [Marshal].
+package cbor
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"reflect"
+
+	"github.com/admpub/bart"
+)
+
+// RFC 9164 tags for IP prefixes
+const (
+	tagIPv4 = 52
+	tagIPv6 = 54
+)
+
+// ErrSyntax is returned for malformed or unsupported CBOR input.
+var ErrSyntax = errors.New("cbor: syntax error")
+
+// Marshal returns the CBOR encoding of t, a nil table is encoded
+// as an empty list.
+//
+// The values are encoded by reflection: booleans, integers, floats,
+// strings, byte slices, slices, arrays, maps, and structs with their
+// exported fields as maps keyed by field name, or by the name of the
+// `cbor` struct tag, "-" skips the field. Nil pointers, slices, maps
+// and interfaces are encoded as null. Values implementing
+// [encoding.BinaryMarshaler] are encoded as byte strings.
+func Marshal[V any](t *bart.Table[V]) ([]byte, error) {
+	var size int
+	if t != nil {
+		size = t.Size()
+	}
+
+	buf := appendHead(nil, majorArray, uint64(size))
+
+	var err error
+	for pfx, val := range t.AllSorted() {
+		buf = appendHead(buf, majorArray, 2)
+		buf = appendPrefix(buf, pfx)
+		if buf, err = appendValue(buf, reflect.ValueOf(&val).Elem()); err != nil {
+			return nil, fmt.Errorf("cbor: value of %s: %w", pfx, err)
+		}
+	}
+
+	return buf, nil
+}
+
+// Unmarshal replaces the content of t with the decoded CBOR encoding
+// of [Marshal]. On error the table is left unchanged.
+func Unmarshal[V any](data []byte, t *bart.Table[V]) error {
+	if t == nil {
+		return errors.New("cbor: Unmarshal into nil table")
+	}
+
+	d := &decoder{data: data}
+
+	n, err := d.arrayLen()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]bart.PrefixValue[V], n)
+	for i := range entries {
+		if m, err := d.arrayLen(); err != nil || m != 2 {
+			return fmt.Errorf("%w: entry %d is not a pair", ErrSyntax, i)
+		}
+
+		if entries[i].Prefix, err = d.prefix(); err != nil {
+			return fmt.Errorf("cbor: entry %d: %w", i, err)
+		}
+		if err = d.value(reflect.ValueOf(&entries[i].Value).Elem(), 0); err != nil {
+			return fmt.Errorf("cbor: value of %s: %w", entries[i].Prefix, err)
+		}
+	}
+
+	if len(d.data) != 0 {
+		return fmt.Errorf("%w: trailing data", ErrSyntax)
+	}
+
+	t.Clear()
+	for _, e := range entries {
+		t.Insert(e.Prefix, e.Value)
+	}
+	return nil
+}
+
+// appendPrefix appends pfx as RFC 9164 tagged prefix,
+// the address with trailing zero bytes removed.
+func appendPrefix(buf []byte, pfx netip.Prefix) []byte {
+	tag := uint64(tagIPv6)
+	if pfx.Addr().Is4() {
+		tag = tagIPv4
+	}
+
+	addr := pfx.Masked().Addr().AsSlice()
+	for len(addr) > 0 && addr[len(addr)-1] == 0 {
+		addr = addr[:len(addr)-1]
+	}
+
+	buf = appendHead(buf, majorTag, tag)
+	buf = appendHead(buf, majorArray, 2)
+	buf = appendHead(buf, majorUint, uint64(pfx.Bits()))
+	buf = appendHead(buf, majorBytes, uint64(len(addr)))
+	return append(buf, addr...)
+}
+
+// prefix decodes an RFC 9164 tagged prefix.
+func (d *decoder) prefix() (pfx netip.Prefix, err error) {
+	major, tag, err := d.head()
+	if err != nil {
+		return pfx, err
+	}
+	if major != majorTag || (tag != tagIPv4 && tag != tagIPv6) {
+		return pfx, fmt.Errorf("%w: expected IP prefix tag", ErrSyntax)
+	}
+
+	if n, err := d.arrayLen(); err != nil || n != 2 {
+		return pfx, fmt.Errorf("%w: invalid IP prefix", ErrSyntax)
+	}
+
+	major, bits, err := d.head()
+	if err != nil || major != majorUint {
+		return pfx, fmt.Errorf("%w: invalid prefix length", ErrSyntax)
+	}
+
+	raw, err := d.bytes(majorBytes)
+	if err != nil {
+		return pfx, err
+	}
+
+	var addr netip.Addr
+	if tag == tagIPv4 {
+		var a4 [4]byte
+		if len(raw) > len(a4) {
+			return pfx, fmt.Errorf("%w: invalid IPv4 address", ErrSyntax)
+		}
+		copy(a4[:], raw)
+		addr = netip.AddrFrom4(a4)
+	} else {
+		var a16 [16]byte
+		if len(raw) > len(a16) {
+			return pfx, fmt.Errorf("%w: invalid IPv6 address", ErrSyntax)
+		}
+		copy(a16[:], raw)
+		addr = netip.AddrFrom16(a16)
+	}
+
+	if bits > uint64(addr.BitLen()) {
+		return pfx, fmt.Errorf("%w: prefix length out of range", ErrSyntax)
+	}
+	return netip.PrefixFrom(addr, int(bits)), nil
+}
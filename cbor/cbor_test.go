@@ -0,0 +1,245 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/admpub/bart"
+)
+
+var mpp = netip.MustParsePrefix
+
+type route struct {
+	NextHop netip.Addr
+	Metric  int32
+	Weight  float64 `cbor:"w"`
+	Tags    []string
+	Attrs   map[string]uint16
+	Origin  *string
+	Ignored bool `cbor:"-"`
+}
+
+func TestMarshalPrefix(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(bart.Table[int])
+	tbl.Insert(mpp("192.0.2.0/24"), 1)
+	tbl.Insert(mpp("2001:db8:1234::/48"), -2)
+
+	data, err := Marshal(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// RFC 9164 examples for the prefixes
+	want := "82" +
+		"82" + "d834" + "82" + "1818" + "43c00002" + "01" +
+		"82" + "d836" + "82" + "1830" + "4620010db81234" + "21"
+
+	if got := hex.EncodeToString(data); got != want {
+		t.Fatalf("Marshal\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestMarshalRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	origin := "bgp"
+
+	tbl := new(bart.Table[route])
+	tbl.Insert(mpp("0.0.0.0/0"), route{NextHop: netip.MustParseAddr("192.0.2.1")})
+	tbl.Insert(mpp("10.0.0.0/8"), route{
+		NextHop: netip.MustParseAddr("10.0.0.1"),
+		Metric:  -100,
+		Weight:  0.5,
+		Tags:    []string{"a", "b"},
+		Attrs:   map[string]uint16{"med": 10, "lp": 200},
+		Origin:  &origin,
+	})
+	tbl.Insert(mpp("::/0"), route{NextHop: netip.MustParseAddr("2001:db8::1"), Weight: math.Inf(1)})
+	tbl.Insert(mpp("2001:db8::/32"), route{Metric: math.MaxInt32})
+
+	data, err := Marshal(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := new(bart.Table[route])
+	if err := Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(tbl) {
+		t.Fatal("Unmarshal(Marshal(tbl)), expected equal tables")
+	}
+
+	// deterministic, also for maps
+	again, err := Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Fatal("Marshal is not deterministic")
+	}
+}
+
+func TestMarshalNil(t *testing.T) {
+	t.Parallel()
+
+	var tbl *bart.Table[int]
+
+	data, err := Marshal(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(data); got != "80" {
+		t.Fatalf("Marshal(nil), got %s, want 80", got)
+	}
+
+	if err := Unmarshal(data, tbl); err == nil {
+		t.Fatal("Unmarshal into nil table, expected error")
+	}
+}
+
+func TestUnmarshalAny(t *testing.T) {
+	t.Parallel()
+
+	src := new(bart.Table[any])
+	src.Insert(mpp("10.0.0.0/8"), map[string]any{"a": uint64(1), "b": []any{int64(-1), "x", true, nil}})
+	src.Insert(mpp("2001:db8::/32"), []byte{1, 2, 3})
+	src.Insert(mpp("fe80::/10"), nil)
+
+	data, err := Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := new(bart.Table[any])
+	if err := Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	for pfx, want := range src.All() {
+		val, ok := got.Get(pfx)
+		if !ok {
+			t.Fatalf("Get(%s), expected ok", pfx)
+		}
+		if !reflect.DeepEqual(val, want) {
+			t.Errorf("Get(%s), got %#v, want %#v", pfx, val, want)
+		}
+	}
+}
+
+func TestUnmarshalFloat16(t *testing.T) {
+	t.Parallel()
+
+	// [[52([8, h'0a']), 1.5 as half float]]
+	data, _ := hex.DecodeString("8182d8348208410af93e00")
+
+	got := new(bart.Table[float32])
+	if err := Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if val, ok := got.Get(mpp("10.0.0.0/8")); !ok || val != 1.5 {
+		t.Fatalf("Get(10.0.0.0/8), got (%v, %v), want (1.5, true)", val, ok)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"no array", "a0"},
+		{"length exceeds data", "9a00010000"},
+		{"no pair", "818101"},
+		{"no prefix tag", "818201" + "01"},
+		{"wrong tag", "8182d835820041" + "0a" + "01"},
+		{"prefix too long", "8182d83482182141" + "0a" + "01"},
+		{"address too long", "8182d834820845" + "0102030405" + "01"},
+		{"overflow", "8182d8348208410a" + "190100"},
+		{"negative uint", "8182d83482084110" + "20"},
+		{"wrong type", "8182d83482084110" + "6161"},
+		{"truncated", "8182d83482084110" + "19"},
+		{"indefinite", "9f"},
+		{"trailing data", "80" + "00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := hex.DecodeString(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tbl := new(bart.Table[uint8])
+			tbl.Insert(mpp("192.168.0.0/16"), 1)
+
+			err = Unmarshal(data, tbl)
+			if err == nil {
+				t.Fatal("Unmarshal, expected error")
+			}
+			if !errors.Is(err, ErrSyntax) {
+				t.Errorf("Unmarshal, got %v, want ErrSyntax", err)
+			}
+
+			// unchanged on error
+			if tbl.Size() != 1 {
+				t.Errorf("Unmarshal error, table modified, size %d", tbl.Size())
+			}
+		})
+	}
+}
+
+func TestUnmarshalDepth(t *testing.T) {
+	t.Parallel()
+
+	// [[52([8, h'0a']), [[[...]]]]] nested beyond maxDepth
+	data, _ := hex.DecodeString("8182d83482084110")
+	data = append(data, bytes.Repeat([]byte{0x81}, maxDepth+2)...)
+	data = append(data, 0x80)
+
+	tbl := new(bart.Table[any])
+	if err := Unmarshal(data, tbl); !errors.Is(err, ErrSyntax) {
+		t.Fatalf("Unmarshal, got %v, want ErrSyntax", err)
+	}
+}
+
+func TestUnmarshalUnknownField(t *testing.T) {
+	t.Parallel()
+
+	type small struct {
+		Metric int32
+	}
+
+	src := new(bart.Table[route])
+	src.Insert(mpp("10.0.0.0/8"), route{Metric: 7, Tags: []string{"x"}})
+
+	data, err := Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := new(bart.Table[small])
+	if err := Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if val, _ := got.Get(mpp("10.0.0.0/8")); val.Metric != 7 {
+		t.Fatalf("Get(10.0.0.0/8), got %v, want Metric 7", val)
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package cbor
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// maxDepth limits the nesting of decoded data items.
+const maxDepth = 64
+
+var (
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// decoder consumes data items from the front of data.
+type decoder struct {
+	data []byte
+}
+
+// peek returns the major type of the next data item.
+func (d *decoder) peek() (byte, error) {
+	if len(d.data) == 0 {
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrSyntax)
+	}
+	return d.data[0] >> 5, nil
+}
+
+// head consumes the initial byte and the argument of the next data item.
+// Indefinite lengths are not supported.
+func (d *decoder) head() (major byte, n uint64, err error) {
+	if len(d.data) == 0 {
+		return 0, 0, fmt.Errorf("%w: unexpected end of data", ErrSyntax)
+	}
+
+	major, info := d.data[0]>>5, d.data[0]&0x1f
+	d.data = d.data[1:]
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, fmt.Errorf("%w: unsupported additional info %d", ErrSyntax, info)
+	}
+
+	if len(d.data) < size {
+		return 0, 0, fmt.Errorf("%w: unexpected end of data", ErrSyntax)
+	}
+
+	switch size {
+	case 1:
+		n = uint64(d.data[0])
+	case 2:
+		n = uint64(binary.BigEndian.Uint16(d.data))
+	case 4:
+		n = uint64(binary.BigEndian.Uint32(d.data))
+	default:
+		n = binary.BigEndian.Uint64(d.data)
+	}
+	d.data = d.data[size:]
+
+	return major, n, nil
+}
+
+// length consumes the head of a data item with the given major type
+// and returns its length, checked against the remaining data.
+func (d *decoder) length(want byte) (int, error) {
+	major, n, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+	if major != want {
+		return 0, fmt.Errorf("%w: unexpected major type %d, want %d", ErrSyntax, major, want)
+	}
+	// every element needs at least one byte
+	if n > uint64(len(d.data)) {
+		return 0, fmt.Errorf("%w: length %d exceeds data", ErrSyntax, n)
+	}
+	return int(n), nil
+}
+
+// arrayLen consumes an array head and returns the number of elements.
+func (d *decoder) arrayLen() (int, error) {
+	return d.length(majorArray)
+}
+
+// bytes consumes a byte or text string, the result aliases the data.
+func (d *decoder) bytes(major byte) ([]byte, error) {
+	n, err := d.length(major)
+	if err != nil {
+		return nil, err
+	}
+	raw := d.data[:n:n]
+	d.data = d.data[n:]
+	return raw, nil
+}
+
+// isNull consumes the next data item if it is null.
+func (d *decoder) isNull() bool {
+	if len(d.data) > 0 && d.data[0] == majorSimple<<5|simpleNull {
+		d.data = d.data[1:]
+		return true
+	}
+	return false
+}
+
+// value decodes the next data item into rv.
+func (d *decoder) value(rv reflect.Value, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("%w: nesting too deep", ErrSyntax)
+	}
+
+	if d.isNull() {
+		rv.SetZero()
+		return nil
+	}
+
+	if rv.Kind() != reflect.Pointer && reflect.PointerTo(rv.Type()).Implements(binaryUnmarshalerType) {
+		raw, err := d.bytes(majorBytes)
+		if err != nil {
+			return err
+		}
+		return rv.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(raw)
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		major, n, err := d.head()
+		if err != nil {
+			return err
+		}
+		if major != majorSimple || (n != simpleFalse && n != simpleTrue) {
+			return fmt.Errorf("%w: expected bool", ErrSyntax)
+		}
+		rv.SetBool(n == simpleTrue)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := d.int()
+		if err != nil {
+			return err
+		}
+		if rv.OverflowInt(i) {
+			return fmt.Errorf("%w: %d overflows %s", ErrSyntax, i, rv.Type())
+		}
+		rv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		major, n, err := d.head()
+		if err != nil {
+			return err
+		}
+		if major != majorUint {
+			return fmt.Errorf("%w: expected unsigned integer", ErrSyntax)
+		}
+		if rv.OverflowUint(n) {
+			return fmt.Errorf("%w: %d overflows %s", ErrSyntax, n, rv.Type())
+		}
+		rv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := d.float()
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+
+	case reflect.String:
+		raw, err := d.bytes(majorText)
+		if err != nil {
+			return err
+		}
+		rv.SetString(string(raw))
+
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			raw, err := d.bytes(majorBytes)
+			if err != nil {
+				return err
+			}
+			rv.SetBytes(append([]byte{}, raw...))
+			return nil
+		}
+
+		n, err := d.arrayLen()
+		if err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(rv.Type(), n, n)
+		for i := range n {
+			if err := d.value(s.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+
+	case reflect.Array:
+		n, err := d.arrayLen()
+		if err != nil {
+			return err
+		}
+		if n != rv.Len() {
+			return fmt.Errorf("%w: array length %d, want %d", ErrSyntax, n, rv.Len())
+		}
+		for i := range n {
+			if err := d.value(rv.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		n, err := d.length(majorMap)
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), n)
+		for range n {
+			key := reflect.New(rv.Type().Key()).Elem()
+			if err := d.value(key, depth+1); err != nil {
+				return err
+			}
+			val := reflect.New(rv.Type().Elem()).Elem()
+			if err := d.value(val, depth+1); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, val)
+		}
+		rv.Set(m)
+
+	case reflect.Struct:
+		return d.structValue(rv, depth)
+
+	case reflect.Pointer:
+		p := reflect.New(rv.Type().Elem())
+		if err := d.value(p.Elem(), depth+1); err != nil {
+			return err
+		}
+		rv.Set(p)
+
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return fmt.Errorf("%w: unsupported type %s", ErrSyntax, rv.Type())
+		}
+		v, err := d.generic(depth)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			rv.Set(reflect.ValueOf(v))
+		} else {
+			rv.SetZero()
+		}
+
+	default:
+		return fmt.Errorf("%w: unsupported type %s", ErrSyntax, rv.Type())
+	}
+
+	return nil
+}
+
+// structValue decodes a map into the exported fields of a struct,
+// unknown keys are skipped.
+func (d *decoder) structValue(rv reflect.Value, depth int) error {
+	n, err := d.length(majorMap)
+	if err != nil {
+		return err
+	}
+
+	fields := structFields(rv.Type())
+
+	rv.SetZero()
+	for range n {
+		name, err := d.bytes(majorText)
+		if err != nil {
+			return err
+		}
+
+		idx := -1
+		for _, f := range fields {
+			if f.name == string(name) {
+				idx = f.index
+				break
+			}
+		}
+
+		if idx < 0 {
+			if _, err := d.generic(depth + 1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.value(rv.Field(idx), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// int decodes a signed or unsigned integer into an int64.
+func (d *decoder) int() (int64, error) {
+	major, n, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+	if (major != majorUint && major != majorNegInt) || n > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: expected int64", ErrSyntax)
+	}
+	if major == majorNegInt {
+		return -1 - int64(n), nil
+	}
+	return int64(n), nil
+}
+
+// float decodes a half, single or double precision float,
+// integers are converted.
+func (d *decoder) float() (float64, error) {
+	if len(d.data) == 0 {
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrSyntax)
+	}
+
+	initial := d.data[0]
+	if major := initial >> 5; major == majorUint || major == majorNegInt {
+		i, err := d.int()
+		return float64(i), err
+	}
+
+	_, n, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+
+	switch initial {
+	case majorSimple<<5 | simpleFloat16:
+		return float16(uint16(n)), nil
+	case majorSimple<<5 | simpleFloat32:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case majorSimple<<5 | simpleFloat64:
+		return math.Float64frombits(n), nil
+	default:
+		return 0, fmt.Errorf("%w: expected float", ErrSyntax)
+	}
+}
+
+// float16 converts an IEEE 754 half precision float.
+func float16(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+	exp := int(h>>10) & 0x1f
+	frac := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	default:
+		return sign * math.Ldexp(frac+1024, exp-25)
+	}
+}
+
+// generic decodes the next data item into the natural Go types:
+// uint64, int64, float64, bool, string, []byte, []any, map[string]any
+// and nil for null.
+func (d *decoder) generic(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nesting too deep", ErrSyntax)
+	}
+
+	if len(d.data) == 0 {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrSyntax)
+	}
+
+	switch initial := d.data[0]; initial >> 5 {
+	case majorUint:
+		_, n, err := d.head()
+		return n, err
+
+	case majorNegInt:
+		return d.int()
+
+	case majorBytes:
+		raw, err := d.bytes(majorBytes)
+		return append([]byte{}, raw...), err
+
+	case majorText:
+		raw, err := d.bytes(majorText)
+		return string(raw), err
+
+	case majorArray:
+		n, err := d.arrayLen()
+		if err != nil {
+			return nil, err
+		}
+		s := make([]any, n)
+		for i := range s {
+			if s[i], err = d.generic(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+
+	case majorMap:
+		n, err := d.length(majorMap)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, n)
+		for range n {
+			key, err := d.bytes(majorText)
+			if err != nil {
+				return nil, err
+			}
+			if m[string(key)], err = d.generic(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+
+	case majorSimple:
+		switch initial & 0x1f {
+		case simpleFalse, simpleTrue:
+			d.data = d.data[1:]
+			return initial&0x1f == simpleTrue, nil
+		case simpleNull:
+			d.data = d.data[1:]
+			return nil, nil
+		default:
+			return d.float()
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported tag", ErrSyntax)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package cbor
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// CBOR major types
+const (
+	majorUint   byte = 0
+	majorNegInt byte = 1
+	majorBytes  byte = 2
+	majorText   byte = 3
+	majorArray  byte = 4
+	majorMap    byte = 5
+	majorTag    byte = 6
+	majorSimple byte = 7
+)
+
+// simple values and float markers of major type 7
+const (
+	simpleFalse   = 20
+	simpleTrue    = 21
+	simpleNull    = 22
+	simpleFloat16 = 25
+	simpleFloat32 = 26
+	simpleFloat64 = 27
+)
+
+var binaryMarshalerType = reflect.TypeFor[encoding.BinaryMarshaler]()
+
+// appendHead appends the initial byte and the argument of a data item.
+func appendHead(buf []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(buf, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, m|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, m|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, m|27), n)
+	}
+}
+
+// appendValue appends the CBOR encoding of rv.
+func appendValue(buf []byte, rv reflect.Value) ([]byte, error) {
+	if !rv.IsValid() {
+		return append(buf, majorSimple<<5|simpleNull), nil
+	}
+
+	if rv.Type().Implements(binaryMarshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return append(buf, majorSimple<<5|simpleNull), nil
+		}
+		raw, err := rv.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return append(appendHead(buf, majorBytes, uint64(len(raw))), raw...), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return append(buf, majorSimple<<5|simpleTrue), nil
+		}
+		return append(buf, majorSimple<<5|simpleFalse), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := rv.Int(); i < 0 {
+			return appendHead(buf, majorNegInt, uint64(-(i + 1))), nil
+		}
+		return appendHead(buf, majorUint, uint64(rv.Int())), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendHead(buf, majorUint, rv.Uint()), nil
+
+	case reflect.Float32:
+		buf = append(buf, majorSimple<<5|simpleFloat32)
+		return binary.BigEndian.AppendUint32(buf, math.Float32bits(float32(rv.Float()))), nil
+
+	case reflect.Float64:
+		buf = append(buf, majorSimple<<5|simpleFloat64)
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(rv.Float())), nil
+
+	case reflect.String:
+		buf = appendHead(buf, majorText, uint64(rv.Len()))
+		return append(buf, rv.String()...), nil
+
+	case reflect.Slice:
+		if rv.IsNil() {
+			return append(buf, majorSimple<<5|simpleNull), nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			buf = appendHead(buf, majorBytes, uint64(rv.Len()))
+			return append(buf, rv.Bytes()...), nil
+		}
+		return appendArray(buf, rv)
+
+	case reflect.Array:
+		return appendArray(buf, rv)
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return append(buf, majorSimple<<5|simpleNull), nil
+		}
+		return appendMap(buf, rv)
+
+	case reflect.Struct:
+		return appendStruct(buf, rv)
+
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return append(buf, majorSimple<<5|simpleNull), nil
+		}
+		return appendValue(buf, rv.Elem())
+
+	default:
+		return nil, fmt.Errorf("unsupported type %s", rv.Type())
+	}
+}
+
+// appendArray appends the elements of a slice or array.
+func appendArray(buf []byte, rv reflect.Value) ([]byte, error) {
+	var err error
+	buf = appendHead(buf, majorArray, uint64(rv.Len()))
+	for i := range rv.Len() {
+		if buf, err = appendValue(buf, rv.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// appendMap appends a map, the entries are sorted by their encoded
+// keys for a deterministic encoding.
+func appendMap(buf []byte, rv reflect.Value) ([]byte, error) {
+	type item struct{ key, val []byte }
+
+	items := make([]item, 0, rv.Len())
+	for iter := rv.MapRange(); iter.Next(); {
+		key, err := appendValue(nil, iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		val, err := appendValue(nil, iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item{key, val})
+	}
+
+	slices.SortFunc(items, func(a, b item) int { return bytes.Compare(a.key, b.key) })
+
+	buf = appendHead(buf, majorMap, uint64(len(items)))
+	for _, it := range items {
+		buf = append(buf, it.key...)
+		buf = append(buf, it.val...)
+	}
+	return buf, nil
+}
+
+// appendStruct appends the exported fields of a struct as map.
+func appendStruct(buf []byte, rv reflect.Value) ([]byte, error) {
+	fields := structFields(rv.Type())
+
+	var err error
+	buf = appendHead(buf, majorMap, uint64(len(fields)))
+	for _, f := range fields {
+		buf = appendHead(buf, majorText, uint64(len(f.name)))
+		buf = append(buf, f.name...)
+		if buf, err = appendValue(buf, rv.Field(f.index)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// field is an encoded struct field.
+type field struct {
+	name  string
+	index int
+}
+
+// structFields returns the encoded fields of the struct type t.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("cbor"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{name: name, index: i})
+	}
+	return fields
+}
@@ -38,6 +38,7 @@
 package bart
 
 import (
+	"fmt"
 	"net/netip"
 
 	"github.com/admpub/bart/internal/nodes"
@@ -58,3 +59,240 @@ type DumpListNode[V any] struct {
 	Value   V                 `json:"value"`
 	Subnets []DumpListNode[V] `json:"subnets,omitempty"`
 }
+
+// PrefixValue is a prefix with its value, e.g. as element
+// of a materialized table, see [Table.AppendEntries].
+type PrefixValue[V any] struct {
+	Prefix netip.Prefix `json:"prefix"`
+	Value  V            `json:"value"`
+}
+
+// appendDumpList appends the flattened entries of the dump list nodes to dst.
+func appendDumpList[V any](dst []PrefixValue[V], list []DumpListNode[V]) []PrefixValue[V] {
+	for _, n := range list {
+		dst = append(dst, PrefixValue[V]{Prefix: n.CIDR, Value: n.Value})
+		dst = appendDumpList(dst, n.Subnets)
+	}
+	return dst
+}
+
+// Stats contains statistics about the trie structure of a table,
+// per address family.
+type Stats struct {
+	IPv4 FamilyStats `json:"ipv4"`
+	IPv6 FamilyStats `json:"ipv6"`
+}
+
+// FamilyStats contains statistics about the trie of one address family.
+type FamilyStats struct {
+	Size     int `json:"size"`     // stored prefixes
+	Nodes    int `json:"nodes"`    // inner nodes, including the root node
+	Prefixes int `json:"prefixes"` // prefixes stored in inner nodes
+	Children int `json:"children"` // occupied child slots of inner nodes
+	Leaves   int `json:"leaves"`   // path-compressed leaf nodes
+	Fringes  int `json:"fringes"`  // path-compressed fringe nodes
+
+	// MaxDepth is the number of inner node levels, 0 for an empty trie.
+	MaxDepth int `json:"max_depth"`
+
+	// AvgDepth is the average number of inner node levels traversed
+	// to reach a stored prefix, the cost of an exact match.
+	AvgDepth float64 `json:"avg_depth"`
+
+	// NodesPerLevel are the inner nodes per depth, the root node
+	// is at level 0. IPv4 tries use only the first 4 levels.
+	NodesPerLevel [maxTreeDepth]int `json:"nodes_per_level"`
+
+	AvgPrefixes float64 `json:"avg_prefixes"` // prefixes per inner node
+	AvgChildren float64 `json:"avg_children"` // occupied child slots per inner node
+}
+
+// maxTreeDepth is the maximum number of inner node levels, 128/8 for IPv6.
+const maxTreeDepth = 16
+
+// String returns the counters in a short human-readable form.
+func (s FamilyStats) String() string {
+	return fmt.Sprintf("%d prefixes, %d nodes, %d leaves, %d fringes", s.Size, s.Nodes, s.Leaves, s.Fringes)
+}
+
+// addNode aggregates the occupancy of an inner node,
+// the node callback of the trie walk for the statistics.
+func (s *FamilyStats) addNode(o nodes.Occupancy) bool {
+	prefixes := o.Prefixes.Size()
+	leaves := o.Leaves.Size()
+	fringes := o.Fringes.Size()
+
+	s.Nodes++
+	s.Prefixes += prefixes
+	s.Children += o.Children.Size()
+	s.Leaves += leaves
+	s.Fringes += fringes
+
+	s.NodesPerLevel[o.Depth]++
+	s.MaxDepth = max(s.MaxDepth, o.Depth+1)
+
+	// sum of the levels, divided by the size in finish
+	s.AvgDepth += float64((prefixes + leaves + fringes) * (o.Depth + 1))
+
+	return true
+}
+
+// finish computes the averages after the trie walk.
+func (s *FamilyStats) finish() {
+	if s.Nodes == 0 {
+		return
+	}
+
+	s.AvgDepth /= float64(s.Prefixes + s.Leaves + s.Fringes)
+	s.AvgPrefixes = float64(s.Prefixes) / float64(s.Nodes)
+	s.AvgChildren = float64(s.Children) / float64(s.Nodes)
+}
+
+// TrieDump is a machine-readable representation of the internal trie
+// structure of a table, e.g. for external tools to inspect the exact
+// internal state or to reproduce a bug reported with a specific prefix
+// set. A family without prefixes has a nil root.
+type TrieDump struct {
+	IPv4 *TrieNode `json:"ipv4,omitempty"`
+	IPv6 *TrieNode `json:"ipv6,omitempty"`
+}
+
+// TrieNode is an inner trie node with its subtrie, see [TrieDump].
+// The prefix indexes are the base indexes 1..255 of the ART algorithm,
+// see [NodeOccupancy].
+type TrieNode struct {
+	Prefix   netip.Prefix   `json:"prefix"`             // octet path of the node
+	Depth    int            `json:"depth"`              // stride depth, 0 for the root node
+	Indexes  []int          `json:"indexes,omitempty"`  // set prefix indexes
+	Prefixes []netip.Prefix `json:"prefixes,omitempty"` // the prefixes of the indexes
+	Children []int          `json:"children,omitempty"` // occupied child octets: nodes, leaves and fringes
+	Leaves   []TrieLeaf     `json:"leaves,omitempty"`   // path-compressed leaves
+	Fringes  []TrieLeaf     `json:"fringes,omitempty"`  // path-compressed fringes
+	Nodes    []TrieNode     `json:"nodes,omitempty"`    // inner child nodes, in octet order
+}
+
+// TrieLeaf is a path-compressed leaf or fringe in the child slot Octet.
+type TrieLeaf struct {
+	Octet  int          `json:"octet"`
+	Prefix netip.Prefix `json:"prefix"`
+}
+
+// newTrieNode converts the recursive node dump.
+func newTrieNode(d nodes.TrieDump, is4 bool) TrieNode {
+	var path stridePath
+	copy(path[:], d.Cidr.Addr().AsSlice())
+
+	tn := TrieNode{
+		Prefix: d.Cidr,
+		Depth:  d.Depth,
+	}
+
+	for _, idx := range d.Indexes {
+		tn.Indexes = append(tn.Indexes, int(idx))
+		tn.Prefixes = append(tn.Prefixes, nodes.CidrFromPath(path, d.Depth, is4, idx))
+	}
+	for _, addr := range d.Children {
+		tn.Children = append(tn.Children, int(addr))
+	}
+	for i, addr := range d.LeafAddrs {
+		tn.Leaves = append(tn.Leaves, TrieLeaf{Octet: int(addr), Prefix: d.LeafPfxs[i]})
+	}
+	for _, addr := range d.FringeAddr {
+		pfx := nodes.CidrForFringe(path[:d.Depth], d.Depth, is4, addr)
+		tn.Fringes = append(tn.Fringes, TrieLeaf{Octet: int(addr), Prefix: pfx})
+	}
+	for _, kid := range d.Nodes {
+		tn.Nodes = append(tn.Nodes, newTrieNode(kid, is4))
+	}
+
+	return tn
+}
+
+// TraceKind classifies a [TraceStep] of a traced lookup.
+type TraceKind uint8
+
+// The kinds of steps, in the order they occur during a lookup.
+const (
+	TraceDescend   TraceKind = iota // child node at octet, descend one stride
+	TraceNoChild                    // no child at octet, stop descending
+	TraceLeaf                       // path-compressed leaf at octet, Hit if it covers the address
+	TraceFringe                     // path-compressed fringe at octet, always a Hit
+	TraceBacktrack                  // prefix test in a node while backtracking, Hit on match
+)
+
+// String returns the name of the kind, e.g. "descend".
+func (k TraceKind) String() string {
+	switch k {
+	case TraceDescend:
+		return "descend"
+	case TraceNoChild:
+		return "nochild"
+	case TraceLeaf:
+		return "leaf"
+	case TraceFringe:
+		return "fringe"
+	case TraceBacktrack:
+		return "backtrack"
+	}
+	return fmt.Sprintf("TraceKind(%d)", uint8(k))
+}
+
+// TraceStep reports a single step of a traced lookup, see LookupTrace.
+//
+// Depth and Octet identify the stride, Node is the address range of
+// the visited trie node. Idx is the tested ART prefix index, only set
+// for TraceBacktrack. Prefix is the leaf or fringe prefix, or the
+// matching prefix of a successful backtrack step.
+type TraceStep struct {
+	Kind   TraceKind
+	Depth  int
+	Octet  uint8
+	Idx    uint8
+	Node   netip.Prefix
+	Prefix netip.Prefix
+	Hit    bool
+}
+
+// String returns a compact one-line representation of the step.
+func (s TraceStep) String() string {
+	str := fmt.Sprintf("%s depth=%d octet=%d node=%s", s.Kind, s.Depth, s.Octet, s.Node)
+	if s.Kind == TraceBacktrack {
+		str += fmt.Sprintf(" idx=%d", s.Idx)
+	}
+	if s.Prefix.IsValid() {
+		str += fmt.Sprintf(" prefix=%s", s.Prefix)
+	}
+	if s.Hit {
+		str += " hit"
+	}
+	return str
+}
+
+// NodeOccupancy describes the occupied slots of a single trie node,
+// in a compact form suitable e.g. for heat-map visualizations.
+//
+// Each node covers a stride of 8 bits. The prefix slots are the
+// indices 1..255 of a complete binary tree over the stride, index 1
+// is the node prefix itself, see the ART algorithm. The child slots
+// 0..255 are the next octet. The bitsets are little-endian: bit i is
+// set in word i/64 at position i%64.
+type NodeOccupancy struct {
+	Prefix   netip.Prefix `json:"prefix"`   // address range of the node
+	Depth    int          `json:"depth"`    // stride depth, 0 for the root node
+	Prefixes [4]uint64    `json:"prefixes"` // occupied prefix slots
+	Children [4]uint64    `json:"children"` // occupied child slots
+	Leaves   [4]uint64    `json:"leaves"`   // subset of Children: path-compressed leaves
+	Fringes  [4]uint64    `json:"fringes"`  // subset of Children: path-compressed fringes
+}
+
+// newNodeOccupancy converts the node occupancy.
+func newNodeOccupancy(o nodes.Occupancy) NodeOccupancy {
+	return NodeOccupancy{
+		Prefix:   o.Cidr,
+		Depth:    o.Depth,
+		Prefixes: o.Prefixes,
+		Children: o.Children,
+		Leaves:   o.Leaves,
+		Fringes:  o.Fringes,
+	}
+}
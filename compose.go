@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+
+	"github.com/admpub/bart/internal/nodes"
+	"github.com/admpub/bart/internal/value"
+)
+
+// Composer evaluates set expressions over several routing tables,
+// e.g. for policy engines combining many route feeds:
+//
+//	result := bart.Compose(feedA).Union(feedB).Subtract(bogons).Build()
+//
+// The expression is only recorded until [Composer.Build] is called.
+// All operand tables are then traversed once, in lockstep, and every
+// prefix is evaluated from left to right over all operands, no
+// intermediate table is materialized for any sub-expression.
+//
+// The input tables are never modified.
+type Composer[V any] struct {
+	base *Table[V]
+	ops  []composeOp[V]
+}
+
+// composeOp is a recorded step of the expression.
+type composeOp[V any] struct {
+	tbl      *Table[V]
+	subtract bool
+}
+
+// Compose starts a set expression with t as the first operand.
+// A nil table is treated as the empty table.
+func Compose[V any](t *Table[V]) *Composer[V] {
+	return &Composer[V]{base: t}
+}
+
+// Union records the union with o, see [Table.Union].
+// For duplicate prefixes the value from o wins.
+func (c *Composer[V]) Union(o *Table[V]) *Composer[V] {
+	c.ops = append(c.ops, composeOp[V]{tbl: o})
+	return c
+}
+
+// Subtract records the removal of all prefixes of o (exact match)
+// from the result so far.
+func (c *Composer[V]) Subtract(o *Table[V]) *Composer[V] {
+	c.ops = append(c.ops, composeOp[V]{tbl: o, subtract: true})
+	return c
+}
+
+// Build evaluates the expression and returns the resulting table.
+//
+// The values are cloned if V implements the Cloner interface,
+// see [Table.Clone].
+func (c *Composer[V]) Build() *Table[V] {
+	ns4 := make([]*nodes.BartNode[V], 0, len(c.ops)+1)
+	ns6 := make([]*nodes.BartNode[V], 0, len(c.ops)+1)
+	subtract := make([]bool, 0, len(c.ops)+1)
+
+	add := func(t *Table[V], sub bool) {
+		if t == nil {
+			return
+		}
+		ns4 = append(ns4, &t.root4)
+		ns6 = append(ns6, &t.root6)
+		subtract = append(subtract, sub)
+	}
+
+	add(c.base, false)
+	for _, op := range c.ops {
+		add(op.tbl, op.subtract)
+	}
+
+	result := new(Table[V])
+	fn := func(pfx netip.Prefix, val V) {
+		result.Insert(pfx, value.CloneVal(val))
+	}
+
+	nodes.ComposeRec(ns4, subtract, stridePath{}, 0, true, fn)
+	nodes.ComposeRec(ns6, subtract, stridePath{}, 0, false, fn)
+
+	return result
+}
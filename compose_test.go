@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestComposeNil(t *testing.T) {
+	t.Parallel()
+
+	got := Compose[int](nil).Union(nil).Subtract(nil).Build()
+	if got == nil || got.Size() != 0 {
+		t.Fatalf("Build, expected empty table, got: %v", got)
+	}
+}
+
+func TestComposeCompare(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	a := new(Table[int])
+	b := new(Table[int])
+	c := new(Table[int])
+
+	pfxs := random.RealWorldPrefixes(prng, n)
+	for i, pfx := range pfxs {
+		switch i % 3 {
+		case 0:
+			a.Insert(pfx, 1)
+		case 1:
+			b.Insert(pfx, 2)
+		case 2:
+			a.Insert(pfx, 1)
+			b.Insert(pfx, 2)
+		}
+		if i%4 == 0 {
+			c.Insert(pfx, 3)
+		}
+	}
+
+	aClone := a.Clone()
+
+	got := Compose(a).Union(b).Subtract(c).Build()
+
+	// step by step, materializing the intermediate table
+	want := a.Clone()
+	want.Union(b)
+	for pfx := range c.All() {
+		want.Delete(pfx)
+	}
+
+	if !got.Equal(want) {
+		t.Fatal("Compose(a).Union(b).Subtract(c), expected equal to step by step evaluation")
+	}
+
+	if !a.Equal(aClone) {
+		t.Fatal("Compose, input table is modified")
+	}
+}
+
+func TestComposeOrder(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	a := new(Table[int])
+	b := new(Table[int])
+	c := new(Table[int])
+
+	// a and c with deep subtrees, b mostly leaves at the same addrs
+	for i, pfx := range random.RealWorldPrefixes(prng, n) {
+		a.Insert(pfx, i)
+		if i%3 == 0 {
+			c.Insert(pfx, -i)
+		}
+		if i%5 == 0 {
+			b.Insert(pfx, 2*i)
+		}
+	}
+	b.Insert(mpp("10.0.0.0/8"), 1)
+	b.Insert(mpp("0.0.0.0/0"), 2)
+	b.Insert(mpp("2001:db8::/32"), 3)
+
+	// subtracted prefixes are added again by the later union
+	got := Compose(a).Subtract(c).Union(b).Subtract(nil).Union(c).Subtract(b).Build()
+
+	want := a.Clone()
+	for pfx := range c.All() {
+		want.Delete(pfx)
+	}
+	want.Union(b)
+	want.Union(c)
+	for pfx := range b.All() {
+		want.Delete(pfx)
+	}
+
+	if !got.Equal(want) || got.Size4() != want.Size4() || got.Size6() != want.Size6() {
+		t.Fatal("Compose, expected equal to step by step evaluation")
+	}
+
+	// the rightmost union wins
+	got = Compose(c).Union(a).Union(c).Build()
+	want = c.Clone()
+	want.Union(a)
+	want.Union(c)
+
+	if !got.Equal(want) {
+		t.Fatal("Compose, duplicate operand, expected equal to step by step evaluation")
+	}
+}
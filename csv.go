@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// LoadCSV reads CSV records from r and returns a new table, e.g. for
+// routes exported from IPAM systems or spreadsheets. The first field of
+// every record is the prefix, the remaining fields are passed to parseVal.
+// The number of fields may vary between records.
+//
+// Lines starting with '#' are comments. A header row is skipped if it is
+// the first record and its first field is not a valid prefix. Prefixes
+// are masked, duplicate prefixes overwrite previous values.
+func LoadCSV[V any](r io.Reader, parseVal func(fields []string) (V, error)) (*Table[V], error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	tbl := new(Table[V])
+
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return tbl, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bart: read csv: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		pfx, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+		if err != nil {
+			if first {
+				continue
+			}
+			return nil, fmt.Errorf("bart: csv line %d: %w", line, err)
+		}
+
+		val, err := parseVal(record[1:])
+		if err != nil {
+			return nil, fmt.Errorf("bart: csv line %d: parse value of %s: %w", line, pfx, err)
+		}
+
+		tbl.Insert(pfx, val)
+	}
+}
+
+// WriteCSV writes t as CSV records to w in natural CIDR sort order,
+// one record per entry: the prefix followed by the fields returned by
+// formatVal. A nil formatVal writes only the prefixes. The output can
+// be read with [LoadCSV].
+func WriteCSV[V any](w io.Writer, t *Table[V], formatVal func(V) ([]string, error)) error {
+	cw := csv.NewWriter(w)
+
+	var record []string
+	for pfx, val := range t.AllSorted() {
+		record = append(record[:0], pfx.String())
+
+		if formatVal != nil {
+			fields, err := formatVal(val)
+			if err != nil {
+				return fmt.Errorf("bart: format value of %s: %w", pfx, err)
+			}
+			record = append(record, fields...)
+		}
+
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("bart: write csv: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("bart: write csv: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+type csvRoute struct {
+	nextHop string
+	metric  int
+}
+
+func parseCSVRoute(fields []string) (csvRoute, error) {
+	if len(fields) != 2 {
+		return csvRoute{}, errors.New("want next-hop and metric")
+	}
+	metric, err := strconv.Atoi(fields[1])
+	return csvRoute{nextHop: fields[0], metric: metric}, err
+}
+
+func formatCSVRoute(r csvRoute) ([]string, error) {
+	return []string{r.nextHop, strconv.Itoa(r.metric)}, nil
+}
+
+func TestCSVRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(Table[csvRoute])
+	for i, pfx := range random.RealWorldPrefixes(prng, 1_000) {
+		tbl.Insert(pfx, csvRoute{nextHop: "gw, \"" + strconv.Itoa(i%7) + "\"", metric: i})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, tbl, formatCSVRoute); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadCSV(&buf, parseCSVRoute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(tbl) {
+		t.Fatal("LoadCSV(WriteCSV(tbl)), expected equal tables")
+	}
+}
+
+func TestCSVLoad(t *testing.T) {
+	t.Parallel()
+
+	input := `prefix,next-hop,metric
+# comment
+10.0.0.0/8, 192.0.2.1, 10
+10.1.2.3/16,192.0.2.2,20
+2001:db8::/32,2001:db8::1,30
+10.0.0.0/8,192.0.2.9,99
+`
+
+	got, err := LoadCSV(strings.NewReader(input), parseCSVRoute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := new(Table[csvRoute])
+	want.Insert(mpp("10.0.0.0/8"), csvRoute{"192.0.2.9", 99})
+	want.Insert(mpp("10.1.0.0/16"), csvRoute{"192.0.2.2", 20})
+	want.Insert(mpp("2001:db8::/32"), csvRoute{"2001:db8::1", 30})
+
+	if !got.Equal(want) {
+		t.Fatalf("LoadCSV, got:\n%s\nwant:\n%s", got.DumpString(), want.DumpString())
+	}
+}
+
+func TestCSVLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"invalid prefix", "10.0.0.0/8,gw,1\n10.0.0.0/33,gw,1\n", "csv line 2"},
+		{"invalid value", "10.0.0.0/8,gw\n", "csv line 1: parse value of 10.0.0.0/8"},
+		{"invalid metric", "# head\n10.0.0.0/8,gw,x\n", "csv line 2"},
+		{"csv syntax", "10.0.0.0/8,\"gw,1\n", "read csv"},
+	}
+
+	for _, tt := range tests {
+		_, err := LoadCSV(strings.NewReader(tt.input), parseCSVRoute)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("LoadCSV(%s), got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCSVWrite(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("2001:db8::/32"), 3)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, tbl, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "10.0.0.0/8\n10.1.0.0/16\n2001:db8::/32\n"
+	if buf.String() != want {
+		t.Fatalf("WriteCSV, got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	wantErr := errors.New("boom")
+	err := WriteCSV(&buf, tbl, func(int) ([]string, error) { return nil, wantErr })
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("WriteCSV, got error %v, want %v", err, wantErr)
+	}
+
+	buf.Reset()
+	if err := WriteCSV[int](&buf, nil, nil); err != nil || buf.Len() != 0 {
+		t.Fatalf("WriteCSV(nil), got (%q, %v), want empty", buf.String(), err)
+	}
+}
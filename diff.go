@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"slices"
+
+	"github.com/admpub/bart/internal/nodes"
+)
+
+// Change is a single route change between two table versions.
+// Old is the zero value for added routes, New for removed routes.
+type Change[V any] struct {
+	Prefix netip.Prefix
+	Old    V
+	New    V
+}
+
+// ChangeSet is the explicit set of route changes between two table
+// versions, each list in natural CIDR sort order of the prefixes.
+type ChangeSet[V any] struct {
+	Added   []Change[V]
+	Removed []Change[V]
+	Changed []Change[V]
+}
+
+// Len returns the total number of changes.
+func (c ChangeSet[V]) Len() int {
+	return len(c.Added) + len(c.Removed) + len(c.Changed)
+}
+
+// Diff computes the explicit change set from the table version prev to
+// next, e.g. to program the deltas between successive RIB versions into
+// a FIB. A nil table is treated as empty. The values are compared with
+// their Equal method, if implemented, or reflect.DeepEqual.
+//
+// Both tries are walked in lockstep, subtrees shared between the versions,
+// as left by the persistent methods, are skipped without visiting their
+// entries.
+func Diff[V any](prev, next *Table[V]) (cs ChangeSet[V]) {
+	if prev == next {
+		return cs
+	}
+	if prev == nil {
+		prev = new(Table[V])
+	}
+	if next == nil {
+		next = new(Table[V])
+	}
+
+	fn := func(pfx netip.Prefix, valA, valB V, inA, inB bool) {
+		switch {
+		case !inB:
+			cs.Removed = append(cs.Removed, Change[V]{Prefix: pfx, Old: valA})
+		case !inA:
+			cs.Added = append(cs.Added, Change[V]{Prefix: pfx, New: valB})
+		default:
+			cs.Changed = append(cs.Changed, Change[V]{Prefix: pfx, Old: valA, New: valB})
+		}
+	}
+
+	nodes.DiffRec(&prev.root4, &next.root4, stridePath{}, 0, true, fn)
+	nodes.DiffRec(&prev.root6, &next.root6, stridePath{}, 0, false, fn)
+
+	cmp := func(a, b Change[V]) int { return nodes.CmpPrefix(a.Prefix, b.Prefix) }
+	slices.SortFunc(cs.Added, cmp)
+	slices.SortFunc(cs.Removed, cmp)
+	slices.SortFunc(cs.Changed, cmp)
+
+	return cs
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/admpub/bart/internal/nodes"
+	"github.com/admpub/bart/internal/tests/random"
+	"github.com/admpub/bart/internal/value"
+)
+
+// diffMergeJoin is the simple but slow reference for Diff,
+// a merge-join of both tables in natural CIDR sort order.
+func diffMergeJoin[V any](a, b *Table[V]) (cs ChangeSet[V]) {
+	nextA, stopA := iter.Pull2(a.AllSorted())
+	defer stopA()
+	nextB, stopB := iter.Pull2(b.AllSorted())
+	defer stopB()
+
+	pfxA, valA, okA := nextA()
+	pfxB, valB, okB := nextB()
+
+	for okA || okB {
+		cmp := 0
+		switch {
+		case !okA:
+			cmp = 1
+		case !okB:
+			cmp = -1
+		default:
+			cmp = nodes.CmpPrefix(pfxA, pfxB)
+		}
+
+		switch {
+		case cmp < 0:
+			cs.Removed = append(cs.Removed, Change[V]{Prefix: pfxA, Old: valA})
+			pfxA, valA, okA = nextA()
+		case cmp > 0:
+			cs.Added = append(cs.Added, Change[V]{Prefix: pfxB, New: valB})
+			pfxB, valB, okB = nextB()
+		default:
+			if !value.Equal(valA, valB) {
+				cs.Changed = append(cs.Changed, Change[V]{Prefix: pfxA, Old: valA, New: valB})
+			}
+			pfxA, valA, okA = nextA()
+			pfxB, valB, okB = nextB()
+		}
+	}
+
+	return cs
+}
+
+func TestDiffCompare(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	pfxs := random.RealWorldPrefixes(prng, 2*n)
+
+	a := new(Table[int])
+	b := new(Table[int])
+	for i, pfx := range pfxs {
+		switch prng.IntN(4) {
+		case 0:
+			a.Insert(pfx, i)
+		case 1:
+			b.Insert(pfx, i)
+		case 2:
+			a.Insert(pfx, i)
+			b.Insert(pfx, i)
+		default:
+			a.Insert(pfx, i)
+			b.Insert(pfx, -i)
+		}
+	}
+
+	cs := Diff(a, b)
+
+	want := diffMergeJoin(a, b)
+	if !slices.Equal(cs.Added, want.Added) ||
+		!slices.Equal(cs.Removed, want.Removed) ||
+		!slices.Equal(cs.Changed, want.Changed) {
+		t.Fatal("Diff differs from the merge-join reference")
+	}
+
+	// apply the change set to a, must result in b
+	got := a.Clone()
+	for _, c := range cs.Removed {
+		if val, ok := b.Get(c.Prefix); ok {
+			t.Fatalf("Removed %s, but in b with %d", c.Prefix, val)
+		}
+		got.Delete(c.Prefix)
+	}
+	for _, c := range cs.Added {
+		if _, ok := a.Get(c.Prefix); ok {
+			t.Fatalf("Added %s, but already in a", c.Prefix)
+		}
+		got.Insert(c.Prefix, c.New)
+	}
+	for _, c := range cs.Changed {
+		if c.Old == c.New {
+			t.Fatalf("Changed %s, but equal values %d", c.Prefix, c.Old)
+		}
+		got.Insert(c.Prefix, c.New)
+	}
+
+	if !got.Equal(b) {
+		t.Fatal("a + changes != b")
+	}
+
+	if cs := Diff(a, a); cs.Len() != 0 {
+		t.Fatalf("diff with itself, got %d changes", cs.Len())
+	}
+	if cs := Diff(a, a.Clone()); cs.Len() != 0 {
+		t.Fatalf("diff with clone, got %d changes", cs.Len())
+	}
+
+	if cs := Diff(nil, b); len(cs.Added) != b.Size() || cs.Len() != b.Size() {
+		t.Fatalf("diff from nil, got %d changes, want: %d", cs.Len(), b.Size())
+	}
+	if cs := Diff(a, nil); len(cs.Removed) != a.Size() || cs.Len() != a.Size() {
+		t.Fatalf("diff to nil, got %d changes, want: %d", cs.Len(), a.Size())
+	}
+}
+
+func TestDiffPersist(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	prev := new(Table[int])
+	for i, pfx := range pfxs {
+		prev.Insert(pfx, i)
+	}
+
+	// successive versions share most of their nodes
+	next := prev
+	for i := range 10 {
+		next = next.InsertPersist(random.Prefix(prng), -i)
+		next = next.DeletePersist(pfxs[i])
+		next = next.InsertPersist(pfxs[n/2+i], i)
+	}
+
+	cs := Diff(prev, next)
+	want := diffMergeJoin(prev, next)
+
+	if !slices.Equal(cs.Added, want.Added) ||
+		!slices.Equal(cs.Removed, want.Removed) ||
+		!slices.Equal(cs.Changed, want.Changed) {
+		t.Fatalf("Diff of persistent versions, got %d changes, want: %d", cs.Len(), want.Len())
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"container/heap"
+	"iter"
+	"net/netip"
+	"time"
+)
+
+// expiringVal is the payload of ExpiringTable, the deadline is stored
+// as Unix nanoseconds, 0 never expires.
+type expiringVal[V any] struct {
+	val      V
+	deadline int64
+}
+
+// expired reports whether the entry is expired at now.
+func (ev expiringVal[V]) expired(now int64) bool {
+	return ev.deadline != 0 && ev.deadline <= now
+}
+
+// deadlineItem is an entry in the deadline queue of ExpiringTable.
+type deadlineItem struct {
+	pfx      netip.Prefix
+	deadline int64
+}
+
+// deadlineQueue is a min-heap of deadlines, see container/heap.
+type deadlineQueue []deadlineItem
+
+func (q deadlineQueue) Len() int           { return len(q) }
+func (q deadlineQueue) Less(i, j int) bool { return q[i].deadline < q[j].deadline }
+func (q deadlineQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *deadlineQueue) Push(x any)        { *q = append(*q, x.(deadlineItem)) }
+
+func (q *deadlineQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// ExpiringTable is a routing table with a deadline per entry, e.g. for
+// threat-intel feeds or routes derived from DHCP leases that must age
+// out automatically.
+//
+// Expired entries are invisible to all queries at once. They are
+// removed by [ExpiringTable.Reap], called lazily before every mutation,
+// or periodically in the background, e.g. as Reap hook of [Maintain]:
+//
+//	go bart.Maintain(ctx, bart.MaintOpts{
+//		Interval: time.Minute,
+//		Reap:     func() { mu.Lock(); tbl.Reap(); mu.Unlock() },
+//	})
+//
+// The optional eviction callback is called for every expired entry
+// removed by Reap, not for explicit deletes.
+//
+// The zero value is ready to use. The same concurrency rules apply as for [Table].
+type ExpiringTable[V any] struct {
+	tbl Table[expiringVal[V]]
+
+	// deadline queue, outdated items of updated or deleted entries
+	// are skipped lazily.
+	queue deadlineQueue
+
+	onEvict func(pfx netip.Prefix, val V)
+
+	// time source, replaceable in tests
+	now func() time.Time
+}
+
+// NewExpiringTable returns an empty table, onEvict may be nil.
+func NewExpiringTable[V any](onEvict func(pfx netip.Prefix, val V)) *ExpiringTable[V] {
+	return &ExpiringTable[V]{onEvict: onEvict}
+}
+
+// clock returns the current time in Unix nanoseconds.
+func (t *ExpiringTable[V]) clock() int64 {
+	if t.now == nil {
+		return time.Now().UnixNano()
+	}
+	return t.now().UnixNano()
+}
+
+// Insert adds or updates a prefix-value pair without a deadline,
+// the entry never expires.
+func (t *ExpiringTable[V]) Insert(pfx netip.Prefix, val V) {
+	t.insert(pfx, val, 0)
+}
+
+// InsertUntil adds or updates a prefix-value pair, the entry expires
+// at the deadline. A deadline in the past is ignored.
+func (t *ExpiringTable[V]) InsertUntil(pfx netip.Prefix, val V, deadline time.Time) {
+	if !deadline.After(time.Unix(0, t.clock())) {
+		return
+	}
+	t.insert(pfx, val, deadline.UnixNano())
+}
+
+// InsertTTL adds or updates a prefix-value pair, the entry expires
+// after ttl. A ttl <= 0 is ignored.
+func (t *ExpiringTable[V]) InsertTTL(pfx netip.Prefix, val V, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	t.insert(pfx, val, t.clock()+int64(ttl))
+}
+
+func (t *ExpiringTable[V]) insert(pfx netip.Prefix, val V, deadline int64) {
+	if !pfx.IsValid() {
+		return
+	}
+	pfx = pfx.Masked()
+
+	t.Reap()
+	t.tbl.Insert(pfx, expiringVal[V]{val: val, deadline: deadline})
+
+	if deadline != 0 {
+		heap.Push(&t.queue, deadlineItem{pfx: pfx, deadline: deadline})
+	}
+
+	// drop the outdated items once they dominate the queue
+	if len(t.queue) > 2*t.tbl.Size()+64 {
+		t.compactQueue()
+	}
+}
+
+// compactQueue removes the outdated items from the deadline queue.
+func (t *ExpiringTable[V]) compactQueue() {
+	live := t.queue[:0]
+	for _, item := range t.queue {
+		if ev, ok := t.tbl.Get(item.pfx); ok && ev.deadline == item.deadline {
+			live = append(live, item)
+		}
+	}
+	clear(t.queue[len(live):])
+	t.queue = live
+	heap.Init(&t.queue)
+}
+
+// Delete removes the exact prefix pfx from the table.
+func (t *ExpiringTable[V]) Delete(pfx netip.Prefix) {
+	t.Reap()
+	t.tbl.Delete(pfx)
+}
+
+// Reap removes all expired entries and returns their number.
+// The eviction callback is called for every removed entry.
+func (t *ExpiringTable[V]) Reap() (evicted int) {
+	now := t.clock()
+
+	for len(t.queue) > 0 && t.queue[0].deadline <= now {
+		item := heap.Pop(&t.queue).(deadlineItem)
+
+		// skip outdated items of updated or deleted entries
+		ev, ok := t.tbl.Get(item.pfx)
+		if !ok || ev.deadline != item.deadline {
+			continue
+		}
+
+		t.tbl.Delete(item.pfx)
+		evicted++
+
+		if t.onEvict != nil {
+			t.onEvict(item.pfx, ev.val)
+		}
+	}
+
+	return evicted
+}
+
+// Deadline returns the deadline of the exact prefix pfx,
+// the zero time for entries without a deadline.
+func (t *ExpiringTable[V]) Deadline(pfx netip.Prefix) (deadline time.Time, ok bool) {
+	ev, ok := t.tbl.Get(pfx)
+	if !ok || ev.expired(t.clock()) {
+		return deadline, false
+	}
+	if ev.deadline != 0 {
+		deadline = time.Unix(0, ev.deadline)
+	}
+	return deadline, true
+}
+
+// Get returns the value of the exact prefix pfx.
+func (t *ExpiringTable[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	ev, ok := t.tbl.Get(pfx)
+	if !ok || ev.expired(t.clock()) {
+		return val, false
+	}
+	return ev.val, true
+}
+
+// Contains reports whether any unexpired prefix covers ip.
+func (t *ExpiringTable[V]) Contains(ip netip.Addr) bool {
+	_, ok := t.Lookup(ip)
+	return ok
+}
+
+// Lookup performs a longest prefix match for ip, expired entries
+// are skipped.
+func (t *ExpiringTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	ev, ok := t.tbl.Lookup(ip)
+	if !ok {
+		return val, false
+	}
+
+	now := t.clock()
+	if !ev.expired(now) {
+		return ev.val, true
+	}
+
+	// slow path, the LPM is expired but not yet reaped
+	return t.lookupSupernets(netip.PrefixFrom(ip, ip.BitLen()), now)
+}
+
+// LookupPrefix performs a longest prefix match for pfx, expired entries
+// are skipped.
+func (t *ExpiringTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	ev, ok := t.tbl.LookupPrefix(pfx)
+	if !ok {
+		return val, false
+	}
+
+	now := t.clock()
+	if !ev.expired(now) {
+		return ev.val, true
+	}
+
+	return t.lookupSupernets(pfx, now)
+}
+
+// lookupSupernets returns the longest unexpired supernet of pfx.
+func (t *ExpiringTable[V]) lookupSupernets(pfx netip.Prefix, now int64) (val V, ok bool) {
+	for _, ev := range t.tbl.Supernets(pfx) {
+		if !ev.expired(now) {
+			return ev.val, true
+		}
+	}
+	return val, false
+}
+
+// Size returns the prefix count, including expired but not yet
+// reaped entries.
+func (t *ExpiringTable[V]) Size() int {
+	return t.tbl.Size()
+}
+
+// All returns an iterator over all unexpired prefix-value pairs,
+// see [Table.All].
+func (t *ExpiringTable[V]) All() iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		now := t.clock()
+		for pfx, ev := range t.tbl.All() {
+			if !ev.expired(now) && !yield(pfx, ev.val) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestExpiringTable(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	evicted := map[netip.Prefix]int{}
+	tbl := NewExpiringTable(func(pfx netip.Prefix, val int) { evicted[pfx] = val })
+	tbl.now = func() time.Time { return now }
+
+	tbl.Insert(mpp("0.0.0.0/0"), 0)
+	tbl.InsertTTL(mpp("10.0.0.0/8"), 1, time.Minute)
+	tbl.InsertTTL(mpp("10.1.0.0/16"), 2, 2*time.Minute)
+	tbl.InsertUntil(mpp("2001:db8::/32"), 3, start.Add(time.Hour))
+
+	// ignored
+	tbl.InsertTTL(mpp("192.168.0.0/16"), 4, 0)
+	tbl.InsertUntil(mpp("192.168.0.0/16"), 4, start)
+
+	if tbl.Size() != 4 {
+		t.Fatalf("Size, got: %d, want: 4", tbl.Size())
+	}
+	if d, ok := tbl.Deadline(mpp("10.0.0.0/8")); !ok || !d.Equal(start.Add(time.Minute)) {
+		t.Errorf("Deadline, got: (%v, %v)", d, ok)
+	}
+	if d, ok := tbl.Deadline(mpp("0.0.0.0/0")); !ok || !d.IsZero() {
+		t.Errorf("Deadline, no expiry, got: (%v, %v)", d, ok)
+	}
+
+	// 10.1.0.0/16 is expired, but not yet reaped
+	now = start.Add(90 * time.Second)
+	tbl.InsertTTL(mpp("10.0.0.0/8"), 11, time.Minute) // refresh, reaps nothing
+	now = start.Add(2 * time.Minute)
+
+	if val, ok := tbl.Lookup(mpa("10.1.2.3")); !ok || val != 11 {
+		t.Errorf("Lookup, expired LPM, got: (%d, %v), want: (11, true)", val, ok)
+	}
+	if val, ok := tbl.LookupPrefix(mpp("10.1.2.0/24")); !ok || val != 11 {
+		t.Errorf("LookupPrefix, expired LPM, got: (%d, %v), want: (11, true)", val, ok)
+	}
+	if _, ok := tbl.Get(mpp("10.1.0.0/16")); ok {
+		t.Error("Get, expired entry visible")
+	}
+	n := 0
+	for range tbl.All() {
+		n++
+	}
+	if n != 3 {
+		t.Errorf("All, got: %d entries, want: 3", n)
+	}
+
+	if n := tbl.Reap(); n != 1 || evicted[mpp("10.1.0.0/16")] != 2 {
+		t.Errorf("Reap, got: %d, evicted: %v", n, evicted)
+	}
+
+	// the refreshed entry expires with the new deadline
+	now = start.Add(150 * time.Second)
+	tbl.Delete(mpp("2001:db8::/32")) // explicit deletes are no evictions
+	if _, ok := evicted[mpp("10.0.0.0/8")]; !ok || evicted[mpp("10.0.0.0/8")] != 11 {
+		t.Errorf("lazy Reap on Delete, got evicted: %v", evicted)
+	}
+	if _, ok := evicted[mpp("2001:db8::/32")]; ok {
+		t.Error("Delete, reported as eviction")
+	}
+
+	if val, ok := tbl.Lookup(mpa("10.1.2.3")); !ok || val != 0 || tbl.Size() != 1 {
+		t.Errorf("Lookup, got: (%d, %v), size: %d, want: (0, true), 1", val, ok, tbl.Size())
+	}
+}
+
+func TestExpiringTableQueueCompact(t *testing.T) {
+	t.Parallel()
+
+	var tbl ExpiringTable[int]
+
+	pfx := mpp("10.0.0.0/8")
+	for i := range 1_000 {
+		tbl.InsertTTL(pfx, i, time.Hour+time.Duration(i))
+	}
+
+	if len(tbl.queue) > 2*tbl.Size()+64 {
+		t.Errorf("deadline queue not compacted, len: %d", len(tbl.queue))
+	}
+	if val, ok := tbl.Get(pfx); !ok || val != 999 {
+		t.Errorf("Get, got: (%d, %v), want: (999, true)", val, ok)
+	}
+}
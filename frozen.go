@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// Frozen is an immutable, read-only view of a [Table], e.g. for tables
+// built once at startup and only read afterwards.
+//
+// A Frozen table owns a compacted deep copy of the source table, all
+// node slices are right-sized, see [Table.Compact]. It has no mutating
+// methods and is safe for concurrent use by any number of readers
+// without further synchronization.
+//
+// For the fastest lookups at the cost of memory build a [Fast] table
+// instead.
+type Frozen[V any] struct {
+	tbl *Table[V]
+}
+
+// Freeze returns an immutable, compacted copy of the table.
+// The values are cloned if V implements the Cloner interface.
+// Later changes of t are not visible in the frozen copy.
+func (t *Table[V]) Freeze() *Frozen[V] {
+	tbl := t.Clone()
+	if tbl == nil {
+		tbl = new(Table[V])
+	}
+	tbl.Compact()
+
+	return &Frozen[V]{tbl: tbl}
+}
+
+// Thaw returns a mutable deep copy of the frozen table.
+func (f *Frozen[V]) Thaw() *Table[V] {
+	return f.tbl.Clone()
+}
+
+// Contains reports whether any stored prefix matches ip, see [Table.Contains].
+func (f *Frozen[V]) Contains(ip netip.Addr) bool {
+	return f.tbl.Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (f *Frozen[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return f.tbl.Lookup(ip)
+}
+
+// LookupBatch performs the longest prefix match for all addresses in ips,
+// see [Table.LookupBatch].
+func (f *Frozen[V]) LookupBatch(ips []netip.Addr, out []V, ok []bool) {
+	f.tbl.LookupBatch(ips, out, ok)
+}
+
+// ContainsBatch reports for all addresses in ips whether any stored
+// prefix covers the address, see [Table.ContainsBatch].
+func (f *Frozen[V]) ContainsBatch(ips []netip.Addr, out []bool) {
+	f.tbl.ContainsBatch(ips, out)
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (f *Frozen[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	return f.tbl.LookupPrefix(pfx)
+}
+
+// LookupPrefixLPM is like LookupPrefix, but also returns the matching
+// prefix, see [Table.LookupPrefixLPM].
+func (f *Frozen[V]) LookupPrefixLPM(pfx netip.Prefix) (lpmPfx netip.Prefix, val V, ok bool) {
+	return f.tbl.LookupPrefixLPM(pfx)
+}
+
+// Get returns the value of the exact prefix pfx, see [Table.Get].
+func (f *Frozen[V]) Get(pfx netip.Prefix) (val V, exists bool) {
+	return f.tbl.Get(pfx)
+}
+
+// Supernets returns an iterator over all supernets of pfx,
+// see [Table.Supernets].
+func (f *Frozen[V]) Supernets(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return f.tbl.Supernets(pfx)
+}
+
+// Subnets returns an iterator over all subnets of pfx,
+// see [Table.Subnets].
+func (f *Frozen[V]) Subnets(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return f.tbl.Subnets(pfx)
+}
+
+// All returns an iterator over all prefix-value pairs, see [Table.All].
+func (f *Frozen[V]) All() iter.Seq2[netip.Prefix, V] {
+	return f.tbl.All()
+}
+
+// AllSorted returns an iterator over all prefix-value pairs in natural
+// CIDR sort order, see [Table.AllSorted].
+func (f *Frozen[V]) AllSorted() iter.Seq2[netip.Prefix, V] {
+	return f.tbl.AllSorted()
+}
+
+// Size returns the prefix count.
+func (f *Frozen[V]) Size() int {
+	return f.tbl.Size()
+}
+
+// Size4 returns the IPv4 prefix count.
+func (f *Frozen[V]) Size4() int {
+	return f.tbl.Size4()
+}
+
+// Size6 returns the IPv6 prefix count.
+func (f *Frozen[V]) Size6() int {
+	return f.tbl.Size6()
+}
+
+// Stats returns the table statistics, see [Table.Stats].
+func (f *Frozen[V]) Stats() Stats {
+	return f.tbl.Stats()
+}
+
+// MemoryFootprint estimates the heap bytes used by the frozen table,
+// see [Table.MemoryFootprint].
+func (f *Frozen[V]) MemoryFootprint() int64 {
+	return f.tbl.MemoryFootprint()
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestFreeze(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, workLoadN())
+
+	tbl := new(Table[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+	for _, pfx := range pfxs[:len(pfxs)/2] {
+		tbl.Delete(pfx)
+	}
+
+	f := tbl.Freeze()
+
+	if f.Size() != tbl.Size() || f.Size4() != tbl.Size4() || f.Size6() != tbl.Size6() {
+		t.Fatalf("Freeze, got size: %d, want: %d", f.Size(), tbl.Size())
+	}
+	if f.MemoryFootprint() > tbl.MemoryFootprint() {
+		t.Errorf("Freeze, not compacted, got: %d bytes, source: %d bytes", f.MemoryFootprint(), tbl.MemoryFootprint())
+	}
+
+	for range workLoadN() {
+		ip := random.IP(prng)
+		wantVal, wantOK := tbl.Lookup(ip)
+		if val, ok := f.Lookup(ip); val != wantVal || ok != wantOK {
+			t.Fatalf("Lookup(%s) = (%d, %v), want (%d, %v)", ip, val, ok, wantVal, wantOK)
+		}
+	}
+
+	// later changes of the source are not visible
+	tbl.Insert(mpp("0.0.0.0/0"), -1)
+	if _, ok := f.Get(mpp("0.0.0.0/0")); ok {
+		t.Fatal("Freeze, change of the source table visible")
+	}
+
+	// thaw is a mutable copy
+	thawed := f.Thaw()
+	thawed.Insert(mpp("0.0.0.0/0"), -2)
+	if _, ok := f.Get(mpp("0.0.0.0/0")); ok {
+		t.Fatal("Thaw, change of the thawed table visible")
+	}
+
+	// nil table
+	var nilTbl *Table[int]
+	if f := nilTbl.Freeze(); f.Size() != 0 || f.Contains(mpa("10.0.0.1")) {
+		t.Fatal("Freeze, nil table, expected empty frozen table")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+)
+
+// GobEncode implements the [gob.GobEncoder] interface, e.g. to embed
+// tables in larger gob-persisted structures or to send them over net/rpc.
+// The entries are written in natural CIDR sort order, the values are
+// encoded by encoding/gob and must be gob encodable.
+func (t *Table[V]) GobEncode() ([]byte, error) {
+	var entries []PrefixValue[V]
+	if t != nil {
+		entries = make([]PrefixValue[V], 0, t.Size())
+	}
+	for pfx, val := range t.AllSorted() {
+		entries = append(entries, PrefixValue[V]{Prefix: pfx, Value: val})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entries); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements the [gob.GobDecoder] interface,
+// the content of the table is replaced. On error the table is left unchanged.
+func (t *Table[V]) GobDecode(data []byte) error {
+	if t == nil {
+		return errors.New("bart: GobDecode on nil table")
+	}
+
+	var entries []PrefixValue[V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entries); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if !e.Prefix.IsValid() {
+			return errors.New("bart: GobDecode, invalid prefix")
+		}
+	}
+
+	t.Clear()
+	for _, e := range entries {
+		t.Insert(e.Prefix, e.Value)
+	}
+	return nil
+}
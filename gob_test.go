@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestGob(t *testing.T) {
+	t.Parallel()
+
+	type route struct {
+		NextHop string
+		Metric  int
+	}
+
+	// a table embedded in a larger structure
+	type snapshot struct {
+		Name  string
+		Table *Table[route]
+	}
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(Table[route])
+	for i, pfx := range random.RealWorldPrefixes(prng, workLoadN()) {
+		tbl.Insert(pfx, route{NextHop: pfx.Addr().String(), Metric: i})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snapshot{Name: "rib", Table: tbl}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got snapshot
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "rib" || !got.Table.Equal(tbl) {
+		t.Fatal("gob round trip, tables differ")
+	}
+
+	if err := got.Table.GobDecode([]byte("garbage")); err == nil {
+		t.Error("GobDecode of garbage, expected error")
+	}
+	if !got.Table.Equal(tbl) {
+		t.Error("failed GobDecode modified the table")
+	}
+
+	var nilTbl *Table[route]
+	if data, err := nilTbl.GobEncode(); err != nil || new(Table[route]).GobDecode(data) != nil {
+		t.Errorf("GobEncode of nil table, err: %v", err)
+	}
+	if err := nilTbl.GobDecode(nil); err == nil {
+		t.Error("GobDecode on nil table, expected error")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"net/netip"
+)
+
+// Input validation errors of [Hardened], wrapped with the offending input.
+var (
+	ErrNilTable      = errors.New("bart: nil table")
+	ErrInvalidAddr   = errors.New("bart: invalid address")
+	ErrInvalidPrefix = errors.New("bart: invalid prefix")
+	ErrNotMasked     = errors.New("bart: prefix is not masked")
+	ErrZone          = errors.New("bart: address has a zone")
+	ErrMappedAddr    = errors.New("bart: IPv4-mapped IPv6 address")
+)
+
+// Hardened is a routing table for untrusted input, e.g. in servers
+// feeding client data straight into the table.
+//
+// Where [Table] silently ignores invalid input, canonicalizes prefixes
+// or panics on a nil receiver, all entry points of Hardened validate the
+// input defensively and return documented errors instead:
+//
+//   - [ErrNilTable] for a nil receiver
+//   - [ErrInvalidAddr] and [ErrInvalidPrefix] for zero values
+//   - [ErrNotMasked] for prefixes with host bits set
+//   - [ErrZone] for addresses with an IPv6 zone
+//   - [ErrMappedAddr] for IPv4-mapped IPv6 addresses, their
+//     address family is ambiguous
+//
+// The zero value is ready to use. The same concurrency rules apply as for [Table].
+type Hardened[V any] struct {
+	tbl Table[V]
+}
+
+// checkAddr validates the address.
+func checkAddr(ip netip.Addr) error {
+	switch {
+	case !ip.IsValid():
+		return ErrInvalidAddr
+	case ip.Zone() != "":
+		return fmt.Errorf("%w: %s", ErrZone, ip)
+	case ip.Is4In6():
+		return fmt.Errorf("%w: %s", ErrMappedAddr, ip)
+	}
+	return nil
+}
+
+// checkPrefix validates the prefix.
+func checkPrefix(pfx netip.Prefix) error {
+	switch {
+	case !pfx.IsValid():
+		return ErrInvalidPrefix
+	case pfx.Addr().Is4In6():
+		return fmt.Errorf("%w: %s", ErrMappedAddr, pfx)
+	case pfx != pfx.Masked():
+		return fmt.Errorf("%w: %s", ErrNotMasked, pfx)
+	}
+	return nil
+}
+
+// Insert adds or updates a prefix-value pair.
+func (h *Hardened[V]) Insert(pfx netip.Prefix, val V) error {
+	if h == nil {
+		return ErrNilTable
+	}
+	if err := checkPrefix(pfx); err != nil {
+		return err
+	}
+	h.tbl.Insert(pfx, val)
+	return nil
+}
+
+// Delete removes the exact prefix pfx, it reports whether the prefix existed.
+func (h *Hardened[V]) Delete(pfx netip.Prefix) (exists bool, err error) {
+	if h == nil {
+		return false, ErrNilTable
+	}
+	if err = checkPrefix(pfx); err != nil {
+		return false, err
+	}
+	_, exists = h.tbl.GetAndDelete(pfx)
+	return exists, nil
+}
+
+// Get returns the value of the exact prefix pfx.
+func (h *Hardened[V]) Get(pfx netip.Prefix) (val V, ok bool, err error) {
+	if h == nil {
+		return val, false, ErrNilTable
+	}
+	if err = checkPrefix(pfx); err != nil {
+		return val, false, err
+	}
+	val, ok = h.tbl.Get(pfx)
+	return val, ok, nil
+}
+
+// Contains reports whether any stored prefix covers ip, see [Table.Contains].
+func (h *Hardened[V]) Contains(ip netip.Addr) (bool, error) {
+	if h == nil {
+		return false, ErrNilTable
+	}
+	if err := checkAddr(ip); err != nil {
+		return false, err
+	}
+	return h.tbl.Contains(ip), nil
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (h *Hardened[V]) Lookup(ip netip.Addr) (val V, ok bool, err error) {
+	if h == nil {
+		return val, false, ErrNilTable
+	}
+	if err = checkAddr(ip); err != nil {
+		return val, false, err
+	}
+	val, ok = h.tbl.Lookup(ip)
+	return val, ok, nil
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (h *Hardened[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool, err error) {
+	if h == nil {
+		return val, false, ErrNilTable
+	}
+	if err = checkPrefix(pfx); err != nil {
+		return val, false, err
+	}
+	val, ok = h.tbl.LookupPrefix(pfx)
+	return val, ok, nil
+}
+
+// Size returns the prefix count, zero for a nil receiver.
+func (h *Hardened[V]) Size() int {
+	if h == nil {
+		return 0
+	}
+	return h.tbl.Size()
+}
+
+// All returns an iterator over all prefix–value pairs, see [Table.All].
+// The iterator is empty for a nil receiver.
+func (h *Hardened[V]) All() iter.Seq2[netip.Prefix, V] {
+	if h == nil {
+		return func(func(netip.Prefix, V) bool) {}
+	}
+	return h.tbl.All()
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestHardenedErrors(t *testing.T) {
+	t.Parallel()
+
+	h := new(Hardened[int])
+
+	pfxTests := []struct {
+		pfx  netip.Prefix
+		want error
+	}{
+		{netip.Prefix{}, ErrInvalidPrefix},
+		{netip.PrefixFrom(mpa("10.0.0.1"), 33), ErrInvalidPrefix},
+		{netip.MustParsePrefix("10.0.0.1/8"), ErrNotMasked},
+		{netip.MustParsePrefix("::ffff:10.0.0.0/104"), ErrMappedAddr},
+		{mpp("10.0.0.0/8"), nil},
+	}
+
+	for _, tt := range pfxTests {
+		if err := h.Insert(tt.pfx, 1); !errors.Is(err, tt.want) {
+			t.Errorf("Insert(%s), got err: %v, want: %v", tt.pfx, err, tt.want)
+		}
+		if _, err := h.Delete(tt.pfx); tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("Delete(%s), got err: %v, want: %v", tt.pfx, err, tt.want)
+		}
+		if _, _, err := h.Get(tt.pfx); !errors.Is(err, tt.want) {
+			t.Errorf("Get(%s), got err: %v, want: %v", tt.pfx, err, tt.want)
+		}
+		if _, _, err := h.LookupPrefix(tt.pfx); !errors.Is(err, tt.want) {
+			t.Errorf("LookupPrefix(%s), got err: %v, want: %v", tt.pfx, err, tt.want)
+		}
+	}
+
+	addrTests := []struct {
+		ip   netip.Addr
+		want error
+	}{
+		{netip.Addr{}, ErrInvalidAddr},
+		{mpa("fe80::1").WithZone("eth0"), ErrZone},
+		{mpa("::ffff:10.0.0.1"), ErrMappedAddr},
+		{mpa("10.0.0.1"), nil},
+	}
+
+	for _, tt := range addrTests {
+		if _, err := h.Contains(tt.ip); !errors.Is(err, tt.want) {
+			t.Errorf("Contains(%s), got err: %v, want: %v", tt.ip, err, tt.want)
+		}
+		if _, _, err := h.Lookup(tt.ip); !errors.Is(err, tt.want) {
+			t.Errorf("Lookup(%s), got err: %v, want: %v", tt.ip, err, tt.want)
+		}
+	}
+}
+
+func TestHardenedNil(t *testing.T) {
+	t.Parallel()
+
+	var h *Hardened[int]
+	pfx := mpp("10.0.0.0/8")
+	ip := mpa("10.0.0.1")
+
+	if err := h.Insert(pfx, 1); !errors.Is(err, ErrNilTable) {
+		t.Errorf("Insert, got err: %v", err)
+	}
+	if _, err := h.Delete(pfx); !errors.Is(err, ErrNilTable) {
+		t.Errorf("Delete, got err: %v", err)
+	}
+	if _, _, err := h.Get(pfx); !errors.Is(err, ErrNilTable) {
+		t.Errorf("Get, got err: %v", err)
+	}
+	if _, _, err := h.LookupPrefix(pfx); !errors.Is(err, ErrNilTable) {
+		t.Errorf("LookupPrefix, got err: %v", err)
+	}
+	if _, err := h.Contains(ip); !errors.Is(err, ErrNilTable) {
+		t.Errorf("Contains, got err: %v", err)
+	}
+	if _, _, err := h.Lookup(ip); !errors.Is(err, ErrNilTable) {
+		t.Errorf("Lookup, got err: %v", err)
+	}
+	if h.Size() != 0 {
+		t.Errorf("Size, expected 0")
+	}
+	for range h.All() {
+		t.Errorf("All, expected empty iterator")
+	}
+}
+
+func TestHardened(t *testing.T) {
+	t.Parallel()
+
+	h := new(Hardened[int])
+	_ = h.Insert(mpp("10.0.0.0/8"), 1)
+	_ = h.Insert(mpp("10.1.0.0/16"), 2)
+
+	if val, ok, err := h.Lookup(mpa("10.1.1.1")); err != nil || !ok || val != 2 {
+		t.Errorf("Lookup, got: (%d, %v, %v)", val, ok, err)
+	}
+	if val, ok, err := h.LookupPrefix(mpp("10.2.0.0/16")); err != nil || !ok || val != 1 {
+		t.Errorf("LookupPrefix, got: (%d, %v, %v)", val, ok, err)
+	}
+	if ok, err := h.Contains(mpa("11.0.0.1")); err != nil || ok {
+		t.Errorf("Contains, got: (%v, %v)", ok, err)
+	}
+	if exists, err := h.Delete(mpp("10.1.0.0/16")); err != nil || !exists {
+		t.Errorf("Delete, got: (%v, %v)", exists, err)
+	}
+	if exists, err := h.Delete(mpp("10.1.0.0/16")); err != nil || exists {
+		t.Errorf("Delete again, got: (%v, %v)", exists, err)
+	}
+
+	n := 0
+	for range h.All() {
+		n++
+	}
+	if n != 1 || h.Size() != 1 {
+		t.Errorf("All: %d, Size: %d, want 1", n, h.Size())
+	}
+}
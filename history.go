@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// History retains past versions of a routing table for time-travel
+// reads, e.g. to answer "what did the table say 5 minutes ago when this
+// packet was dropped".
+//
+// Versions are cheap to retain when the table is maintained with the
+// persistent methods (InsertPersist, DeletePersist, ...), since
+// untouched nodes are shared between all versions.
+//
+// A recorded table must not be modified in-place afterwards,
+// otherwise the history is silently corrupted.
+//
+// The zero value is ready to use, without limits.
+// History is safe for concurrent use.
+type History[V any] struct {
+	mu sync.RWMutex
+
+	maxVersions int
+	maxAge      time.Duration
+
+	// oldest first
+	versions []historyItem[V]
+	next     uint64
+
+	// time source, replaceable in tests
+	now func() time.Time
+}
+
+// historyItem is a recorded version of the table.
+type historyItem[V any] struct {
+	version uint64
+	at      time.Time
+	tbl     *Table[V]
+}
+
+// NewHistory returns a History retaining at most maxVersions versions
+// and, additionally, only versions still valid within the window maxAge.
+// A zero value for maxVersions or maxAge disables the respective limit.
+// The latest version is always retained.
+func NewHistory[V any](maxVersions int, maxAge time.Duration) *History[V] {
+	return &History[V]{
+		maxVersions: maxVersions,
+		maxAge:      maxAge,
+		next:        1,
+		now:         time.Now,
+	}
+}
+
+// Record adds t as the latest version and returns its version number.
+// Version numbers start at 1 and are increasing.
+func (h *History[V]) Record(t *Table[V]) (version uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// zero value, version numbers start at 1
+	if h.next == 0 {
+		h.next = 1
+	}
+	version = h.next
+	h.next++
+
+	now := time.Now()
+	if h.now != nil {
+		now = h.now()
+	}
+	h.versions = append(h.versions, historyItem[V]{version: version, at: now, tbl: t})
+	h.prune(now)
+
+	return version
+}
+
+// prune drops versions exceeding the limits, the latest version is always kept.
+func (h *History[V]) prune(now time.Time) {
+	drop := 0
+
+	if h.maxVersions > 0 && len(h.versions) > h.maxVersions {
+		drop = len(h.versions) - h.maxVersions
+	}
+
+	// a version is still valid within the window as long as
+	// its successor was recorded inside the window
+	if h.maxAge > 0 {
+		cutoff := now.Add(-h.maxAge)
+		for drop < len(h.versions)-1 && h.versions[drop+1].at.Before(cutoff) {
+			drop++
+		}
+	}
+
+	if drop > 0 {
+		clear(h.versions[:drop])
+		h.versions = h.versions[drop:]
+	}
+}
+
+// Latest returns the latest version of the table and its version number.
+// If nothing is recorded yet, nil and 0 is returned.
+func (h *History[V]) Latest() (*Table[V], uint64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.versions) == 0 {
+		return nil, 0
+	}
+	last := h.versions[len(h.versions)-1]
+	return last.tbl, last.version
+}
+
+// At returns the table with the given version number.
+// If the version is unknown or already pruned, nil and false is returned.
+func (h *History[V]) At(version uint64) (*Table[V], bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	i := sort.Search(len(h.versions), func(i int) bool { return h.versions[i].version >= version })
+	if i < len(h.versions) && h.versions[i].version == version {
+		return h.versions[i].tbl, true
+	}
+	return nil, false
+}
+
+// AtTime returns the table as it was at time t, that is the latest version
+// recorded at or before t. If no such version is retained, nil and false
+// is returned.
+func (h *History[V]) AtTime(t time.Time) (*Table[V], bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	// first version recorded after t
+	i := sort.Search(len(h.versions), func(i int) bool { return h.versions[i].at.After(t) })
+	if i == 0 {
+		return nil, false
+	}
+	return h.versions[i-1].tbl, true
+}
+
+// Len returns the number of retained versions.
+func (h *History[V]) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.versions)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryMaxVersions(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistory[int](3, 0)
+	if tbl, v := h.Latest(); tbl != nil || v != 0 {
+		t.Fatalf("Latest on empty history, got: (%v, %d)", tbl, v)
+	}
+
+	tbl := new(Table[int])
+	for i := range 5 {
+		tbl = tbl.InsertPersist(mpp("10.0.0.0/8"), i)
+		if v := h.Record(tbl); v != uint64(i+1) {
+			t.Fatalf("Record, version got: %d, want: %d", v, i+1)
+		}
+	}
+
+	if h.Len() != 3 {
+		t.Fatalf("Len, got: %d, want: 3", h.Len())
+	}
+
+	for v, want := range map[uint64]bool{1: false, 2: false, 3: true, 4: true, 5: true, 6: false} {
+		if _, ok := h.At(v); ok != want {
+			t.Errorf("At(%d), got: %v, want: %v", v, ok, want)
+		}
+	}
+
+	old, _ := h.At(3)
+	if val, _ := old.Get(mpp("10.0.0.0/8")); val != 2 {
+		t.Errorf("At(3).Get, got: %d, want: 2", val)
+	}
+
+	latest, v := h.Latest()
+	if val, _ := latest.Get(mpp("10.0.0.0/8")); v != 5 || val != 4 {
+		t.Errorf("Latest, got: (%d, %d), want: (4, 5)", val, v)
+	}
+}
+
+func TestHistoryAtTime(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	h := NewHistory[int](0, 10*time.Minute)
+	h.now = func() time.Time { return now }
+
+	tbl := new(Table[int])
+	for i := range 6 {
+		// a version every 5 minutes
+		now = start.Add(time.Duration(i) * 5 * time.Minute)
+		tbl = tbl.InsertPersist(mpp("10.0.0.0/8"), i)
+		h.Record(tbl)
+	}
+
+	// now is start+25m, window starts at start+15m,
+	// the version recorded at start+15m must still be retained
+	if _, ok := h.AtTime(start.Add(15 * time.Minute)); !ok {
+		t.Fatal("AtTime(start+15m), expected version")
+	}
+
+	// version from start+5m is valid until start+10m, pruned
+	if _, ok := h.AtTime(start.Add(7 * time.Minute)); ok {
+		t.Fatal("AtTime(start+7m), expected pruned version")
+	}
+
+	got, ok := h.AtTime(start.Add(22 * time.Minute))
+	if !ok {
+		t.Fatal("AtTime(start+22m), expected version")
+	}
+	if val, _ := got.Get(mpp("10.0.0.0/8")); val != 4 {
+		t.Errorf("AtTime(start+22m).Get, got: %d, want: 4", val)
+	}
+
+	// the version from start+25m was valid until start+60m,
+	// all older versions are out of the window
+	now = start.Add(time.Hour)
+	h.Record(tbl)
+	if h.Len() != 2 {
+		t.Errorf("Len, got: %d, want: 2", h.Len())
+	}
+}
+
+func TestHistoryZeroValue(t *testing.T) {
+	t.Parallel()
+
+	var h History[int]
+
+	tbl := new(Table[int]).InsertPersist(mpp("10.0.0.0/8"), 1)
+	if v := h.Record(tbl); v != 1 {
+		t.Fatalf("Record on zero value, version got: %d, want: 1", v)
+	}
+	if v := h.Record(tbl.InsertPersist(mpp("11.0.0.0/8"), 2)); v != 2 {
+		t.Fatalf("Record on zero value, version got: %d, want: 2", v)
+	}
+
+	// no limits
+	if h.Len() != 2 {
+		t.Errorf("Len, got: %d, want: 2", h.Len())
+	}
+	if got, ok := h.At(1); !ok || got != tbl {
+		t.Errorf("At(1), got: (%v, %v)", got, ok)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// idVal is the payload of IDTable.
+type idVal[V any] struct {
+	id  uint32
+	val V
+}
+
+// IDTable is a routing table that assigns every stored prefix a stable,
+// small integer ID, retrievable at lookup time. Data planes can reference
+// routes by index, e.g. in arrays or BPF maps, instead of hashing prefixes.
+//
+// The ID of a prefix is stable until the prefix is deleted. With ID reuse
+// enabled, the IDs of deleted prefixes are handed out again and the IDs
+// stay dense in the range 0..Size()-1 plus the free IDs, otherwise the
+// IDs are monotonic and never reused.
+//
+// The zero value is ready to use, with monotonic IDs.
+// The same concurrency rules apply as for [Table].
+type IDTable[V any] struct {
+	tbl Table[idVal[V]]
+
+	reuse    bool
+	next     uint32
+	free     []uint32
+	prefixes map[uint32]netip.Prefix
+}
+
+// NewIDTable returns an empty table, reuse enables the reuse
+// of the IDs of deleted prefixes.
+func NewIDTable[V any](reuse bool) *IDTable[V] {
+	return &IDTable[V]{reuse: reuse}
+}
+
+// allocID returns a free ID.
+func (t *IDTable[V]) allocID() uint32 {
+	if n := len(t.free); n > 0 {
+		id := t.free[n-1]
+		t.free = t.free[:n-1]
+		return id
+	}
+
+	id := t.next
+	t.next++
+	return id
+}
+
+// Insert adds or updates a prefix-value pair and returns the ID of the prefix.
+// Updates keep the ID of the prefix. For an invalid prefix nothing is
+// stored and ok is false, 0 is a valid ID.
+func (t *IDTable[V]) Insert(pfx netip.Prefix, val V) (id uint32, ok bool) {
+	if !pfx.IsValid() {
+		return 0, false
+	}
+	pfx = pfx.Masked()
+
+	t.tbl.Modify(pfx, func(old idVal[V], exists bool) (_ idVal[V], del bool) {
+		if exists {
+			id = old.id
+		} else {
+			id = t.allocID()
+			if t.prefixes == nil {
+				t.prefixes = make(map[uint32]netip.Prefix)
+			}
+			t.prefixes[id] = pfx
+		}
+		return idVal[V]{id: id, val: val}, false
+	})
+
+	return id, true
+}
+
+// Delete removes the exact prefix pfx and releases its ID.
+func (t *IDTable[V]) Delete(pfx netip.Prefix) {
+	old, exists := t.tbl.GetAndDelete(pfx)
+	if !exists {
+		return
+	}
+
+	delete(t.prefixes, old.id)
+	if t.reuse {
+		t.free = append(t.free, old.id)
+	}
+}
+
+// Get returns the ID and the value of the exact prefix pfx.
+func (t *IDTable[V]) Get(pfx netip.Prefix) (id uint32, val V, ok bool) {
+	iv, ok := t.tbl.Get(pfx)
+	return iv.id, iv.val, ok
+}
+
+// Lookup performs a longest prefix match for ip and returns
+// the ID and the value of the matching prefix, see [Table.Lookup].
+func (t *IDTable[V]) Lookup(ip netip.Addr) (id uint32, val V, ok bool) {
+	iv, ok := t.tbl.Lookup(ip)
+	return iv.id, iv.val, ok
+}
+
+// LookupPrefix performs a longest prefix match for pfx and returns
+// the ID and the value of the matching prefix, see [Table.LookupPrefix].
+func (t *IDTable[V]) LookupPrefix(pfx netip.Prefix) (id uint32, val V, ok bool) {
+	iv, ok := t.tbl.LookupPrefix(pfx)
+	return iv.id, iv.val, ok
+}
+
+// Prefix returns the prefix with the given ID.
+func (t *IDTable[V]) Prefix(id uint32) (netip.Prefix, bool) {
+	pfx, ok := t.prefixes[id]
+	return pfx, ok
+}
+
+// Size returns the prefix count.
+func (t *IDTable[V]) Size() int {
+	return t.tbl.Size()
+}
+
+// All returns an iterator over all prefixes with their IDs, see [Table.All].
+func (t *IDTable[V]) All() iter.Seq2[netip.Prefix, uint32] {
+	return func(yield func(netip.Prefix, uint32) bool) {
+		for pfx, iv := range t.tbl.All() {
+			if !yield(pfx, iv.id) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIDTableMonotonic(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(IDTable[string])
+
+	if id, ok := tbl.Insert(mpp("10.0.0.0/8"), "a"); !ok || id != 0 {
+		t.Fatalf("Insert, got id: %d, want: 0", id)
+	}
+	if id, ok := tbl.Insert(mpp("10.1.0.0/16"), "b"); !ok || id != 1 {
+		t.Fatalf("Insert, got id: %d, want: 1", id)
+	}
+	// update keeps the ID
+	if id, ok := tbl.Insert(mpp("10.0.0.0/8"), "c"); !ok || id != 0 {
+		t.Fatalf("Insert update, got id: %d, want: 0", id)
+	}
+
+	if id, val, ok := tbl.Lookup(mpa("10.1.2.3")); !ok || id != 1 || val != "b" {
+		t.Errorf("Lookup, got: (%d, %q, %v)", id, val, ok)
+	}
+	if id, val, ok := tbl.LookupPrefix(mpp("10.2.0.0/16")); !ok || id != 0 || val != "c" {
+		t.Errorf("LookupPrefix, got: (%d, %q, %v)", id, val, ok)
+	}
+	if id, val, ok := tbl.Get(mpp("10.1.0.0/16")); !ok || id != 1 || val != "b" {
+		t.Errorf("Get, got: (%d, %q, %v)", id, val, ok)
+	}
+
+	tbl.Delete(mpp("10.0.0.0/8"))
+	tbl.Delete(mpp("10.0.0.0/8"))
+
+	if _, ok := tbl.Prefix(0); ok {
+		t.Error("Prefix of deleted ID, expected !ok")
+	}
+	if pfx, ok := tbl.Prefix(1); !ok || pfx != mpp("10.1.0.0/16") {
+		t.Errorf("Prefix(1), got: (%s, %v)", pfx, ok)
+	}
+
+	// monotonic, no reuse
+	if id, ok := tbl.Insert(mpp("2001:db8::/32"), "d"); !ok || id != 2 {
+		t.Errorf("Insert after delete, got id: %d, want: 2", id)
+	}
+
+	// invalid prefix, no ID allocated
+	if _, ok := tbl.Insert(netip.Prefix{}, "e"); ok {
+		t.Error("Insert of invalid prefix, expected !ok")
+	}
+
+	if tbl.Size() != 2 {
+		t.Errorf("Size, got: %d, want: 2", tbl.Size())
+	}
+
+	ids := map[uint32]bool{}
+	for pfx, id := range tbl.All() {
+		if got, _ := tbl.Prefix(id); got != pfx {
+			t.Errorf("All, id %d maps to %s, want: %s", id, got, pfx)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("All, got ids: %v", ids)
+	}
+}
+
+func TestIDTableReuse(t *testing.T) {
+	t.Parallel()
+
+	tbl := NewIDTable[int](true)
+
+	for i, s := range []string{"10.0.0.0/8", "11.0.0.0/8", "12.0.0.0/8"} {
+		tbl.Insert(mpp(s), i)
+	}
+
+	tbl.Delete(mpp("11.0.0.0/8"))
+
+	if id, ok := tbl.Insert(mpp("13.0.0.0/8"), 3); !ok || id != 1 {
+		t.Errorf("Insert with reuse, got id: %d, want: 1", id)
+	}
+	if id, ok := tbl.Insert(mpp("14.0.0.0/8"), 4); !ok || id != 3 {
+		t.Errorf("Insert with reuse, got id: %d, want: 3", id)
+	}
+	if pfx, _ := tbl.Prefix(1); pfx != mpp("13.0.0.0/8") {
+		t.Errorf("Prefix(1), got: %s", pfx)
+	}
+}
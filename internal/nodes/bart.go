@@ -5,7 +5,9 @@ package nodes
 
 import (
 	"iter"
+	"net/netip"
 
+	"github.com/admpub/bart/internal/bitset"
 	"github.com/admpub/bart/internal/lpm"
 	"github.com/admpub/bart/internal/sparse"
 	"github.com/admpub/bart/internal/value"
@@ -262,3 +264,239 @@ func (n *BartNode[V]) CloneRec(cloneFn value.CloneFunc[V]) *BartNode[V] {
 
 	return c
 }
+
+// MapRec performs a recursive structural copy of the node and all its
+// descendants into a node with a different value type, converting each
+// stored value with fn.
+//
+// The bitsets of prefixes and children are copied verbatim, no
+// insertions are needed to rebuild the trie.
+func MapRec[V, W any](n *BartNode[V], path StridePath, depth int, is4 bool, fn func(netip.Prefix, V) W) *BartNode[W] {
+	c := new(BartNode[W])
+	if n == nil || n.IsEmpty() {
+		return c
+	}
+
+	var buf [256]uint8
+
+	// the Items are stored in rank order of the bitset
+	c.Prefixes.BitSet256 = n.Prefixes.BitSet256
+	c.Prefixes.Items = make([]W, len(n.Prefixes.Items))
+	for i, idx := range n.Prefixes.AsSlice(&buf) {
+		cidr := CidrFromPath(path, depth, is4, idx)
+		c.Prefixes.Items[i] = fn(cidr, n.Prefixes.Items[i])
+	}
+
+	c.Children.BitSet256 = n.Children.BitSet256
+	c.Children.Items = make([]any, len(n.Children.Items))
+	for i, addr := range n.Children.AsSlice(&buf) {
+		switch kid := n.Children.Items[i].(type) {
+		case *BartNode[V]:
+			path[depth] = addr
+			c.Children.Items[i] = MapRec(kid, path, depth+1, is4, fn)
+		case *LeafNode[V]:
+			c.Children.Items[i] = NewLeafNode(kid.Prefix, fn(kid.Prefix, kid.Value))
+		case *FringeNode[V]:
+			fringePfx := CidrForFringe(path[:], depth, is4, addr)
+			c.Children.Items[i] = NewFringeNode(fn(fringePfx, kid.Value))
+		default:
+			panic("logic error, wrong node type")
+		}
+	}
+
+	return c
+}
+
+// DiffRec walks the nodes a and b of two table versions in lockstep and
+// calls fn for every prefix stored in only one of them or with differing
+// values, compared by value.Equal. The flags inA and inB report where
+// the prefix is stored.
+//
+// Identical subtrees, e.g. shared between persistent versions,
+// are detected by pointer equality and skipped. The order of the calls
+// is unspecified.
+func DiffRec[V any](a, b *BartNode[V], path StridePath, depth int, is4 bool,
+	fn func(pfx netip.Prefix, valA, valB V, inA, inB bool),
+) {
+	if a == b {
+		return
+	}
+	if a == nil {
+		a = new(BartNode[V])
+	}
+	if b == nil {
+		b = new(BartNode[V])
+	}
+
+	var buf [256]uint8
+
+	idxs := a.Prefixes.BitSet256
+	idxs.Union(&b.Prefixes.BitSet256)
+
+	for _, idx := range idxs.AsSlice(&buf) {
+		valA, inA := a.Prefixes.Get(idx)
+		valB, inB := b.Prefixes.Get(idx)
+
+		if inA && inB && value.Equal(valA, valB) {
+			continue
+		}
+		fn(CidrFromPath(path, depth, is4, idx), valA, valB, inA, inB)
+	}
+
+	addrs := a.Children.BitSet256
+	addrs.Union(&b.Children.BitSet256)
+
+	for _, addr := range addrs.AsSlice(&buf) {
+		kidA, _ := a.Children.Get(addr)
+		kidB, _ := b.Children.Get(addr)
+
+		if kidA == kidB {
+			continue
+		}
+
+		// both inner nodes, descend in lockstep
+		nodeA, okA := kidA.(*BartNode[V])
+		nodeB, okB := kidB.(*BartNode[V])
+		if okA && okB {
+			path[depth] = addr
+			DiffRec(nodeA, nodeB, path, depth+1, is4, fn)
+			continue
+		}
+
+		// different node types, compare the few entries by prefix
+		entriesA := make(map[netip.Prefix]V)
+		kidEntries(kidA, path, depth, is4, addr, func(pfx netip.Prefix, val V) {
+			entriesA[pfx] = val
+		})
+
+		kidEntries(kidB, path, depth, is4, addr, func(pfx netip.Prefix, valB V) {
+			valA, inA := entriesA[pfx]
+			delete(entriesA, pfx)
+
+			if inA && value.Equal(valA, valB) {
+				return
+			}
+			fn(pfx, valA, valB, inA, true)
+		})
+
+		var zero V
+		for pfx, valA := range entriesA {
+			fn(pfx, valA, zero, true, false)
+		}
+	}
+}
+
+// ComposeRec walks the nodes ns of several tables in lockstep and
+// calls fn for every prefix of the set expression over ns, evaluated
+// from left to right: a prefix of ns[i] is added with its value, or
+// removed if subtract[i] is set. For duplicate prefixes the value of
+// the rightmost added operand wins. A nil node is treated as empty.
+//
+// No intermediate results are materialized, each prefix is evaluated
+// once for all operands. The order of the calls is unspecified.
+func ComposeRec[V any](ns []*BartNode[V], subtract []bool, path StridePath, depth int, is4 bool,
+	fn func(pfx netip.Prefix, val V),
+) {
+	var buf [256]uint8
+	var idxs, addrs bitset.BitSet256
+
+	for _, n := range ns {
+		if n != nil {
+			idxs.Union(&n.Prefixes.BitSet256)
+			addrs.Union(&n.Children.BitSet256)
+		}
+	}
+
+	for _, idx := range idxs.AsSlice(&buf) {
+		var val V
+		var ok bool
+
+		for i, n := range ns {
+			if n == nil {
+				continue
+			}
+			v, in := n.Prefixes.Get(idx)
+			switch {
+			case !in:
+			case subtract[i]:
+				var zero V
+				val, ok = zero, false
+			default:
+				val, ok = v, true
+			}
+		}
+
+		if ok {
+			fn(CidrFromPath(path, depth, is4, idx), val)
+		}
+	}
+
+	// reused for all children at this level
+	kidNodes := make([]*BartNode[V], len(ns))
+
+	for _, addr := range addrs.AsSlice(&buf) {
+		// all children are inner nodes, descend in lockstep
+		lockstep := true
+
+		for i, n := range ns {
+			kidNodes[i] = nil
+			if n == nil {
+				continue
+			}
+
+			switch kid, _ := n.Children.Get(addr); kid := kid.(type) {
+			case nil:
+			case *BartNode[V]:
+				kidNodes[i] = kid
+			default:
+				lockstep = false
+			}
+		}
+
+		if lockstep {
+			path[depth] = addr
+			ComposeRec(kidNodes, subtract, path, depth+1, is4, fn)
+			continue
+		}
+
+		// different node types, evaluate the few entries by prefix
+		entries := make(map[netip.Prefix]V)
+		for i, n := range ns {
+			if n == nil {
+				continue
+			}
+
+			kid, _ := n.Children.Get(addr)
+			kidEntries(kid, path, depth, is4, addr, func(pfx netip.Prefix, val V) {
+				if subtract[i] {
+					delete(entries, pfx)
+					return
+				}
+				entries[pfx] = val
+			})
+		}
+
+		for pfx, val := range entries {
+			fn(pfx, val)
+		}
+	}
+}
+
+// kidEntries calls fn for all entries of the child kid at addr, kid may be nil.
+func kidEntries[V any](kid any, path StridePath, depth int, is4 bool, addr uint8, fn func(netip.Prefix, V)) {
+	switch kid := kid.(type) {
+	case nil:
+	case *BartNode[V]:
+		path[depth] = addr
+		kid.AllRec(path, depth+1, is4, func(pfx netip.Prefix, val V) bool {
+			fn(pfx, val)
+			return true
+		})
+	case *LeafNode[V]:
+		fn(kid.Prefix, kid.Value)
+	case *FringeNode[V]:
+		fn(CidrForFringe(path[:], depth, is4, addr), kid.Value)
+	default:
+		panic("logic error, wrong node type")
+	}
+}
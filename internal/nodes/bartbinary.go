@@ -0,0 +1,225 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package nodes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/admpub/bart/internal/bitset"
+)
+
+// child kinds in the structural binary encoding
+const (
+	binKindNode byte = iota
+	binKindLeaf
+	binKindFringe
+)
+
+// ErrCorrupt is returned for malformed structural binary encodings.
+var ErrCorrupt = errors.New("corrupt trie encoding")
+
+// AppendBinaryRec appends the structural binary encoding of the node and
+// all its descendants to buf. The bitsets are written verbatim, followed
+// by the packed items, no prefixes are written for the prefixes and
+// fringes, they are implied by the trie structure.
+//
+//	node:   prefixes bitset [32]byte, values, children bitset [32]byte, kids
+//	value:  uvarint length, encoded value
+//	kid:    kind byte, then node | leaf: addr, bits, value | fringe: value
+func (n *BartNode[V]) AppendBinaryRec(buf []byte, encode func(V) ([]byte, error)) ([]byte, error) {
+	var err error
+
+	buf = appendBitSet(buf, &n.Prefixes.BitSet256)
+	for _, val := range n.Prefixes.Items {
+		if buf, err = appendBinaryValue(buf, val, encode); err != nil {
+			return nil, err
+		}
+	}
+
+	buf = appendBitSet(buf, &n.Children.BitSet256)
+	for _, anyKid := range n.Children.Items {
+		switch kid := anyKid.(type) {
+		case *BartNode[V]:
+			buf = append(buf, binKindNode)
+			if buf, err = kid.AppendBinaryRec(buf, encode); err != nil {
+				return nil, err
+			}
+		case *LeafNode[V]:
+			buf = append(buf, binKindLeaf)
+			buf = append(buf, kid.Prefix.Addr().AsSlice()...)
+			buf = append(buf, byte(kid.Prefix.Bits()))
+			if buf, err = appendBinaryValue(buf, kid.Value, encode); err != nil {
+				return nil, err
+			}
+		case *FringeNode[V]:
+			buf = append(buf, binKindFringe)
+			if buf, err = appendBinaryValue(buf, kid.Value, encode); err != nil {
+				return nil, err
+			}
+		default:
+			panic("logic error, wrong node type")
+		}
+	}
+
+	return buf, nil
+}
+
+// appendBitSet appends the bitset in little endian byte order.
+func appendBitSet(buf []byte, bs *bitset.BitSet256) []byte {
+	for _, word := range bs {
+		buf = binary.LittleEndian.AppendUint64(buf, word)
+	}
+	return buf
+}
+
+// appendBinaryValue appends the length prefixed encoded value.
+func appendBinaryValue[V any](buf []byte, val V, encode func(V) ([]byte, error)) ([]byte, error) {
+	raw, err := encode(val)
+	if err != nil {
+		return nil, err
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(raw)))
+	return append(buf, raw...), nil
+}
+
+// BinaryDecoder decodes the structural binary encoding of
+// [BartNode.AppendBinaryRec].
+type BinaryDecoder[V any] struct {
+	Data   []byte
+	Decode func(raw []byte) (V, error)
+
+	// number of decoded prefixes
+	Count int
+}
+
+// DecodeRec decodes a node and all its descendants into n, the node is
+// at depth and reached by path. The structure is validated while decoding,
+// leaves must be canonical prefixes placed at the slot of their path,
+// as checked by [BartNode.ValidateRec].
+func (d *BinaryDecoder[V]) DecodeRec(n *BartNode[V], path StridePath, depth int, is4 bool) error {
+	maxDepth := MaxTreeDepth
+	if is4 {
+		maxDepth = 4
+	}
+	if depth >= maxDepth {
+		return fmt.Errorf("%w: trie too deep", ErrCorrupt)
+	}
+
+	bs, err := d.bitSet()
+	if err != nil {
+		return err
+	}
+	if bs.Test(0) {
+		return fmt.Errorf("%w: invalid prefix index 0", ErrCorrupt)
+	}
+
+	n.Prefixes.BitSet256 = bs
+	n.Prefixes.Items = make([]V, bs.Size())
+	for i := range n.Prefixes.Items {
+		if n.Prefixes.Items[i], err = d.value(); err != nil {
+			return err
+		}
+	}
+	d.Count += len(n.Prefixes.Items)
+
+	if bs, err = d.bitSet(); err != nil {
+		return err
+	}
+
+	var buf [256]uint8
+	addrs := bs.AsSlice(&buf)
+
+	n.Children.BitSet256 = bs
+	n.Children.Items = make([]any, len(addrs))
+	for i, addr := range addrs {
+		if len(d.Data) == 0 {
+			return fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+		}
+		kind := d.Data[0]
+		d.Data = d.Data[1:]
+
+		switch kind {
+		case binKindNode:
+			kid := new(BartNode[V])
+			path[depth] = addr
+			if err = d.DecodeRec(kid, path, depth+1, is4); err != nil {
+				return err
+			}
+			if kid.IsEmpty() {
+				return fmt.Errorf("%w: empty node", ErrCorrupt)
+			}
+			n.Children.Items[i] = kid
+		case binKindLeaf:
+			pfx, err := d.prefix(is4)
+			if err != nil {
+				return err
+			}
+			if err = validateLeaf(pfx, path, depth, is4, addr); err != nil {
+				return fmt.Errorf("%w: %w", ErrCorrupt, err)
+			}
+			val, err := d.value()
+			if err != nil {
+				return err
+			}
+			n.Children.Items[i] = NewLeafNode(pfx, val)
+			d.Count++
+		case binKindFringe:
+			val, err := d.value()
+			if err != nil {
+				return err
+			}
+			n.Children.Items[i] = NewFringeNode(val)
+			d.Count++
+		default:
+			return fmt.Errorf("%w: invalid node kind %d", ErrCorrupt, kind)
+		}
+	}
+
+	return nil
+}
+
+// bitSet decodes a bitset.
+func (d *BinaryDecoder[V]) bitSet() (bs bitset.BitSet256, err error) {
+	if len(d.Data) < 32 {
+		return bs, fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+	}
+	for i := range bs {
+		bs[i] = binary.LittleEndian.Uint64(d.Data[8*i:])
+	}
+	d.Data = d.Data[32:]
+	return bs, nil
+}
+
+// value decodes a length prefixed value.
+func (d *BinaryDecoder[V]) value() (val V, err error) {
+	valLen, n := binary.Uvarint(d.Data)
+	if n <= 0 || uint64(len(d.Data)-n) < valLen {
+		return val, fmt.Errorf("%w: invalid value length", ErrCorrupt)
+	}
+
+	raw := d.Data[n : n+int(valLen)]
+	d.Data = d.Data[n+int(valLen):]
+
+	return d.Decode(raw)
+}
+
+// prefix decodes the prefix of a leaf.
+func (d *BinaryDecoder[V]) prefix(is4 bool) (pfx netip.Prefix, err error) {
+	addrLen := 16
+	if is4 {
+		addrLen = 4
+	}
+	if len(d.Data) < addrLen+1 {
+		return pfx, fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+	}
+
+	addr, _ := netip.AddrFromSlice(d.Data[:addrLen])
+	pfx = netip.PrefixFrom(addr, int(d.Data[addrLen]))
+	d.Data = d.Data[addrLen+1:]
+
+	return pfx, nil
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/admpub/bart/internal/allot"
 	"github.com/admpub/bart/internal/art"
+	"github.com/admpub/bart/internal/bitset"
 	"github.com/admpub/bart/internal/value"
 )
 
@@ -108,6 +109,93 @@ func (n *LiteNode[V]) Insert(pfx netip.Prefix, val V, depth int) (exists bool) {
 	panic("unreachable")
 }
 
+// InsertBulk builds the subtrie of the empty node n at depth from the
+// canonical prefixes pfxs and their values vals in one pass, e.g. for
+// loading full tables. All prefixes must share the path of n, for
+// duplicate prefixes the last value wins. It returns the number of
+// distinct prefixes.
+//
+// The prefixes are distributed per octet with a stable radix pass, the
+// prefixes and children of every node are inserted in ascending order,
+// no slice elements are shifted as with repeated inserts.
+//
+// The buffers bufP and bufV must have the same length as pfxs, all four
+// slices are overwritten.
+func (n *LiteNode[V]) InsertBulk(pfxs []netip.Prefix, vals []V, bufP []netip.Prefix, bufV []V, depth int) (size int) {
+	// prefixes stored in this node, by index
+	var ownSet bitset.BitSet256
+	var ownPos [256]int
+
+	// prefixes below the children, by octet
+	var counts [256]int
+
+	for i, pfx := range pfxs {
+		octet := octetAt(pfx.Addr(), depth)
+		if lastOctetPlusOne, lastBits := LastOctetPlusOneAndLastBits(pfx); depth == lastOctetPlusOne {
+			idx := art.PfxToIdx(octet, lastBits)
+			ownSet.Set(idx)
+			ownPos[idx] = i
+			continue
+		}
+		counts[octet]++
+	}
+
+	var buf [256]uint8
+	for _, idx := range ownSet.AsSlice(&buf) {
+		n.InsertPrefix(idx, vals[ownPos[idx]])
+	}
+	size = ownSet.Size()
+
+	// bucket start positions
+	var starts, next [256]int
+	sum := 0
+	for octet, cnt := range counts {
+		starts[octet] = sum
+		sum += cnt
+	}
+	next = starts
+
+	// stable distribution into the buffers
+	for i, pfx := range pfxs {
+		lastOctetPlusOne, _ := LastOctetPlusOneAndLastBits(pfx)
+		if depth == lastOctetPlusOne {
+			continue
+		}
+		octet := octetAt(pfx.Addr(), depth)
+		bufP[next[octet]] = pfx
+		bufV[next[octet]] = vals[i]
+		next[octet]++
+	}
+
+	for octet, cnt := range counts {
+		if cnt == 0 {
+			continue
+		}
+
+		lo, hi := starts[octet], starts[octet]+cnt
+		kidPfxs, kidVals := bufP[lo:hi], bufV[lo:hi]
+
+		// a single prefix, maybe duplicated, is path compressed
+		if last := cnt - 1; allEqual(kidPfxs) {
+			pfx := kidPfxs[last]
+			if IsFringe(depth, pfx) {
+				n.InsertChild(uint8(octet), NewFringeNode(kidVals[last]))
+			} else {
+				n.InsertChild(uint8(octet), NewLeafNode(pfx, kidVals[last]))
+			}
+			size++
+			continue
+		}
+
+		// the consumed input is the buffer for the next level
+		kid := new(LiteNode[V])
+		size += kid.InsertBulk(kidPfxs, kidVals, pfxs[lo:hi], vals[lo:hi], depth+1)
+		n.InsertChild(uint8(octet), kid)
+	}
+
+	return size
+}
+
 // InsertPersist is similar to insert but the receiver isn't modified.
 // Assumes the caller has pre-cloned the root (COW). It clones the
 // internal nodes along the descent path before mutating them.
@@ -199,6 +287,62 @@ func (n *LiteNode[V]) InsertPersist(cloneFn value.CloneFunc[V], pfx netip.Prefix
 	panic("unreachable")
 }
 
+// CompactRec compacts the subtrie of n at depth bottom-up: empty inner
+// nodes are removed, inner nodes with a single prefix, leaf or fringe
+// are collapsed into a path-compressed leaf or fringe in n, and the
+// sparse slices are right-sized. It returns the number of removed nodes.
+//
+// Insert and Delete keep the trie compressed, but slices keep their
+// capacity after deletes.
+func (n *LiteNode[V]) CompactRec(path StridePath, depth int, is4 bool) (removed int) {
+	var buf [256]uint8
+	for _, addr := range n.Children.AsSlice(&buf) {
+		kid, ok := n.MustGetChild(addr).(*LiteNode[V])
+		if !ok {
+			continue
+		}
+
+		path[depth] = addr
+		removed += kid.CompactRec(path, depth+1, is4)
+
+		pfxCount, childCount := kid.PrefixCount(), kid.ChildCount()
+		switch {
+		case pfxCount+childCount == 0:
+			n.DeleteChild(addr)
+			removed++
+
+		case pfxCount == 1 && childCount == 0:
+			idx, _ := kid.Prefixes.FirstSet()
+			pfx := CidrFromPath(path, depth+1, is4, idx)
+
+			n.DeleteChild(addr)
+			n.Insert(pfx, kid.MustGetPrefix(idx), depth)
+			removed++
+
+		case pfxCount == 0 && childCount == 1:
+			singleAddr, _ := kid.Children.FirstSet()
+
+			switch grandKid := kid.MustGetChild(singleAddr).(type) {
+			case *LeafNode[V]:
+				n.DeleteChild(addr)
+				n.Insert(grandKid.Prefix, grandKid.Value, depth)
+				removed++
+
+			case *FringeNode[V]:
+				fringePfx := CidrForFringe(path[:], depth+1, is4, singleAddr)
+
+				n.DeleteChild(addr)
+				n.Insert(fringePfx, grandKid.Value, depth)
+				removed++
+			}
+		}
+	}
+
+	n.shrinkItems()
+
+	return removed
+}
+
 // PurgeAndCompress performs bottom-up compression of the trie.
 //
 // The function unwinds the provided stack of parent nodes, checking each level
@@ -1067,6 +1211,14 @@ func (n *LiteNode[V]) StatsRec() (s StatsT) {
 // starting from this node to the provided writer. The output shows the
 // routing table structure in human-readable format for debugging and analysis.
 func (n *LiteNode[V]) FprintRec(w io.Writer, parent TrieItem[V], pad string) error {
+	return n.FprintFuncRec(w, parent, pad, nil)
+}
+
+// FprintFuncRec is the recursive worker of FprintRec, the values are
+// formatted by format, or with the default format if format is nil.
+func (n *LiteNode[V]) FprintFuncRec(w io.Writer, parent TrieItem[V], pad string,
+	format func(netip.Prefix, V) string,
+) error {
 	// recursion stop condition
 	if n == nil || n.IsEmpty() {
 		return nil
@@ -1096,10 +1248,12 @@ func (n *LiteNode[V]) FprintRec(w io.Writer, parent TrieItem[V], pad string) err
 		}
 
 		var err error
-		// val is the empty struct, don't print it
-		if printValues {
+		switch {
+		case format != nil:
+			_, err = fmt.Fprintf(w, "%s%s (%s)\n", pad+glyph, item.Cidr, format(item.Cidr, item.Val))
+		case printValues:
 			_, err = fmt.Fprintf(w, "%s%s (%v)\n", pad+glyph, item.Cidr, item.Val)
-		} else {
+		default:
 			// skip printing values if V is zero-sized
 			_, err = fmt.Fprintf(w, "%s%s\n", pad+glyph, item.Cidr)
 		}
@@ -1110,7 +1264,7 @@ func (n *LiteNode[V]) FprintRec(w io.Writer, parent TrieItem[V], pad string) err
 
 		// rec-descent with this item as parent
 		nextNode, _ := item.Node.(*LiteNode[V])
-		if err = nextNode.FprintRec(w, item, pad+space); err != nil {
+		if err = nextNode.FprintFuncRec(w, item, pad+space, format); err != nil {
 			return err
 		}
 	}
@@ -1590,6 +1744,77 @@ func (n *LiteNode[V]) AllRec(path StridePath, depth int, is4 bool, yield func(ne
 	return true
 }
 
+// OccupancyRec calls yield for this node and recursively for all inner
+// subnodes in depth-first pre-order, with the occupied prefix and child slots.
+//
+// Returns false if yield function requests early termination.
+func (n *LiteNode[V]) OccupancyRec(path StridePath, depth int, is4 bool, yield func(Occupancy) bool) bool {
+	occ := Occupancy{
+		Cidr:  CidrFromPath(path, depth, is4, 1),
+		Depth: depth,
+	}
+
+	var buf [256]uint8
+	for _, idx := range n.Prefixes.AsSlice(&buf) {
+		occ.Prefixes.Set(idx)
+	}
+
+	for _, addr := range n.Children.AsSlice(&buf) {
+		occ.Children.Set(addr)
+		switch n.MustGetChild(addr).(type) {
+		case *LeafNode[V]:
+			occ.Leaves.Set(addr)
+		case *FringeNode[V]:
+			occ.Fringes.Set(addr)
+		}
+	}
+
+	if !yield(occ) {
+		return false
+	}
+
+	for _, addr := range n.Children.AsSlice(&buf) {
+		if kid, ok := n.MustGetChild(addr).(*LiteNode[V]); ok {
+			path[depth] = addr
+			if !kid.OccupancyRec(path, depth+1, is4, yield) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// ResetValuesRec recursively replaces the value of every stored prefix
+// with fn(prefix, oldValue), in place.
+//
+// Only values are rewritten, the trie structure is not modified:
+// no nodes are allocated, moved or removed.
+func (n *LiteNode[V]) ResetValuesRec(path StridePath, depth int, is4 bool, fn func(netip.Prefix, V) V) {
+	var buf [256]uint8
+	for _, idx := range n.Prefixes.AsSlice(&buf) {
+		cidr := CidrFromPath(path, depth, is4, idx)
+		n.InsertPrefix(idx, fn(cidr, n.MustGetPrefix(idx)))
+	}
+
+	for _, addr := range n.Children.AsSlice(&buf) {
+		anyKid := n.MustGetChild(addr)
+		switch kid := anyKid.(type) {
+		case *LiteNode[V]:
+			path[depth] = addr
+			kid.ResetValuesRec(path, depth+1, is4, fn)
+		case *LeafNode[V]:
+			kid.Value = fn(kid.Prefix, kid.Value)
+		case *FringeNode[V]:
+			fringePfx := CidrForFringe(path[:], depth, is4, addr)
+			kid.Value = fn(fringePfx, kid.Value)
+
+		default:
+			panic("logic error, wrong node type")
+		}
+	}
+}
+
 // AllRecSorted recursively traverses the trie in prefix-sorted order and applies
 // the given yield function to each stored prefix and value.
 //
@@ -2046,6 +2271,139 @@ func (n *LiteNode[V]) Subnets(pfx netip.Prefix, yield func(netip.Prefix, V) bool
 	}
 }
 
+// CloneSubtree returns a new trie with all entries of n covered by pfx
+// and their count.
+//
+// Only the node owning pfx is visited entry by entry, its covered prefixes
+// and child slots are copied, covered subnodes are cloned with CloneRec.
+// The spine from the root down to this node is copied node by node and
+// path-compressed afterwards, no prefix is reinserted from the root.
+func (n *LiteNode[V]) CloneSubtree(cloneFn value.CloneFunc[V], pfx netip.Prefix) (root *LiteNode[V], size int) {
+	return n.subtree(cloneFn, pfx, true)
+}
+
+// SubtreeView is like CloneSubtree, but the covered subnodes, leaves and
+// fringes are shared with n, only the spine and the node owning pfx are
+// new. The view is cheap to build and must not be modified,
+// e.g. to compare regions of two tries with Overlaps.
+func (n *LiteNode[V]) SubtreeView(pfx netip.Prefix) *LiteNode[V] {
+	root, _ := n.subtree(nil, pfx, false)
+	return root
+}
+
+// subtree implements CloneSubtree and SubtreeView, size is only
+// counted for deep copies, a view just reports size > 0 if not empty.
+func (n *LiteNode[V]) subtree(cloneFn value.CloneFunc[V], pfx netip.Prefix, deep bool) (root *LiteNode[V], size int) {
+	// values derived from pfx
+	ip := pfx.Addr()
+	is4 := ip.Is4()
+	octets := ip.AsSlice()
+	lastOctetPlusOne, lastBits := LastOctetPlusOneAndLastBits(pfx)
+
+	root = new(LiteNode[V])
+
+	// the copied spine, for path compression
+	stack := make([]*LiteNode[V], 0, MaxTreeDepth)
+	c := root
+
+	// find the trie node
+	for depth, octet := range octets {
+		if depth == lastOctetPlusOne {
+			idx := art.PfxToIdx(octet, lastBits)
+			pfxFirstAddr, pfxLastAddr := art.IdxToRange(idx)
+
+			var buf [256]uint8
+			for _, idx := range n.Prefixes.AsSlice(&buf) {
+				thisFirstAddr, thisLastAddr := art.IdxToRange(idx)
+
+				if thisFirstAddr >= pfxFirstAddr && thisLastAddr <= pfxLastAddr {
+					val := n.MustGetPrefix(idx)
+					if cloneFn != nil {
+						val = cloneFn(val)
+					}
+					c.InsertPrefix(idx, val)
+					size++
+				}
+			}
+
+			for _, addr := range n.Children.AsSlice(&buf) {
+				if addr < pfxFirstAddr || addr > pfxLastAddr {
+					continue
+				}
+
+				if !deep {
+					c.InsertChild(addr, n.MustGetChild(addr))
+					size++
+					continue
+				}
+
+				switch kid := n.MustGetChild(addr).(type) {
+				case *LiteNode[V]:
+					kidClone := kid.CloneRec(cloneFn)
+					stats := kidClone.StatsRec()
+					size += stats.Prefixes + stats.Leaves + stats.Fringes
+					c.InsertChild(addr, kidClone)
+				case *LeafNode[V]:
+					c.InsertChild(addr, kid.CloneLeaf(cloneFn))
+					size++
+				case *FringeNode[V]:
+					c.InsertChild(addr, kid.CloneFringe(cloneFn))
+					size++
+				default:
+					panic("logic error, wrong node type")
+				}
+			}
+
+			break
+		}
+
+		if !n.Children.Test(octet) {
+			break
+		}
+
+		// kid is node or leaf or fringe at octet
+		switch kid := n.MustGetChild(octet).(type) {
+		case *LiteNode[V]:
+			// copy the spine and descend down to next trie level
+			stack = append(stack, c)
+			c.InsertChild(octet, new(LiteNode[V]))
+			c = c.MustGetChild(octet).(*LiteNode[V])
+			n = kid
+			continue
+
+		case *LeafNode[V]:
+			if pfx.Bits() <= kid.Prefix.Bits() && pfx.Overlaps(kid.Prefix) {
+				c.InsertChild(octet, kid.CloneLeaf(cloneFn))
+				size++
+			}
+
+		case *FringeNode[V]:
+			// get the LPM prefix back from ip and depth
+			// it's a fringe, bits are always /8, /16, /24, ...
+			fringePfx, _ := ip.Prefix((depth + 1) << 3)
+
+			if pfx.Bits() <= fringePfx.Bits() && pfx.Overlaps(fringePfx) {
+				c.InsertChild(octet, kid.CloneFringe(cloneFn))
+				size++
+			}
+
+		default:
+			panic("logic error, wrong node type")
+		}
+
+		break
+	}
+
+	if size == 0 {
+		return new(LiteNode[V]), 0
+	}
+
+	// the spine nodes have a single child, compress the path upwards
+	c.PurgeAndCompress(stack, octets, is4)
+
+	return root, size
+}
+
 // Overlaps recursively compares two trie nodes and returns true
 // if any of their prefixes or descendants overlap.
 //
@@ -2393,3 +2751,172 @@ func (n *LiteNode[V]) OverlapsTwoChildren(nChild, oChild any, depth int) bool {
 		panic("logic error, wrong node type combination")
 	}
 }
+
+// ValidateRec checks the structural invariants of the node and its subtrie,
+// the node is at depth and reached by path. It returns the number of
+// stored prefixes or a descriptive error on the first violation:
+//
+//   - bitsets, item slices and counters are consistent
+//   - prefix index 0 is never used
+//   - inner nodes below the root are not empty and within the max depth
+//   - leaves are canonical prefixes of the right family, placed at
+//     the slot of their path, and not fringes or node prefixes
+func (n *LiteNode[V]) ValidateRec(path StridePath, depth int, is4 bool) (int, error) {
+	maxDepth := MaxTreeDepth
+	if is4 {
+		maxDepth = 4
+	}
+
+	node := func() string {
+		return CidrFromPath(path, depth, is4, 1).String()
+	}
+
+	if depth >= maxDepth {
+		return 0, fmt.Errorf("node %s: depth %d exceeds max depth", node(), depth)
+	}
+	if depth > 0 && n.IsEmpty() {
+		return 0, fmt.Errorf("node %s: empty inner node", node())
+	}
+	if err := n.checkItems(); err != nil {
+		return 0, fmt.Errorf("node %s: %w", node(), err)
+	}
+	if n.Prefixes.Test(0) {
+		return 0, fmt.Errorf("node %s: invalid prefix index 0", node())
+	}
+
+	count := n.PrefixCount()
+
+	for addr, child := range n.AllChildren() {
+		switch kid := child.(type) {
+		case *LiteNode[V]:
+			path[depth] = addr
+			c, err := kid.ValidateRec(path, depth+1, is4)
+			if err != nil {
+				return 0, err
+			}
+			count += c
+
+		case *LeafNode[V]:
+			if err := validateLeaf(kid.Prefix, path, depth, is4, addr); err != nil {
+				return 0, fmt.Errorf("node %s: leaf at %d: %w", node(), addr, err)
+			}
+			count++
+
+		case *FringeNode[V]:
+			count++
+
+		default:
+			return 0, fmt.Errorf("node %s: child at %d has wrong type %T", node(), addr, child)
+		}
+	}
+
+	return count, nil
+}
+
+// DumpTrieRec returns the structured representation of the node and
+// its subtrie, the node is at depth and reached by path.
+func (n *LiteNode[V]) DumpTrieRec(path StridePath, depth int, is4 bool) TrieDump {
+	d := TrieDump{
+		Cidr:  CidrFromPath(path, depth, is4, 1),
+		Depth: depth,
+	}
+
+	var buf [256]uint8
+	d.Indexes = slices.Clone(n.Prefixes.AsSlice(&buf))
+	d.Children = slices.Clone(n.Children.AsSlice(&buf))
+
+	for _, addr := range d.Children {
+		switch kid := n.MustGetChild(addr).(type) {
+		case *LiteNode[V]:
+			path[depth] = addr
+			d.Nodes = append(d.Nodes, kid.DumpTrieRec(path, depth+1, is4))
+		case *LeafNode[V]:
+			d.LeafAddrs = append(d.LeafAddrs, addr)
+			d.LeafPfxs = append(d.LeafPfxs, kid.Prefix)
+		case *FringeNode[V]:
+			d.FringeAddr = append(d.FringeAddr, addr)
+		}
+	}
+
+	return d
+}
+
+// LookupTrace is the longest-prefix-match lookup of ip, starting at the
+// root node n, every step is reported to trace, e.g. for latency analysis.
+// It mirrors the lookup of the tables, but is not tuned for speed.
+func (n *LiteNode[V]) LookupTrace(ip netip.Addr, trace func(TraceEvent)) (val V, ok bool) {
+	is4 := ip.Is4()
+	octets := ip.AsSlice()
+
+	var path StridePath
+	copy(path[:], octets)
+
+	nodeCidr := func(depth int) netip.Prefix {
+		return CidrFromPath(path, depth, is4, 1)
+	}
+
+	// stack of the traversed nodes for backtracking
+	stack := [MaxTreeDepth]*LiteNode[V]{}
+
+	var depth int
+	var octet byte
+
+LOOP:
+	for depth, octet = range octets {
+		depth = depth & DepthMask // BCE
+
+		stack[depth] = n
+		ev := TraceEvent{Depth: depth, Octet: octet, Node: nodeCidr(depth)}
+
+		if !n.Children.Test(octet) {
+			ev.Kind = TraceNoChild
+			trace(ev)
+			break LOOP
+		}
+
+		switch kid := n.MustGetChild(octet).(type) {
+		case *LiteNode[V]:
+			ev.Kind = TraceDescend
+			trace(ev)
+			n = kid
+			continue LOOP
+
+		case *FringeNode[V]:
+			ev.Kind = TraceFringe
+			ev.Prefix = CidrForFringe(octets, depth, is4, octet)
+			ev.Hit = true
+			trace(ev)
+			return kid.Value, true
+
+		case *LeafNode[V]:
+			ev.Kind = TraceLeaf
+			ev.Prefix = kid.Prefix
+			ev.Hit = kid.Prefix.Contains(ip)
+			trace(ev)
+			if ev.Hit {
+				return kid.Value, true
+			}
+			break LOOP
+		}
+	}
+
+	// backtracking, unwind the stack
+	for ; depth >= 0; depth-- {
+		depth = depth & DepthMask // BCE
+
+		n = stack[depth]
+		idx := art.OctetToIdx(octets[depth])
+
+		ev := TraceEvent{Kind: TraceBacktrack, Depth: depth, Octet: octets[depth], Idx: idx, Node: nodeCidr(depth)}
+
+		if lpmIdx, lpmVal, lpmOk := n.LookupIdx(idx); lpmOk {
+			ev.Prefix = CidrFromPath(path, depth, is4, lpmIdx)
+			ev.Hit = true
+			trace(ev)
+			return lpmVal, true
+		}
+		trace(ev)
+	}
+
+	return val, ok
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package nodes
+
+import (
+	"reflect"
+	"slices"
+)
+
+// sizeOf returns the direct size of a T in bytes, without
+// the memory referenced by T.
+func sizeOf[T any]() int {
+	return int(reflect.TypeFor[T]().Size())
+}
+
+// MemoryRec estimates the heap bytes of the node and its subtrie:
+// the node itself, the capacity of the sparse slices, the leaves
+// and fringes. Memory referenced by the values, e.g. the contents
+// of strings, and allocator size classes are not included.
+func (n *BartNode[V]) MemoryRec() int {
+	if n == nil {
+		return 0
+	}
+
+	bytes := sizeOf[BartNode[V]]()
+	bytes += cap(n.Prefixes.Items) * sizeOf[V]()
+	bytes += cap(n.Children.Items) * sizeOf[any]()
+
+	for _, child := range n.Children.Items {
+		bytes += kidMemory[BartNode[V], V](child, (*BartNode[V]).MemoryRec)
+	}
+
+	return bytes
+}
+
+// MemoryRec estimates the heap bytes of the node and its subtrie,
+// see [BartNode.MemoryRec]. Every prefix value and child is
+// stored behind an extra pointer.
+func (n *FastNode[V]) MemoryRec() int {
+	if n == nil {
+		return 0
+	}
+
+	bytes := sizeOf[FastNode[V]]()
+	bytes += n.PrefixCount() * sizeOf[V]()
+	bytes += n.ChildCount() * sizeOf[any]()
+
+	for _, child := range n.AllChildren() {
+		bytes += kidMemory[FastNode[V], V](child, (*FastNode[V]).MemoryRec)
+	}
+
+	return bytes
+}
+
+// MemoryRec estimates the heap bytes of the node and its subtrie,
+// see [BartNode.MemoryRec].
+func (n *LiteNode[V]) MemoryRec() int {
+	if n == nil {
+		return 0
+	}
+
+	bytes := sizeOf[LiteNode[V]]()
+	bytes += cap(n.Children.Items) * sizeOf[any]()
+
+	for _, child := range n.Children.Items {
+		bytes += kidMemory[LiteNode[V], V](child, (*LiteNode[V]).MemoryRec)
+	}
+
+	return bytes
+}
+
+// shrinkItems right-sizes the sparse slices of the node to their length.
+func (n *BartNode[V]) shrinkItems() {
+	if cap(n.Prefixes.Items) > len(n.Prefixes.Items) {
+		n.Prefixes.Items = slices.Clone(n.Prefixes.Items)
+	}
+	if cap(n.Children.Items) > len(n.Children.Items) {
+		n.Children.Items = slices.Clone(n.Children.Items)
+	}
+}
+
+// shrinkItems is a no-op, the arrays of a FastNode have a fixed size.
+func (n *FastNode[V]) shrinkItems() {}
+
+// shrinkItems right-sizes the sparse slice of the node to its length.
+func (n *LiteNode[V]) shrinkItems() {
+	if cap(n.Children.Items) > len(n.Children.Items) {
+		n.Children.Items = slices.Clone(n.Children.Items)
+	}
+}
+
+// kidMemory returns the bytes of a child, recursing into inner nodes of type N.
+func kidMemory[N any, V any](child any, rec func(*N) int) int {
+	switch kid := child.(type) {
+	case *N:
+		return rec(kid)
+	case *LeafNode[V]:
+		return sizeOf[LeafNode[V]]()
+	case *FringeNode[V]:
+		return sizeOf[FringeNode[V]]()
+	default:
+		panic("logic error, wrong node type")
+	}
+}
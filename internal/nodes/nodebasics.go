@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/admpub/bart/internal/art"
+	"github.com/admpub/bart/internal/bitset"
 	"github.com/admpub/bart/internal/value"
 )
 
@@ -47,6 +48,53 @@ type TrieItem[V any] struct {
 	Val  V
 }
 
+// Occupancy describes the occupied slots of a single inner node.
+// Leaves and Fringes are subsets of Children.
+type Occupancy struct {
+	Cidr     netip.Prefix // address range of the node
+	Depth    int
+	Prefixes bitset.BitSet256
+	Children bitset.BitSet256
+	Leaves   bitset.BitSet256
+	Fringes  bitset.BitSet256
+}
+
+// TrieDump is the structured representation of an inner node and
+// its subtrie, the children are in ascending octet order.
+type TrieDump struct {
+	Cidr       netip.Prefix // address range of the node
+	Depth      int
+	Indexes    []uint8        // set prefix indexes
+	Children   []uint8        // occupied child octets
+	LeafAddrs  []uint8        // octets of the path-compressed leaves
+	LeafPfxs   []netip.Prefix // prefixes of the path-compressed leaves
+	FringeAddr []uint8        // octets of the path-compressed fringes
+	Nodes      []TrieDump     // inner child nodes
+}
+
+// TraceKind classifies a TraceEvent of LookupTrace.
+type TraceKind uint8
+
+// the trace events, in the order of a lookup
+const (
+	TraceDescend   TraceKind = iota // child node at octet, descend
+	TraceNoChild                    // no child at octet, stop descending
+	TraceLeaf                       // path-compressed leaf at octet
+	TraceFringe                     // path-compressed fringe at octet, always a match
+	TraceBacktrack                  // longest-prefix-match test in a node on the way back
+)
+
+// TraceEvent reports a step of LookupTrace.
+type TraceEvent struct {
+	Kind   TraceKind
+	Depth  int
+	Octet  uint8
+	Idx    uint8        // tested prefix index, only for TraceBacktrack
+	Node   netip.Prefix // address range of the visited node
+	Prefix netip.Prefix // leaf, fringe or matching prefix
+	Hit    bool         // the step found the longest-prefix-match
+}
+
 // StatsT, only used for dump, tests and benchmarks
 type StatsT struct {
 	Prefixes int
@@ -133,6 +181,25 @@ func CmpPrefix(a, b netip.Prefix) int {
 	return cmp.Compare(a.Bits(), b.Bits())
 }
 
+// octetAt returns the octet of ip at depth, without the slice
+// allocation of ip.AsSlice.
+func octetAt(ip netip.Addr, depth int) uint8 {
+	if ip.Is4() {
+		return ip.As4()[depth&3]
+	}
+	return ip.As16()[depth&15]
+}
+
+// allEqual reports whether all prefixes are equal.
+func allEqual(pfxs []netip.Prefix) bool {
+	for _, pfx := range pfxs[1:] {
+		if pfx != pfxs[0] {
+			return false
+		}
+	}
+	return true
+}
+
 // LeafNode represents a path-compressed routing entry that stores both prefix and value.
 // Leaf nodes are used when a prefix doesn't align with trie stride boundaries
 // and needs to be stored as a compressed path to save memory.
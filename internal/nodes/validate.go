@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package nodes
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// validateLeaf checks a leaf prefix stored at depth in the child slot addr
+// of a node with the given path.
+func validateLeaf(pfx netip.Prefix, path StridePath, depth int, is4 bool, addr uint8) error {
+	if !pfx.IsValid() || pfx.Addr().Is4() != is4 {
+		return fmt.Errorf("invalid prefix %s", pfx)
+	}
+	if pfx != pfx.Masked() {
+		return fmt.Errorf("prefix %s not masked", pfx)
+	}
+
+	// shorter prefixes are stored as node prefixes or fringes
+	if pfx.Bits() <= (depth+1)*strideLen {
+		return fmt.Errorf("prefix %s too short for a leaf at depth %d", pfx, depth)
+	}
+
+	octets := pfx.Addr().AsSlice()
+	for i := range depth {
+		if octets[i] != path[i] {
+			return fmt.Errorf("prefix %s off the node path", pfx)
+		}
+	}
+	if octets[depth] != addr {
+		return fmt.Errorf("prefix %s in wrong child slot", pfx)
+	}
+
+	return nil
+}
+
+// checkItems checks the consistency of the bitsets and the sparse arrays.
+func (n *BartNode[V]) checkItems() error {
+	if got, want := len(n.Prefixes.Items), n.Prefixes.Size(); got != want {
+		return fmt.Errorf("%d prefix items for %d bits", got, want)
+	}
+	if got, want := len(n.Children.Items), n.Children.Size(); got != want {
+		return fmt.Errorf("%d child items for %d bits", got, want)
+	}
+	return nil
+}
+
+// checkItems checks the consistency of the bitsets, the counters and the arrays.
+func (n *FastNode[V]) checkItems() error {
+	if got, want := int(n.PfxCount), n.Prefixes.Size(); got != want {
+		return fmt.Errorf("prefix count %d for %d bits", got, want)
+	}
+	if got, want := int(n.CldCount), n.Children.Size(); got != want {
+		return fmt.Errorf("child count %d for %d bits", got, want)
+	}
+
+	for i := range MaxItems {
+		idx := uint8(i)
+		if n.Prefixes.Test(idx) && n.Prefixes.Items[idx] == nil {
+			return fmt.Errorf("missing prefix item at index %d", idx)
+		}
+		if n.Children.Test(idx) != (n.Children.Items[idx] != nil) {
+			return fmt.Errorf("child item at %d inconsistent with bitset", idx)
+		}
+	}
+	return nil
+}
+
+// checkItems checks the consistency of the bitsets, the counter and the sparse array.
+func (n *LiteNode[V]) checkItems() error {
+	if got, want := int(n.Prefixes.Count), n.Prefixes.Size(); got != want {
+		return fmt.Errorf("prefix count %d for %d bits", got, want)
+	}
+	if got, want := len(n.Children.Items), n.Children.Size(); got != want {
+		return fmt.Errorf("%d child items for %d bits", got, want)
+	}
+	return nil
+}
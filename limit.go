@@ -0,0 +1,235 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"net/netip"
+)
+
+// ErrPrefixLimit is returned by [LimitedTable.Insert] if a hard
+// prefix limit would be exceeded.
+var ErrPrefixLimit = errors.New("bart: prefix limit exceeded")
+
+// PrefixLimit is a maximum prefix count for a [LimitedTable],
+// mirroring the prefix-limit feature of routers.
+//
+// Without Group the limit applies to the whole table. With Group
+// the routes are partitioned into accounting groups, e.g. per /8
+// (see [GroupBySupernet]) or per tag of the value, each group is
+// limited separately.
+type PrefixLimit[V any] struct {
+	// Name identifies the limit in events and errors.
+	Name string
+
+	// Max is the maximum number of prefixes per group.
+	Max int
+
+	// Reject turns the limit into a hard limit, inserts beyond Max are
+	// rejected. Otherwise the limit is a warning threshold only.
+	Reject bool
+
+	// Group returns the accounting group of a route, ok is false if
+	// the route isn't subject to this limit. A nil Group puts all
+	// routes into the single group "".
+	Group func(pfx netip.Prefix, val V) (group string, ok bool)
+}
+
+// LimitEvent is reported to the callback of a [LimitedTable]
+// when a prefix limit is crossed.
+type LimitEvent struct {
+	Limit    string // name of the limit
+	Group    string // accounting group
+	Count    int    // prefix count in the group, including the new prefix
+	Max      int    // the configured maximum
+	Rejected bool   // the insert was rejected
+}
+
+// GroupBySupernet returns a Group func for a [PrefixLimit] that
+// accounts all routes per covering supernet of the given length,
+// e.g. per /8 for IPv4. Routes shorter than bits are not accounted.
+func GroupBySupernet[V any](bits4, bits6 int) func(netip.Prefix, V) (string, bool) {
+	return func(pfx netip.Prefix, _ V) (string, bool) {
+		bits := bits6
+		if pfx.Addr().Is4() {
+			bits = bits4
+		}
+		if pfx.Bits() < bits {
+			return "", false
+		}
+		return netip.PrefixFrom(pfx.Addr(), bits).Masked().String(), true
+	}
+}
+
+// LimitedTable is a routing table with configurable prefix limits,
+// for systems ingesting untrusted feeds.
+//
+// Limits are checked on [LimitedTable.Insert]. Crossing a limit, i.e.
+// the first prefix above Max in a group, and every rejected insert is
+// reported to the optional callback.
+//
+// The same concurrency rules apply as for [Table], the callback is
+// called synchronously by Insert.
+type LimitedTable[V any] struct {
+	tbl Table[V]
+
+	limits  []PrefixLimit[V]
+	counts  []map[string]int // per limit: group -> count
+	onLimit func(LimitEvent)
+}
+
+// NewLimitedTable returns an empty table with the given limits,
+// onLimit may be nil.
+func NewLimitedTable[V any](onLimit func(LimitEvent), limits ...PrefixLimit[V]) *LimitedTable[V] {
+	l := &LimitedTable[V]{
+		limits:  limits,
+		counts:  make([]map[string]int, len(limits)),
+		onLimit: onLimit,
+	}
+	for i := range l.counts {
+		l.counts[i] = make(map[string]int)
+	}
+	return l
+}
+
+// group returns the accounting group of the route for limit i.
+func (l *LimitedTable[V]) group(i int, pfx netip.Prefix, val V) (string, bool) {
+	if l.limits[i].Group == nil {
+		return "", true
+	}
+	return l.limits[i].Group(pfx, val)
+}
+
+// Insert adds or updates a prefix-value pair.
+//
+// If a hard limit would be exceeded, the table is left unchanged and an
+// error wrapping [ErrPrefixLimit] is returned. Updates of existing
+// prefixes only count against a limit if the group changes.
+func (l *LimitedTable[V]) Insert(pfx netip.Prefix, val V) error {
+	if !pfx.IsValid() {
+		return nil
+	}
+	pfx = pfx.Masked()
+
+	oldVal, exists := l.tbl.Get(pfx)
+
+	// first pass: check all limits before any modification
+	for i, lim := range l.limits {
+		grp, ok := l.group(i, pfx, val)
+		if !ok || !lim.Reject {
+			continue
+		}
+		if exists {
+			if oldGrp, oldOk := l.group(i, pfx, oldVal); oldOk && oldGrp == grp {
+				continue
+			}
+		}
+
+		if count := l.counts[i][grp] + 1; count > lim.Max {
+			l.report(LimitEvent{Limit: lim.Name, Group: grp, Count: count, Max: lim.Max, Rejected: true})
+			return fmt.Errorf("%w: %s, group %q, max %d", ErrPrefixLimit, lim.Name, grp, lim.Max)
+		}
+	}
+
+	l.tbl.Insert(pfx, val)
+
+	for i, lim := range l.limits {
+		grp, ok := l.group(i, pfx, val)
+
+		if exists {
+			oldGrp, oldOk := l.group(i, pfx, oldVal)
+			if oldOk && ok && oldGrp == grp {
+				// value update within the group, count unchanged
+				continue
+			}
+			if oldOk {
+				l.decrement(i, oldGrp)
+			}
+		}
+
+		if !ok {
+			continue
+		}
+
+		// only a new member of the group crosses the threshold
+		l.counts[i][grp]++
+		if count := l.counts[i][grp]; count == lim.Max+1 {
+			l.report(LimitEvent{Limit: lim.Name, Group: grp, Count: count, Max: lim.Max})
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the exact prefix pfx from the table.
+func (l *LimitedTable[V]) Delete(pfx netip.Prefix) {
+	if val, exists := l.tbl.GetAndDelete(pfx); exists {
+		l.uncount(pfx.Masked(), val)
+	}
+}
+
+// uncount decrements the group counters of the route.
+func (l *LimitedTable[V]) uncount(pfx netip.Prefix, val V) {
+	for i := range l.limits {
+		if grp, ok := l.group(i, pfx, val); ok {
+			l.decrement(i, grp)
+		}
+	}
+}
+
+// decrement decrements the counter of the group for limit i.
+func (l *LimitedTable[V]) decrement(i int, grp string) {
+	if l.counts[i][grp]--; l.counts[i][grp] <= 0 {
+		delete(l.counts[i], grp)
+	}
+}
+
+// report calls the callback, if any.
+func (l *LimitedTable[V]) report(ev LimitEvent) {
+	if l.onLimit != nil {
+		l.onLimit(ev)
+	}
+}
+
+// Count returns the current prefix count of the group for the named limit.
+func (l *LimitedTable[V]) Count(limit, group string) int {
+	for i, lim := range l.limits {
+		if lim.Name == limit {
+			return l.counts[i][group]
+		}
+	}
+	return 0
+}
+
+// Get returns the value of the exact prefix pfx.
+func (l *LimitedTable[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	return l.tbl.Get(pfx)
+}
+
+// Contains reports whether any stored prefix covers ip, see [Table.Contains].
+func (l *LimitedTable[V]) Contains(ip netip.Addr) bool {
+	return l.tbl.Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (l *LimitedTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return l.tbl.Lookup(ip)
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (l *LimitedTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	return l.tbl.LookupPrefix(pfx)
+}
+
+// Size returns the prefix count.
+func (l *LimitedTable[V]) Size() int {
+	return l.tbl.Size()
+}
+
+// All returns an iterator over all prefix–value pairs, see [Table.All].
+func (l *LimitedTable[V]) All() iter.Seq2[netip.Prefix, V] {
+	return l.tbl.All()
+}
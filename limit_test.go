@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestLimitedTableSoftLimit(t *testing.T) {
+	t.Parallel()
+
+	var events []LimitEvent
+	l := NewLimitedTable(func(ev LimitEvent) { events = append(events, ev) },
+		PrefixLimit[int]{Name: "total", Max: 2},
+	)
+
+	for i, s := range []string{"10.0.0.0/8", "11.0.0.0/8", "12.0.0.0/8", "13.0.0.0/8"} {
+		if err := l.Insert(mpp(s), i); err != nil {
+			t.Fatalf("Insert(%s), unexpected error: %v", s, err)
+		}
+	}
+
+	if l.Size() != 4 || l.Count("total", "") != 4 {
+		t.Fatalf("Size: %d, Count: %d, want 4", l.Size(), l.Count("total", ""))
+	}
+
+	// only the crossing is reported
+	if len(events) != 1 || events[0] != (LimitEvent{Limit: "total", Count: 3, Max: 2}) {
+		t.Fatalf("events, got: %v", events)
+	}
+
+	// update, no new count
+	_ = l.Insert(mpp("10.0.0.0/8"), 42)
+	if l.Count("total", "") != 4 {
+		t.Errorf("Count after update, got: %d, want: 4", l.Count("total", ""))
+	}
+
+	l.Delete(mpp("10.0.0.0/8"))
+	l.Delete(mpp("10.0.0.0/8"))
+	if l.Count("total", "") != 3 || l.Size() != 3 {
+		t.Errorf("Count after delete, got: %d, want: 3", l.Count("total", ""))
+	}
+}
+
+func TestLimitedTableHardLimit(t *testing.T) {
+	t.Parallel()
+
+	var events []LimitEvent
+	l := NewLimitedTable(func(ev LimitEvent) { events = append(events, ev) },
+		PrefixLimit[string]{Name: "per-slash8", Max: 2, Reject: true, Group: GroupBySupernet[string](8, 32)},
+		PrefixLimit[string]{Name: "per-tag", Max: 1, Reject: true, Group: func(_ netip.Prefix, tag string) (string, bool) {
+			return tag, tag != ""
+		}},
+	)
+
+	mustInsert := func(s, tag string) {
+		t.Helper()
+		if err := l.Insert(mpp(s), tag); err != nil {
+			t.Fatalf("Insert(%s, %q), unexpected error: %v", s, tag, err)
+		}
+	}
+
+	mustInsert("10.1.0.0/16", "")
+	mustInsert("10.2.0.0/16", "")
+	mustInsert("11.1.0.0/16", "")
+	mustInsert("0.0.0.0/0", "") // not accounted per /8
+	mustInsert("2001:db8::/32", "a")
+	mustInsert("10.2.0.0/16", "") // update in a full group
+
+	err := l.Insert(mpp("10.3.0.0/16"), "")
+	if !errors.Is(err, ErrPrefixLimit) {
+		t.Fatalf("Insert beyond hard limit, got err: %v", err)
+	}
+	if _, ok := l.Get(mpp("10.3.0.0/16")); ok {
+		t.Fatal("rejected prefix was inserted")
+	}
+
+	// tag limit, moving an existing prefix to a full tag is rejected
+	err = l.Insert(mpp("11.1.0.0/16"), "a")
+	if !errors.Is(err, ErrPrefixLimit) {
+		t.Fatalf("Insert beyond tag limit, got err: %v", err)
+	}
+	if val, _ := l.Get(mpp("11.1.0.0/16")); val != "" {
+		t.Fatalf("rejected update was applied, got: %q", val)
+	}
+
+	want := []LimitEvent{
+		{Limit: "per-slash8", Group: "10.0.0.0/8", Count: 3, Max: 2, Rejected: true},
+		{Limit: "per-tag", Group: "a", Count: 2, Max: 1, Rejected: true},
+	}
+	if len(events) != len(want) || events[0] != want[0] || events[1] != want[1] {
+		t.Fatalf("events, got: %v, want: %v", events, want)
+	}
+
+	// make room and retry
+	l.Delete(mpp("10.1.0.0/16"))
+	mustInsert("10.3.0.0/16", "")
+
+	if l.Count("per-slash8", "10.0.0.0/8") != 2 || l.Count("per-slash8", "11.0.0.0/8") != 1 {
+		t.Errorf("Count, got: %d, %d", l.Count("per-slash8", "10.0.0.0/8"), l.Count("per-slash8", "11.0.0.0/8"))
+	}
+	if l.Count("unknown", "") != 0 {
+		t.Errorf("Count for unknown limit, expected 0")
+	}
+
+	if !l.Contains(mpa("10.3.0.1")) {
+		t.Errorf("Contains, expected true")
+	}
+	if _, ok := l.Lookup(mpa("10.3.0.1")); !ok {
+		t.Errorf("Lookup, expected ok")
+	}
+	if _, ok := l.LookupPrefix(mpp("10.3.1.0/24")); !ok {
+		t.Errorf("LookupPrefix, expected ok")
+	}
+
+	n := 0
+	for range l.All() {
+		n++
+	}
+	if n != l.Size() {
+		t.Errorf("All, got %d items, want: %d", n, l.Size())
+	}
+}
+
+func TestLimitedTableUpdateOverLimit(t *testing.T) {
+	t.Parallel()
+
+	var events []LimitEvent
+	l := NewLimitedTable(func(ev LimitEvent) { events = append(events, ev) },
+		PrefixLimit[string]{Name: "per-tag", Max: 2, Group: func(_ netip.Prefix, tag string) (string, bool) {
+			return tag, tag != ""
+		}},
+	)
+
+	for _, s := range []string{"10.0.0.0/8", "11.0.0.0/8", "12.0.0.0/8"} {
+		_ = l.Insert(mpp(s), "a")
+	}
+	if len(events) != 1 || l.Count("per-tag", "a") != 3 {
+		t.Fatalf("events: %v, Count: %d, want: 1 event, 3", events, l.Count("per-tag", "a"))
+	}
+
+	// value updates within the over-limit group are no crossings
+	_ = l.Insert(mpp("12.0.0.0/8"), "a")
+	_ = l.Insert(mpp("10.0.0.0/8"), "a")
+	if len(events) != 1 || l.Count("per-tag", "a") != 3 {
+		t.Fatalf("update, events: %v, Count: %d, want: 1 event, 3", events, l.Count("per-tag", "a"))
+	}
+
+	// moving a prefix out of and back into the group crosses again
+	_ = l.Insert(mpp("12.0.0.0/8"), "")
+	_ = l.Insert(mpp("12.0.0.0/8"), "a")
+	if len(events) != 2 || l.Count("per-tag", "a") != 3 {
+		t.Fatalf("regroup, events: %v, Count: %d, want: 2 events, 3", events, l.Count("per-tag", "a"))
+	}
+}
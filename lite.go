@@ -4,6 +4,8 @@
 package bart
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
 	"iter"
 	"net/netip"
@@ -47,6 +49,13 @@ func (l *Lite) Get(pfx netip.Prefix) bool {
 	return ok
 }
 
+// GetAndDelete removes the exact prefix pfx from the table in-place
+// and reports whether it existed.
+func (l *Lite) GetAndDelete(pfx netip.Prefix) bool {
+	_, ok := l.liteTable.GetAndDelete(pfx)
+	return ok
+}
+
 // Lookup performs a longest-prefix-match (LPM) for addr.
 //
 // Note: Lite stores no payload values, so this method is rarely useful.
@@ -59,6 +68,29 @@ func (l *Lite) Lookup(ip netip.Addr) bool {
 	return l.Contains(ip)
 }
 
+// LookupTrace is like [Lite.Lookup], but reports every step of the
+// lookup to trace, see [Table.LookupTrace].
+func (l *Lite) LookupTrace(ip netip.Addr, trace func(TraceStep)) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.liteTable.LookupTrace(ip, trace)
+	return ok
+}
+
+// LookupBatch reports for all addresses in ips whether any prefix
+// matches and stores the results in ok at the same positions,
+// see [Lite.ContainsBatch].
+func (l *Lite) LookupBatch(ips []netip.Addr, ok []bool) {
+	l.ContainsBatch(ips, ok)
+}
+
+// LookupPair reports for both address families in one call whether
+// any prefix matches the address, see [Table.LookupPair].
+func (l *Lite) LookupPair(ip4, ip6 netip.Addr) (ok4, ok6 bool) {
+	return l.ContainsPair(ip4, ip6)
+}
+
 // LookupPrefix performs a longest prefix match lookup for any address within
 // the given prefix.
 //
@@ -93,6 +125,16 @@ func (l *Lite) Insert(pfx netip.Prefix) {
 	l.liteTable.Insert(pfx, struct{}{})
 }
 
+// InsertBulk inserts all prefixes, e.g. to load a full table,
+// see [Table.InsertBulk].
+func (l *Lite) InsertBulk(pfxs []netip.Prefix) {
+	entries := make([]PrefixValue[struct{}], len(pfxs))
+	for i, pfx := range pfxs {
+		entries[i].Prefix = pfx
+	}
+	l.liteTable.InsertBulk(entries)
+}
+
 // InsertPersist is similar to Insert but the receiver isn't modified.
 //
 // All nodes touched during insert are cloned and a new *Lite is returned.
@@ -106,9 +148,7 @@ func (l *Lite) Insert(pfx netip.Prefix) {
 // use [Lite.InsertPersist], [Lite.ModifyPersist] and [Lite.DeletePersist]
 // for further lock-free ops.
 func (l *Lite) InsertPersist(pfx netip.Prefix) *Lite {
-	lp := l.liteTable.InsertPersist(pfx, struct{}{})
-	//nolint:govet // copy of *lp is here by intention
-	return &Lite{*lp}
+	return wrapLite(l.liteTable.InsertPersist(pfx, struct{}{}))
 }
 
 // DeletePersist is similar to Delete but does not modify the receiver.
@@ -128,8 +168,7 @@ func (l *Lite) DeletePersist(pfx netip.Prefix) *Lite {
 		return l
 	}
 
-	//nolint:govet // copy of *lp is here by intention
-	return &Lite{*lp}
+	return wrapLite(lp)
 }
 
 // Modify applies an insert, update, or delete for the given prefix.
@@ -169,9 +208,18 @@ func (l *Lite) ModifyPersist(pfx netip.Prefix, cb func(exists bool) (del bool))
 		return struct{}{}, cb(exists)
 	}
 
-	lp := l.liteTable.ModifyPersist(pfx, wrappedFn)
-	//nolint:govet // copy of *lp is here by intention
-	return &Lite{*lp}
+	return wrapLite(l.liteTable.ModifyPersist(pfx, wrappedFn))
+}
+
+// wrapLite wraps the result of a persistent liteTable operation into a *Lite.
+// The fields are moved one by one, the zero-sized lock sentinel is never copied.
+func wrapLite(lp *liteTable[struct{}]) *Lite {
+	return &Lite{liteTable[struct{}]{
+		root4: lp.root4,
+		root6: lp.root6,
+		size4: lp.size4,
+		size6: lp.size6,
+	}}
 }
 
 // dropSeq2 converts a Seq2[netip.Prefix, V] into a Seq[netip.Prefix] by discarding the value.
@@ -191,6 +239,72 @@ func (l *Lite) Clone() *Lite {
 	return &Lite{*l.liteTable.Clone()}
 }
 
+// Filter returns a new table containing only the prefixes
+// for which pred returns true, see [Table.Filter].
+func (l *Lite) Filter(pred func(pfx netip.Prefix) bool) *Lite {
+	if l == nil {
+		return nil
+	}
+	return &Lite{*l.liteTable.Filter(func(pfx netip.Prefix, _ struct{}) bool { return pred(pfx) })}
+}
+
+// CloneSubtree returns a new table containing only the prefixes
+// covered by pfx, see [Table.CloneSubtree].
+func (l *Lite) CloneSubtree(pfx netip.Prefix) *Lite {
+	if l == nil {
+		return nil
+	}
+	return &Lite{*l.liteTable.CloneSubtree(pfx)}
+}
+
+// Clear removes all prefixes from the table, see [Table.Clear].
+func (l *Lite) Clear() {
+	if l == nil {
+		return
+	}
+	l.liteTable.Clear()
+}
+
+// ReplaceRoot4 replaces the IPv4 prefixes of the receiver with the IPv4
+// prefixes of from, see [Table.ReplaceRoot4].
+func (l *Lite) ReplaceRoot4(from *Lite) {
+	if from == nil {
+		from = new(Lite)
+	}
+	l.liteTable.ReplaceRoot4(&from.liteTable)
+}
+
+// ReplaceRoot6 is like [Lite.ReplaceRoot4] but for the IPv6 prefixes.
+func (l *Lite) ReplaceRoot6(from *Lite) {
+	if from == nil {
+		from = new(Lite)
+	}
+	l.liteTable.ReplaceRoot6(&from.liteTable)
+}
+
+// ReplaceRoot4Persist is similar to ReplaceRoot4 but the receiver isn't
+// modified and a new *Lite is returned, see [Table.ReplaceRoot4Persist].
+func (l *Lite) ReplaceRoot4Persist(from *Lite) *Lite {
+	if l == nil {
+		l = new(Lite)
+	}
+	if from == nil {
+		from = new(Lite)
+	}
+	return wrapLite(l.liteTable.ReplaceRoot4Persist(&from.liteTable))
+}
+
+// ReplaceRoot6Persist is like [Lite.ReplaceRoot4Persist] but for the IPv6 prefixes.
+func (l *Lite) ReplaceRoot6Persist(from *Lite) *Lite {
+	if l == nil {
+		l = new(Lite)
+	}
+	if from == nil {
+		from = new(Lite)
+	}
+	return wrapLite(l.liteTable.ReplaceRoot6Persist(&from.liteTable))
+}
+
 // Union merges another routing table into the receiver table, modifying it in-place.
 //
 // All prefixes from the other table (o) are inserted into the receiver.
@@ -210,9 +324,34 @@ func (l *Lite) UnionPersist(o *Lite) *Lite {
 	if o == nil || (o.size4 == 0 && o.size6 == 0) {
 		return l
 	}
-	lp := l.liteTable.UnionPersist(&o.liteTable)
-	//nolint:govet // copy of *lp is here by intention
-	return &Lite{*lp}
+	return wrapLite(l.liteTable.UnionPersist(&o.liteTable))
+}
+
+// Intersect returns a new table with the prefixes present in both l and o,
+// see [Table.Intersect].
+func (l *Lite) Intersect(o *Lite) *Lite {
+	if l == nil || o == nil {
+		return new(Lite)
+	}
+	return wrapLite(l.liteTable.Intersect(&o.liteTable, nil))
+}
+
+// Difference returns a new table with the prefixes of l not present in o,
+// see [Table.Difference].
+func (l *Lite) Difference(o *Lite) *Lite {
+	if l == nil {
+		return new(Lite)
+	}
+	if o == nil {
+		return l.Clone()
+	}
+	return wrapLite(l.liteTable.Difference(&o.liteTable))
+}
+
+// SymmetricDifference returns the prefixes present in exactly one of l and o,
+// split by origin, see [Table.SymmetricDifference].
+func (l *Lite) SymmetricDifference(o *Lite) (onlyL, onlyO *Lite) {
+	return l.Difference(o), o.Difference(l)
 }
 
 // All returns an iterator over all prefixes in the table.
@@ -248,6 +387,24 @@ func (l *Lite) All() iter.Seq[netip.Prefix] {
 	return dropSeq2(l.liteTable.All())
 }
 
+// AppendEntries appends all prefixes to dst and returns the
+// extended slice, see [Table.AppendEntries].
+func (l *Lite) AppendEntries(dst []netip.Prefix) []netip.Prefix {
+	if l == nil {
+		return dst
+	}
+
+	yield := func(pfx netip.Prefix, _ struct{}) bool {
+		dst = append(dst, pfx)
+		return true
+	}
+
+	l.root4.AllRec(stridePath{}, 0, true, yield)
+	l.root6.AllRec(stridePath{}, 0, false, yield)
+
+	return dst
+}
+
 // All4 is like [Lite.All] but only for the v4 routing table.
 func (l *Lite) All4() iter.Seq[netip.Prefix] {
 	if l == nil {
@@ -351,6 +508,33 @@ func (l *Lite) Supernets(pfx netip.Prefix) iter.Seq[netip.Prefix] {
 	return dropSeq2(l.liteTable.Supernets(pfx))
 }
 
+// WouldShadow returns an iterator over the existing more-specific prefixes
+// a proposed new aggregate pfx would cover, see [Table.WouldShadow].
+func (l *Lite) WouldShadow(pfx netip.Prefix) iter.Seq[netip.Prefix] {
+	if l == nil {
+		return func(func(netip.Prefix) bool) {}
+	}
+	return dropSeq2(l.liteTable.WouldShadow(pfx))
+}
+
+// WouldBeShadowedBy returns an iterator over the existing less-specific
+// prefixes covering a proposed new prefix pfx, see [Table.WouldBeShadowedBy].
+func (l *Lite) WouldBeShadowedBy(pfx netip.Prefix) iter.Seq[netip.Prefix] {
+	if l == nil {
+		return func(func(netip.Prefix) bool) {}
+	}
+	return dropSeq2(l.liteTable.WouldBeShadowedBy(pfx))
+}
+
+// LookupAll returns an iterator over all routes matching ip, from the
+// longest prefix match towards the least-specific route, see [Table.LookupAll].
+func (l *Lite) LookupAll(ip netip.Addr) iter.Seq[netip.Prefix] {
+	if l == nil {
+		return func(func(netip.Prefix) bool) {}
+	}
+	return dropSeq2(l.liteTable.LookupAll(ip))
+}
+
 // Overlaps reports whether any route in the receiver table overlaps
 // with a route in the other table, in either direction.
 //
@@ -389,6 +573,14 @@ func (l *Lite) Overlaps6(o *Lite) bool {
 	return l.liteTable.Overlaps6(&o.liteTable)
 }
 
+// OverlapsIn is like [Lite.Overlaps] but restricted to the region pfx.
+func (l *Lite) OverlapsIn(pfx netip.Prefix, o *Lite) bool {
+	if o == nil {
+		return false
+	}
+	return l.liteTable.OverlapsIn(pfx, &o.liteTable)
+}
+
 // Equal checks whether two tables are structurally and semantically equal.
 // It ensures both trees (IPv4-based and IPv6-based) have the same sizes and
 // recursively compares their root nodes.
@@ -448,6 +640,72 @@ func (l *Lite) Fprint(w io.Writer) error {
 	return l.liteTable.Fprint(w)
 }
 
+// Dump returns a machine-readable tree of the inner trie nodes,
+// see [Table.Dump].
+func (l *Lite) Dump() TrieDump {
+	if l == nil {
+		return TrieDump{}
+	}
+	return l.liteTable.Dump()
+}
+
+// Validate checks the internal invariants of the table,
+// see [Table.Validate].
+func (l *Lite) Validate() error {
+	if l == nil {
+		return nil
+	}
+	return l.liteTable.Validate()
+}
+
+// MemoryFootprint estimates the heap bytes used by the table,
+// see [Table.MemoryFootprint].
+func (l *Lite) MemoryFootprint() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.liteTable.MemoryFootprint()
+}
+
+// Compact shrinks the table after heavy deletion and returns the
+// reclaimed heap bytes, see [Table.Compact].
+func (l *Lite) Compact() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.liteTable.Compact()
+}
+
+// String implements [fmt.Stringer] with a short summary of the table,
+// see [Table.String].
+func (l *Lite) String() string {
+	if l == nil {
+		return "<nil>"
+	}
+	return l.liteTable.String()
+}
+
+// DumpString returns the full tree of the table as printed by
+// [Lite.Fprint].
+func (l *Lite) DumpString() string {
+	if l == nil {
+		return ""
+	}
+	return l.liteTable.DumpString()
+}
+
+// FprintFunc is like [Lite.Fprint], but every prefix is annotated
+// with the string returned by format, see [Table.FprintFunc].
+func (l *Lite) FprintFunc(w io.Writer, format func(pfx netip.Prefix) string) error {
+	if l == nil {
+		return nil
+	}
+	if format == nil {
+		return l.liteTable.FprintFunc(w, nil)
+	}
+	return l.liteTable.FprintFunc(w, func(pfx netip.Prefix, _ struct{}) string { return format(pfx) })
+}
+
 // MarshalJSON dumps the table into two sorted lists: for ipv4 and ipv6.
 // Every root and subnet is an array, not a map, because the order matters.
 func (l *Lite) MarshalJSON() ([]byte, error) {
@@ -457,6 +715,48 @@ func (l *Lite) MarshalJSON() ([]byte, error) {
 	return l.liteTable.MarshalJSON()
 }
 
+// MarshalJSONFlat encodes the table as flat list of prefixes in CIDR
+// sort order, e.g. ["10.0.0.0/8","2001:db8::/32"], see [Table.MarshalJSONFlat].
+// The result is accepted by [Lite.UnmarshalJSON].
+func (l *Lite) MarshalJSONFlat() ([]byte, error) {
+	if l == nil {
+		return []byte("null"), nil
+	}
+
+	pfxs := make([]netip.Prefix, 0, l.Size())
+	for pfx := range l.AllSorted() {
+		pfxs = append(pfxs, pfx)
+	}
+
+	return json.Marshal(pfxs)
+}
+
+// UnmarshalJSON replaces the content of the table with the decoded prefixes,
+// see [Table.UnmarshalJSON]. Additionally the flat list of prefixes
+// as produced by encoding the result of [Lite.AppendEntries] is accepted.
+func (l *Lite) UnmarshalJSON(data []byte) error {
+	if l == nil {
+		return errors.New("bart: UnmarshalJSON on nil table")
+	}
+
+	var pfxs []netip.Prefix
+	if err := json.Unmarshal(data, &pfxs); err != nil || pfxs == nil {
+		return l.liteTable.UnmarshalJSON(data)
+	}
+
+	for _, pfx := range pfxs {
+		if !pfx.IsValid() {
+			return errors.New("bart: UnmarshalJSON, invalid prefix")
+		}
+	}
+
+	l.liteTable.Clear()
+	for _, pfx := range pfxs {
+		l.liteTable.insert(pfx, struct{}{})
+	}
+	return nil
+}
+
 // MarshalText implements the [encoding.TextMarshaler] interface,
 // just a wrapper for [liteTable.Fprint].
 func (l *Lite) MarshalText() ([]byte, error) {
@@ -4,6 +4,7 @@
 package bart
 
 import (
+	"encoding/json"
 	"net/netip"
 	"testing"
 )
@@ -30,8 +31,8 @@ func TestTableNil_LiteTable(t *testing.T) {
 		mustPanic(t, "sizeUpdate", func() { tbl1.sizeUpdate(false, 1) })
 		mustPanic(t, "rootNodeByVersion", func() { tbl1.rootNodeByVersion(true) })
 		mustPanic(t, "rootNodeByVersion", func() { tbl1.rootNodeByVersion(false) })
-		mustPanic(t, "fprint", func() { tbl1.fprint(nil, true) })
-		mustPanic(t, "fprint", func() { tbl1.fprint(nil, false) })
+		mustPanic(t, "fprint", func() { tbl1.fprint(nil, true, nil) })
+		mustPanic(t, "fprint", func() { tbl1.fprint(nil, false, nil) })
 
 		mustPanic(t, "Size", func() { tbl1.Size() })
 		mustPanic(t, "Size4", func() { tbl1.Size4() })
@@ -39,13 +40,21 @@ func TestTableNil_LiteTable(t *testing.T) {
 
 		mustPanic(t, "Get", func() { tbl1.Get(pfx4) })
 		mustPanic(t, "Insert", func() { tbl1.Insert(pfx4) })
+		mustPanic(t, "InsertBulk", func() { tbl1.InsertBulk([]netip.Prefix{pfx4}) })
 		mustPanic(t, "InsertPersist", func() { tbl1.InsertPersist(pfx4) })
 		mustPanic(t, "Delete", func() { tbl1.Delete(pfx4) })
+		mustPanic(t, "GetAndDelete", func() { tbl1.GetAndDelete(pfx4) })
 		mustPanic(t, "DeletePersist", func() { tbl1.DeletePersist(pfx4) })
 		mustPanic(t, "Modify", func() { tbl1.Modify(pfx4, nil) })
 		mustPanic(t, "ModifyPersist", func() { tbl1.ModifyPersist(pfx4, nil) })
 		mustPanic(t, "Contains", func() { tbl1.Contains(ip4) })
 		mustPanic(t, "Lookup", func() { tbl1.Lookup(ip6) })
+		mustPanic(t, "LookupPair", func() { tbl1.LookupPair(ip4, ip6) })
+		mustPanic(t, "LookupBatch", func() { tbl1.LookupBatch([]netip.Addr{ip4}, make([]bool, 1)) })
+		mustPanic(t, "ContainsBatch", func() { tbl1.ContainsBatch([]netip.Addr{ip4}, make([]bool, 1)) })
+		mustPanic(t, "ReplaceRoot4", func() { tbl1.ReplaceRoot4(tbl2) })
+		mustPanic(t, "ReplaceRoot6", func() { tbl1.ReplaceRoot6(nil) })
+		mustPanic(t, "ContainsPair", func() { tbl1.ContainsPair(ip4, ip6) })
 		mustPanic(t, "LookupPrefix", func() { tbl1.LookupPrefix(pfx4) })
 		mustPanic(t, "LookupPrefixLPM", func() { tbl1.LookupPrefixLPM(pfx4) })
 		mustPanic(t, "Union", func() { tbl1.Union(tbl2) })
@@ -68,6 +77,8 @@ func TestTableNil_LiteTable(t *testing.T) {
 		mustPanic(t, "Overlaps6", func() { tbl1.Overlaps6(tbl2) })
 		mustPanic(t, "OverlapsPrefix", func() { tbl1.OverlapsPrefix(pfx4) })
 		mustPanic(t, "OverlapsPrefix", func() { tbl1.OverlapsPrefix(pfx6) })
+		mustPanic(t, "OverlapsIn", func() { tbl1.OverlapsIn(pfx4, tbl2) })
+		noPanic(t, "OverlapsIn", func() { tbl1.OverlapsIn(pfx4, nil) })
 
 		mustPanic(t, "Equal", func() { tbl1.Equal(tbl2) })
 		noPanic(t, "Equal", func() { tbl1.Equal(tbl1) })
@@ -76,10 +87,28 @@ func TestTableNil_LiteTable(t *testing.T) {
 		mustPanic(t, "dump", func() { tbl1.dump(nil) })
 		mustPanic(t, "dumpString", func() { tbl1.dumpString() })
 		noPanic(t, "Clone", func() { tbl1.Clone() })
+		noPanic(t, "Clear", func() { tbl1.Clear() })
+		noPanic(t, "Filter", func() { tbl1.Filter(nil) })
+		noPanic(t, "Intersect", func() { tbl1.Intersect(tbl2) })
+		noPanic(t, "Difference", func() { tbl1.Difference(tbl2) })
+		noPanic(t, "SymmetricDifference", func() { tbl1.SymmetricDifference(tbl2) })
+		noPanic(t, "CloneSubtree", func() { tbl1.CloneSubtree(mpp("10.0.0.0/8")) })
+		noPanic(t, "ReplaceRoot4Persist", func() { tbl1.ReplaceRoot4Persist(tbl2) })
+		noPanic(t, "ReplaceRoot6Persist", func() { tbl1.ReplaceRoot6Persist(nil) })
+		noPanic(t, "AppendEntries", func() { tbl1.AppendEntries(nil) })
 		noPanic(t, "DumpList4", func() { tbl1.DumpList4() })
 		noPanic(t, "DumpList6", func() { tbl1.DumpList6() })
 		noPanic(t, "Fprint", func() { tbl1.Fprint(nil) })
+		noPanic(t, "FprintFunc", func() { tbl1.FprintFunc(nil, nil) })
+		noPanic(t, "String", func() { _ = tbl1.String() })
+		noPanic(t, "MemoryFootprint", func() { tbl1.MemoryFootprint() })
+		noPanic(t, "Compact", func() { tbl1.Compact() })
+		noPanic(t, "Validate", func() { _ = tbl1.Validate() })
+		noPanic(t, "Dump", func() { tbl1.Dump() })
+		noPanic(t, "LookupTrace", func() { tbl1.LookupTrace(ip4, nil) })
+		noPanic(t, "DumpString", func() { _ = tbl1.DumpString() })
 		noPanic(t, "MarshalJSON", func() { _, _ = tbl1.MarshalJSON() })
+		noPanic(t, "MarshalJSONFlat", func() { _, _ = tbl1.MarshalJSONFlat() })
 		noPanic(t, "MarshalText", func() { _, _ = tbl1.MarshalText() })
 	})
 
@@ -94,6 +123,9 @@ func TestTableNil_LiteTable(t *testing.T) {
 		noPanicRangeOverFunc[any](t, "AllSorted6", tbl1.AllSorted6)
 		noPanicRangeOverFunc[any](t, "Subnets", tbl1.Subnets)
 		noPanicRangeOverFunc[any](t, "Supernets", tbl1.Supernets)
+		noPanicRangeOverFunc[any](t, "WouldShadow", tbl1.WouldShadow)
+		noPanicRangeOverFunc[any](t, "WouldBeShadowedBy", tbl1.WouldBeShadowedBy)
+		noPanicRangeOverFunc[any](t, "LookupAll", tbl1.LookupAll)
 	})
 }
 
@@ -112,6 +144,9 @@ func TestTableInvalid_LiteTable(t *testing.T) {
 	noPanic(t, "AllSorted", func() { tbl1.AllSorted() })
 	noPanic(t, "AllSorted4", func() { tbl1.AllSorted4() })
 	noPanic(t, "AllSorted6", func() { tbl1.AllSorted6() })
+	noPanic(t, "Clear", func() { tbl1.Clear() })
+	noPanic(t, "Filter", func() { tbl1.Filter(func(netip.Prefix) bool { return true }) })
+	noPanic(t, "CloneSubtree", func() { tbl1.CloneSubtree(zeroPfx) })
 	noPanic(t, "Clone", func() { tbl1.Clone() })
 	noPanic(t, "Contains", func() { tbl1.Contains(zeroIP) })
 	noPanic(t, "Delete", func() { tbl1.Delete(zeroPfx) })
@@ -120,13 +155,22 @@ func TestTableInvalid_LiteTable(t *testing.T) {
 	noPanic(t, "DumpList6", func() { tbl1.DumpList6() })
 	noPanic(t, "Equal", func() { tbl1.Equal(tbl2) })
 	noPanic(t, "Fprint", func() { tbl1.Fprint(nil) })
+	noPanic(t, "FprintFunc", func() { tbl1.FprintFunc(nil, nil) })
 	noPanic(t, "Get", func() { tbl1.Get(zeroPfx) })
+	noPanic(t, "GetAndDelete", func() { tbl1.GetAndDelete(zeroPfx) })
 	noPanic(t, "Insert", func() { tbl1.Insert(zeroPfx) })
+	noPanic(t, "InsertBulk", func() { tbl1.InsertBulk([]netip.Prefix{zeroPfx}) })
 	noPanic(t, "InsertPersist", func() { tbl1.InsertPersist(zeroPfx) })
 	noPanic(t, "Lookup", func() { tbl1.Lookup(zeroIP) })
+	noPanic(t, "LookupAll", func() { tbl1.LookupAll(zeroIP) })
+	noPanic(t, "LookupPair", func() { tbl1.LookupPair(zeroIP, zeroIP) })
+	noPanic(t, "LookupBatch", func() { tbl1.LookupBatch([]netip.Addr{zeroIP}, make([]bool, 1)) })
+	noPanic(t, "ContainsBatch", func() { tbl1.ContainsBatch([]netip.Addr{zeroIP}, make([]bool, 1)) })
+	noPanic(t, "LookupTrace", func() { tbl1.LookupTrace(zeroIP, nil) })
 	noPanic(t, "LookupPrefix", func() { tbl1.LookupPrefix(zeroPfx) })
 	noPanic(t, "LookupPrefixLPM", func() { tbl1.LookupPrefixLPM(zeroPfx) })
 	noPanic(t, "MarshalJSON", func() { _, _ = tbl1.MarshalJSON() })
+	noPanic(t, "MarshalJSONFlat", func() { _, _ = tbl1.MarshalJSONFlat() })
 	noPanic(t, "MarshalText", func() { _, _ = tbl1.MarshalText() })
 	noPanic(t, "Modify", func() { tbl1.Modify(zeroPfx, nil) })
 	noPanic(t, "ModifyPersist", func() { tbl1.ModifyPersist(zeroPfx, nil) })
@@ -134,11 +178,70 @@ func TestTableInvalid_LiteTable(t *testing.T) {
 	noPanic(t, "Overlaps4", func() { tbl1.Overlaps4(tbl2) })
 	noPanic(t, "Overlaps6", func() { tbl1.Overlaps6(tbl2) })
 	noPanic(t, "OverlapsPrefix", func() { tbl1.OverlapsPrefix(zeroPfx) })
+	noPanic(t, "OverlapsIn", func() { tbl1.OverlapsIn(zeroPfx, tbl2) })
 	noPanic(t, "Size", func() { tbl1.Size() })
 	noPanic(t, "Size4", func() { tbl1.Size4() })
 	noPanic(t, "Size6", func() { tbl1.Size6() })
 	noPanic(t, "Subnets", func() { tbl1.Subnets(zeroPfx) })
 	noPanic(t, "Supernets", func() { tbl1.Supernets(zeroPfx) })
+	noPanic(t, "WouldShadow", func() { tbl1.WouldShadow(zeroPfx) })
+	noPanic(t, "WouldBeShadowedBy", func() { tbl1.WouldBeShadowedBy(zeroPfx) })
 	noPanic(t, "Union", func() { tbl1.Union(tbl2) })
 	noPanic(t, "UnionPersist", func() { tbl1.UnionPersist(tbl2) })
 }
+
+func TestLiteUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Lite)
+	tbl.Insert(mpp("10.0.0.0/8"))
+	tbl.Insert(mpp("2001:db8::/32"))
+
+	for _, marshal := range []func() ([]byte, error){
+		tbl.MarshalJSON,
+		tbl.MarshalJSONFlat,
+		func() ([]byte, error) { return json.Marshal(tbl.AppendEntries(nil)) },
+	} {
+		data, err := marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := new(Lite)
+		got.Insert(mpp("0.0.0.0/0"))
+		if err := json.Unmarshal(data, got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if !got.Equal(tbl) {
+			t.Fatalf("Unmarshal(%s), tables differ", data)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`["10.0.0.0/33"]`), tbl); err == nil {
+		t.Error("Unmarshal of invalid prefix, expected error")
+	}
+}
+
+func TestLiteSetOps(t *testing.T) {
+	t.Parallel()
+
+	a, b := new(Lite), new(Lite)
+	a.Insert(mpp("10.0.0.0/8"))
+	a.Insert(mpp("192.168.0.0/16"))
+	b.Insert(mpp("10.0.0.0/8"))
+	b.Insert(mpp("2001:db8::/32"))
+
+	want := new(Lite)
+	want.Insert(mpp("10.0.0.0/8"))
+	if !a.Intersect(b).Equal(want) {
+		t.Fatal("Intersect, expected the common prefix")
+	}
+
+	onlyA, onlyB := a.SymmetricDifference(b)
+	if onlyA.Size() != 1 || !onlyA.Get(mpp("192.168.0.0/16")) {
+		t.Fatal("SymmetricDifference, unexpected prefixes only in a")
+	}
+	if onlyB.Size() != 1 || !onlyB.Get(mpp("2001:db8::/32")) {
+		t.Fatal("SymmetricDifference, unexpected prefixes only in b")
+	}
+}
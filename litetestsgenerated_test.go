@@ -7,12 +7,16 @@ package bart
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/bits"
 	"math/rand/v2"
 	"net/netip"
+	"reflect"
 	"slices"
 	"strings"
 	"testing"
 
+	"github.com/admpub/bart/internal/nodes"
 	"github.com/admpub/bart/internal/tests/golden"
 	"github.com/admpub/bart/internal/tests/random"
 )
@@ -52,8 +56,8 @@ func TestTableNil_liteTable(t *testing.T) {
 		mustPanic(t, "sizeUpdate", func() { tbl1.sizeUpdate(false, 1) })
 		mustPanic(t, "rootNodeByVersion", func() { tbl1.rootNodeByVersion(true) })
 		mustPanic(t, "rootNodeByVersion", func() { tbl1.rootNodeByVersion(false) })
-		mustPanic(t, "fprint", func() { tbl1.fprint(nil, true) })
-		mustPanic(t, "fprint", func() { tbl1.fprint(nil, false) })
+		mustPanic(t, "fprint", func() { tbl1.fprint(nil, true, nil) })
+		mustPanic(t, "fprint", func() { tbl1.fprint(nil, false, nil) })
 
 		mustPanic(t, "Size", func() { tbl1.Size() })
 		mustPanic(t, "Size4", func() { tbl1.Size4() })
@@ -61,13 +65,24 @@ func TestTableNil_liteTable(t *testing.T) {
 
 		mustPanic(t, "Get", func() { tbl1.Get(pfx4) })
 		mustPanic(t, "Insert", func() { tbl1.Insert(pfx4, nil) })
+		mustPanic(t, "InsertBulk", func() { tbl1.InsertBulk([]PrefixValue[any]{{Prefix: pfx4}}) })
 		mustPanic(t, "InsertPersist", func() { tbl1.InsertPersist(pfx4, nil) })
 		mustPanic(t, "Delete", func() { tbl1.Delete(pfx4) })
+		mustPanic(t, "GetAndDelete", func() { tbl1.GetAndDelete(pfx4) })
 		mustPanic(t, "DeletePersist", func() { tbl1.DeletePersist(pfx4) })
 		mustPanic(t, "Modify", func() { tbl1.Modify(pfx4, nil) })
+		mustPanic(t, "Update", func() { tbl1.Update(pfx4, nil) })
 		mustPanic(t, "ModifyPersist", func() { tbl1.ModifyPersist(pfx4, nil) })
+		mustPanic(t, "ResetValues", func() { tbl1.ResetValues(nil) })
+		mustPanic(t, "Fill", func() { tbl1.Fill(nil) })
+		mustPanic(t, "ReplaceRoot4", func() { tbl1.ReplaceRoot4(tbl2) })
+		mustPanic(t, "ReplaceRoot6", func() { tbl1.ReplaceRoot6(tbl2) })
 		mustPanic(t, "Contains", func() { tbl1.Contains(ip4) })
 		mustPanic(t, "Lookup", func() { tbl1.Lookup(ip6) })
+		mustPanic(t, "LookupPair", func() { tbl1.LookupPair(ip4, ip6) })
+		mustPanic(t, "LookupBatch", func() { tbl1.LookupBatch([]netip.Addr{ip4}, make([]any, 1), make([]bool, 1)) })
+		mustPanic(t, "ContainsBatch", func() { tbl1.ContainsBatch([]netip.Addr{ip4}, make([]bool, 1)) })
+		mustPanic(t, "ContainsPair", func() { tbl1.ContainsPair(ip4, ip6) })
 		mustPanic(t, "LookupPrefix", func() { tbl1.LookupPrefix(pfx4) })
 		mustPanic(t, "LookupPrefixLPM", func() { tbl1.LookupPrefixLPM(pfx4) })
 		mustPanic(t, "Union", func() { tbl1.Union(tbl2) })
@@ -90,6 +105,8 @@ func TestTableNil_liteTable(t *testing.T) {
 		mustPanic(t, "Overlaps6", func() { tbl1.Overlaps6(tbl2) })
 		mustPanic(t, "OverlapsPrefix", func() { tbl1.OverlapsPrefix(pfx4) })
 		mustPanic(t, "OverlapsPrefix", func() { tbl1.OverlapsPrefix(pfx6) })
+		mustPanic(t, "OverlapsIn", func() { tbl1.OverlapsIn(pfx4, tbl2) })
+		noPanic(t, "OverlapsIn", func() { tbl1.OverlapsIn(pfx4, nil) })
 
 		mustPanic(t, "Equal", func() { tbl1.Equal(tbl2) })
 		noPanic(t, "Equal", func() { tbl1.Equal(tbl1) })
@@ -98,10 +115,34 @@ func TestTableNil_liteTable(t *testing.T) {
 		noPanic(t, "dump", func() { tbl1.dump(nil) })
 		noPanic(t, "dumpString", func() { tbl1.dumpString() })
 		noPanic(t, "Clone", func() { tbl1.Clone() })
+		noPanic(t, "Clear", func() { tbl1.Clear() })
+		noPanic(t, "Filter", func() { tbl1.Filter(nil) })
+		noPanic(t, "Intersect", func() { tbl1.Intersect(tbl2, nil) })
+		noPanic(t, "Difference", func() { tbl1.Difference(tbl2) })
+		noPanic(t, "SymmetricDifference", func() { tbl1.SymmetricDifference(tbl2) })
+		noPanic(t, "UnionWith", func() { tbl1.UnionWith(nil, nil) })
+		noPanic(t, "CloneSubtree", func() { tbl1.CloneSubtree(mpp("10.0.0.0/8")) })
+		noPanic(t, "AppendEntries", func() { tbl1.AppendEntries(nil) })
+		noPanic(t, "Stats", func() { tbl1.Stats() })
+		noPanic(t, "MemoryFootprint", func() { tbl1.MemoryFootprint() })
+		noPanic(t, "Compact", func() { tbl1.Compact() })
+		noPanic(t, "Validate", func() { _ = tbl1.Validate() })
+		noPanic(t, "Dump", func() { tbl1.Dump() })
+		noPanic(t, "LookupTrace", func() { tbl1.LookupTrace(ip4, nil) })
+		noPanic(t, "String", func() { _ = tbl1.String() })
+		noPanic(t, "DumpString", func() { _ = tbl1.DumpString() })
+		noPanic(t, "Occupancy", func() {
+			for range tbl1.Occupancy() {
+			}
+		})
 		noPanic(t, "DumpList4", func() { tbl1.DumpList4() })
 		noPanic(t, "DumpList6", func() { tbl1.DumpList6() })
 		noPanic(t, "Fprint", func() { tbl1.Fprint(nil) })
+		noPanic(t, "FprintFunc", func() { tbl1.FprintFunc(nil, nil) })
 		noPanic(t, "MarshalJSON", func() { _, _ = tbl1.MarshalJSON() })
+		noPanic(t, "MarshalJSONFlat", func() { _, _ = tbl1.MarshalJSONFlat() })
+		noPanic(t, "UnmarshalJSON", func() { _ = tbl1.UnmarshalJSON([]byte("null")) })
+		noPanic(t, "UnmarshalJSON", func() { _ = tbl1.UnmarshalJSON(nil) })
 		noPanic(t, "MarshalText", func() { _, _ = tbl1.MarshalText() })
 	})
 
@@ -116,6 +157,9 @@ func TestTableNil_liteTable(t *testing.T) {
 		noPanicRangeOverFunc[any](t, "AllSorted6", tbl1.AllSorted6)
 		noPanicRangeOverFunc[any](t, "Subnets", tbl1.Subnets)
 		noPanicRangeOverFunc[any](t, "Supernets", tbl1.Supernets)
+		noPanicRangeOverFunc[any](t, "WouldShadow", tbl1.WouldShadow)
+		noPanicRangeOverFunc[any](t, "WouldBeShadowedBy", tbl1.WouldBeShadowedBy)
+		noPanicRangeOverFunc[any](t, "LookupAll", tbl1.LookupAll)
 	})
 }
 
@@ -134,35 +178,56 @@ func TestTableInvalid_liteTable(t *testing.T) {
 	noPanic(t, "AllSorted", func() { tbl1.AllSorted() })
 	noPanic(t, "AllSorted4", func() { tbl1.AllSorted4() })
 	noPanic(t, "AllSorted6", func() { tbl1.AllSorted6() })
+	noPanic(t, "AppendEntries", func() { tbl1.AppendEntries(nil) })
+	noPanic(t, "Clear", func() { tbl1.Clear() })
 	noPanic(t, "Clone", func() { tbl1.Clone() })
+	noPanic(t, "Filter", func() { tbl1.Filter(func(netip.Prefix, any) bool { return true }) })
+	noPanic(t, "CloneSubtree", func() { tbl1.CloneSubtree(zeroPfx) })
 	noPanic(t, "Contains", func() { tbl1.Contains(zeroIP) })
 	noPanic(t, "Delete", func() { tbl1.Delete(zeroPfx) })
 	noPanic(t, "DeletePersist", func() { tbl1.DeletePersist(zeroPfx) })
 	noPanic(t, "DumpList4", func() { tbl1.DumpList4() })
 	noPanic(t, "DumpList6", func() { tbl1.DumpList6() })
 	noPanic(t, "Equal", func() { tbl1.Equal(tbl2) })
+	noPanic(t, "Fill", func() { tbl1.Fill(nil) })
 	noPanic(t, "Fprint", func() { tbl1.Fprint(nil) })
+	noPanic(t, "FprintFunc", func() { tbl1.FprintFunc(nil, nil) })
 	noPanic(t, "Get", func() { tbl1.Get(zeroPfx) })
+	noPanic(t, "GetAndDelete", func() { tbl1.GetAndDelete(zeroPfx) })
 	noPanic(t, "Insert", func() { tbl1.Insert(zeroPfx, nil) })
+	noPanic(t, "InsertBulk", func() { tbl1.InsertBulk([]PrefixValue[any]{{Prefix: zeroPfx}}) })
 	noPanic(t, "InsertPersist", func() { tbl1.InsertPersist(zeroPfx, nil) })
 	noPanic(t, "Lookup", func() { tbl1.Lookup(zeroIP) })
+	noPanic(t, "LookupPair", func() { tbl1.LookupPair(zeroIP, zeroIP) })
+	noPanic(t, "LookupBatch", func() { tbl1.LookupBatch([]netip.Addr{zeroIP}, make([]any, 1), make([]bool, 1)) })
+	noPanic(t, "ContainsBatch", func() { tbl1.ContainsBatch([]netip.Addr{zeroIP}, make([]bool, 1)) })
+	noPanic(t, "LookupTrace", func() { tbl1.LookupTrace(zeroIP, nil) })
+	noPanic(t, "ContainsPair", func() { tbl1.ContainsPair(zeroIP, zeroIP) })
+	noPanic(t, "LookupAll", func() { tbl1.LookupAll(zeroIP) })
 	noPanic(t, "LookupPrefix", func() { tbl1.LookupPrefix(zeroPfx) })
 	noPanic(t, "LookupPrefixLPM", func() { tbl1.LookupPrefixLPM(zeroPfx) })
 	noPanic(t, "MarshalJSON", func() { _, _ = tbl1.MarshalJSON() })
+	noPanic(t, "MarshalJSONFlat", func() { _, _ = tbl1.MarshalJSONFlat() })
 	noPanic(t, "MarshalText", func() { _, _ = tbl1.MarshalText() })
 	noPanic(t, "Modify", func() { tbl1.Modify(zeroPfx, nil) })
 	noPanic(t, "ModifyPersist", func() { tbl1.ModifyPersist(zeroPfx, nil) })
+	noPanic(t, "Update", func() { tbl1.Update(zeroPfx, nil) })
 	noPanic(t, "Overlaps", func() { tbl1.Overlaps(tbl2) })
 	noPanic(t, "Overlaps4", func() { tbl1.Overlaps4(tbl2) })
 	noPanic(t, "Overlaps6", func() { tbl1.Overlaps6(tbl2) })
 	noPanic(t, "OverlapsPrefix", func() { tbl1.OverlapsPrefix(zeroPfx) })
+	noPanic(t, "OverlapsIn", func() { tbl1.OverlapsIn(zeroPfx, tbl2) })
 	noPanic(t, "Size", func() { tbl1.Size() })
 	noPanic(t, "Size4", func() { tbl1.Size4() })
 	noPanic(t, "Size6", func() { tbl1.Size6() })
 	noPanic(t, "Subnets", func() { tbl1.Subnets(zeroPfx) })
 	noPanic(t, "Supernets", func() { tbl1.Supernets(zeroPfx) })
+	noPanic(t, "WouldShadow", func() { tbl1.WouldShadow(zeroPfx) })
+	noPanic(t, "WouldBeShadowedBy", func() { tbl1.WouldBeShadowedBy(zeroPfx) })
 	noPanic(t, "Union", func() { tbl1.Union(tbl2) })
 	noPanic(t, "UnionPersist", func() { tbl1.UnionPersist(tbl2) })
+	noPanic(t, "ReplaceRoot4Persist", func() { tbl1.ReplaceRoot4Persist(tbl2) })
+	noPanic(t, "ReplaceRoot6Persist", func() { tbl1.ReplaceRoot6Persist(nil) })
 }
 
 func TestTableContainsCompare_liteTable(t *testing.T) {
@@ -232,6 +297,96 @@ func TestTableLookupCompare_liteTable(t *testing.T) {
 	}
 }
 
+func TestTableLookupPairCompare_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	gold := new(golden.Table[int])
+	tbl := new(liteTable[int])
+
+	for i, pfx := range pfxs {
+		gold.Insert(pfx, i)
+		tbl.Insert(pfx, i)
+	}
+
+	// Skip value comparison for liteTable (no real payload)
+	_, isLite := any(tbl).(*liteTable[int])
+
+	for range n {
+		ip4 := random.IP4(prng)
+		ip6 := random.IP6(prng)
+
+		goldVal4, goldOK4 := gold.Lookup(ip4)
+		goldVal6, goldOK6 := gold.Lookup(ip6)
+
+		val4, ok4, val6, ok6 := tbl.LookupPair(ip4, ip6)
+		if ok4 != goldOK4 || ok6 != goldOK6 {
+			t.Fatalf("LookupPair(%s, %s) = (_, %v, _, %v), want (_, %v, _, %v)", ip4, ip6, ok4, ok6, goldOK4, goldOK6)
+		}
+		if !isLite && (val4 != goldVal4 || val6 != goldVal6) {
+			t.Fatalf("LookupPair(%s, %s) = (%v, _, %v, _), want (%v, _, %v, _)", ip4, ip6, val4, val6, goldVal4, goldVal6)
+		}
+
+		ok4, ok6 = tbl.ContainsPair(ip4, ip6)
+		if ok4 != goldOK4 || ok6 != goldOK6 {
+			t.Fatalf("ContainsPair(%s, %s) = (%v, %v), want (%v, %v)", ip4, ip6, ok4, ok6, goldOK4, goldOK6)
+		}
+	}
+
+	// single-stack host
+	var zeroIP netip.Addr
+	if _, ok4, _, ok6 := tbl.LookupPair(zeroIP, zeroIP); ok4 || ok6 {
+		t.Fatal("LookupPair with invalid addresses, expected not found")
+	}
+}
+
+func TestTableLookupBatch_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	tbl := new(liteTable[int])
+	for i, pfx := range random.RealWorldPrefixes(prng, n) {
+		tbl.Insert(pfx, i)
+	}
+
+	ips := make([]netip.Addr, n)
+	for i := range ips {
+		ips[i] = random.IP(prng)
+	}
+	ips = append(ips, netip.Addr{})
+
+	out := make([]int, len(ips))
+	ok := make([]bool, len(ips))
+	tbl.LookupBatch(ips, out, ok)
+
+	for i, ip := range ips {
+		wantVal, wantOK := tbl.Lookup(ip)
+		if out[i] != wantVal || ok[i] != wantOK {
+			t.Fatalf("LookupBatch, %s = (%v, %v), want (%v, %v)", ip, out[i], ok[i], wantVal, wantOK)
+		}
+	}
+
+	// out too short
+	mustPanic(t, "LookupBatch", func() { tbl.LookupBatch(ips, out[:1], ok) })
+
+	contains := make([]bool, len(ips))
+	tbl.ContainsBatch(ips, contains)
+
+	for i, ip := range ips {
+		if want := tbl.Contains(ip); contains[i] != want {
+			t.Fatalf("ContainsBatch, %s = %v, want %v", ip, contains[i], want)
+		}
+	}
+
+	mustPanic(t, "ContainsBatch", func() { tbl.ContainsBatch(ips, contains[:1]) })
+}
+
 func TestTableLookupPrefixUnmasked_liteTable(t *testing.T) {
 	// test that the pfx must not be masked on input for LookupPrefix
 	t.Parallel()
@@ -439,75 +594,1172 @@ func TestTableInsertPersistShuffled_liteTable(t *testing.T) {
 		tbl1 := new(liteTable[string])
 		tbl2 := new(liteTable[string])
 
-		// bart1 is mutable
-		for _, pfx := range pfxs {
-			tbl1.Insert(pfx, pfx.String())
-		}
+		// bart1 is mutable
+		for _, pfx := range pfxs {
+			tbl1.Insert(pfx, pfx.String())
+		}
+
+		// bart2 is persistent
+		for _, pfx := range pfxs2 {
+			tbl2 = tbl2.InsertPersist(pfx, pfx.String())
+		}
+
+		if tbl1.dumpString() != tbl2.dumpString() {
+			t.Fatal("mutable and immutable table have different dumpString representation")
+		}
+
+		if !tbl1.Equal(tbl2) {
+			t.Fatal("expected Equal")
+		}
+	}
+}
+
+func TestTableDeleteCompare_liteTable(t *testing.T) {
+	// Create large route tables repeatedly, delete half of their
+	// prefixes, and compare Table's behavior to a naive and slow but
+	// correct implementation.
+	t.Parallel()
+
+	var (
+		n            = workLoadN()
+		prng         = rand.New(rand.NewPCG(42, 42))
+		numPrefixes  = n // total prefixes to insert (test deletes 50% of them)
+		numPerFamily = numPrefixes / 2
+		deleteCut    = numPerFamily / 2
+		probes       = 3
+	)
+
+	for range probes {
+		all4 := random.RealWorldPrefixes4(prng, numPerFamily)
+		all6 := random.RealWorldPrefixes6(prng, numPerFamily)
+
+		pfxs := slices.Concat(all4, all6)
+		toDelete := slices.Concat(all4[deleteCut:], all6[deleteCut:])
+
+		gold := new(golden.Table[string])
+		tbl := new(liteTable[string])
+
+		for _, pfx := range pfxs {
+			gold.Insert(pfx, pfx.String())
+			tbl.Insert(pfx, pfx.String())
+		}
+
+		for _, pfx := range toDelete {
+			gold.Delete(pfx)
+			tbl.Delete(pfx)
+		}
+
+		gold.Sort()
+
+		tblFlat := tbl.flatSorted()
+
+		// Skip value comparison for liteTable (no real payload)
+		if _, isLite := any(tbl).(*liteTable[string]); isLite {
+			if !slices.Equal(gold.AllSorted(), tblFlat.AllSorted()) {
+				t.Fatal("expected Equal")
+			}
+		} else {
+			if !slices.Equal(*gold, tblFlat) {
+				t.Fatal("expected Equal")
+			}
+		}
+	}
+}
+
+func TestTableGetAndDeleteCompare_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	gold := new(golden.Table[string])
+	tbl := new(liteTable[string])
+	for _, pfx := range pfxs {
+		gold.Insert(pfx, pfx.String())
+		tbl.Insert(pfx, pfx.String())
+	}
+
+	// delete existing and non-existing prefixes
+	toDelete := append(pfxs[:n/2:n/2], random.RealWorldPrefixes(prng, n/2)...)
+
+	for _, pfx := range toDelete {
+		goldVal, goldOK := gold.Get(pfx)
+		gold.Delete(pfx)
+
+		tblVal, tblOK := tbl.GetAndDelete(pfx)
+
+		if goldOK != tblOK {
+			t.Fatalf("GetAndDelete(%s) = (_, %v), want (_, %v)", pfx, tblOK, goldOK)
+		}
+
+		// Skip value comparison for liteTable (no real payload)
+		if _, isLite := any(tbl).(*liteTable[string]); !isLite {
+			if goldVal != tblVal {
+				t.Fatalf("GetAndDelete(%s) = (%v, %v), want (%v, %v)", pfx, tblVal, tblOK, goldVal, goldOK)
+			}
+		}
+
+		if _, ok := tbl.Get(pfx); ok {
+			t.Fatalf("GetAndDelete(%s), prefix still in table", pfx)
+		}
+	}
+
+	if tbl.Size() != len(*gold) {
+		t.Fatalf("Size, got: %d, want: %d", tbl.Size(), len(*gold))
+	}
+}
+
+func TestTableClear_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(liteTable[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	clone := tbl.Clone()
+	persist := tbl.InsertPersist(mpp("0.0.0.0/0"), -1)
+
+	tbl.Clear()
+
+	if tbl.Size() != 0 || tbl.Size4() != 0 || tbl.Size6() != 0 {
+		t.Fatalf("Clear, Size is not zero: %d", tbl.Size())
+	}
+
+	for _, pfx := range pfxs {
+		if _, ok := tbl.Get(pfx); ok {
+			t.Fatalf("Clear, Get(%s) still found", pfx)
+		}
+		if tbl.Contains(pfx.Addr()) {
+			t.Fatalf("Clear, Contains(%s) still true", pfx.Addr())
+		}
+	}
+
+	if clone.Size() != len(pfxs) || persist.Size() != len(pfxs)+1 {
+		t.Fatalf("Clear modified derived tables, Size: %d, %d", clone.Size(), persist.Size())
+	}
+
+	// the cleared table is reusable
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	if !tbl.Equal(clone) {
+		t.Fatal("reinsert after Clear, expected equal to clone")
+	}
+}
+
+func TestTableFilter_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(liteTable[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	clone := tbl.Clone()
+
+	tests := []struct {
+		name string
+		pred func(netip.Prefix, int) bool
+	}{
+		{"none", func(netip.Prefix, int) bool { return false }},
+		{"all", func(netip.Prefix, int) bool { return true }},
+		{"few", func(pfx netip.Prefix, _ int) bool { return pfx.Bits()%8 == 0 }},
+		{"most", func(pfx netip.Prefix, _ int) bool { return pfx.Bits()%8 != 0 }},
+		{"v4", func(pfx netip.Prefix, _ int) bool { return pfx.Addr().Is4() }},
+	}
+
+	for _, tt := range tests {
+		want := new(liteTable[int])
+		for pfx, val := range tbl.All() {
+			if tt.pred(pfx, val) {
+				want.Insert(pfx, val)
+			}
+		}
+
+		got := tbl.Filter(tt.pred)
+
+		if got.Size() != want.Size() || got.Size4() != want.Size4() {
+			t.Fatalf("Filter(%s), Size, got: %d, want: %d", tt.name, got.Size(), want.Size())
+		}
+		if !got.Equal(want) {
+			t.Fatalf("Filter(%s), result differs from expected table", tt.name)
+		}
+
+		// result is independent of the receiver
+		got.Insert(mpp("0.0.0.0/0"), -1)
+		if _, ok := tbl.Get(mpp("0.0.0.0/0")); ok {
+			t.Fatalf("Filter(%s), result shares nodes with the receiver", tt.name)
+		}
+	}
+
+	if !tbl.Equal(clone) {
+		t.Fatal("Filter modified the receiver")
+	}
+}
+
+func TestTableSetOps_liteTable(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	// zero values, the set operations are checked by prefix,
+	// the value merging is tested for Table in setops_test.go
+	a, b := new(liteTable[int]), new(liteTable[int])
+	for i, pfx := range random.RealWorldPrefixes(prng, workLoadN()) {
+		if i%3 != 1 {
+			a.Insert(pfx, 0)
+		}
+		if i%3 != 0 {
+			b.Insert(pfx, 0)
+		}
+	}
+	aClone, bClone := a.Clone(), b.Clone()
+
+	wantInter, wantDiff := new(liteTable[int]), new(liteTable[int])
+	wantUnion := b.Clone()
+	for pfx := range a.All() {
+		if _, ok := b.Get(pfx); ok {
+			wantInter.Insert(pfx, 0)
+		} else {
+			wantDiff.Insert(pfx, 0)
+		}
+		wantUnion.Insert(pfx, 0)
+	}
+
+	if got := a.Intersect(b, nil); !got.Equal(wantInter) {
+		t.Fatal("Intersect, result differs from expected table")
+	}
+	if got := a.Difference(b); !got.Equal(wantDiff) {
+		t.Fatal("Difference, result differs from expected table")
+	}
+
+	onlyA, onlyB := a.SymmetricDifference(b)
+	if !onlyA.Equal(wantDiff) || !onlyB.Equal(b.Difference(a)) {
+		t.Fatal("SymmetricDifference, expected the differences in both directions")
+	}
+	if onlyB.Size()+wantInter.Size() != b.Size() {
+		t.Fatalf("SymmetricDifference, Size, got: %d, want: %d", onlyB.Size(), b.Size()-wantInter.Size())
+	}
+
+	if !a.Equal(aClone) || !b.Equal(bClone) {
+		t.Fatal("set operations modified their operands")
+	}
+
+	a.UnionWith(b, func(existing, _ int) int { return existing })
+	if !a.Equal(wantUnion) {
+		t.Fatal("UnionWith, result differs from expected table")
+	}
+}
+
+func TestTableInsertBulk_liteTable(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, workLoadN())
+
+	var entries []PrefixValue[int]
+	for i, pfx := range pfxs {
+		entries = append(entries, PrefixValue[int]{Prefix: pfx, Value: i})
+	}
+
+	// duplicates, the last one wins, and invalid prefixes
+	entries = append(entries,
+		PrefixValue[int]{Prefix: pfxs[0], Value: -1},
+		PrefixValue[int]{Prefix: netip.Prefix{}, Value: -2},
+	)
+
+	gold := new(liteTable[int])
+	for _, e := range entries {
+		gold.Insert(e.Prefix, e.Value)
+	}
+
+	// shuffled input
+	shuffled := slices.Clone(entries[:len(pfxs)])
+	prng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	shuffled = append(shuffled, entries[len(pfxs):]...)
+
+	tbl := new(liteTable[int])
+	tbl.InsertBulk(shuffled)
+
+	if err := tbl.Validate(); err != nil {
+		t.Fatalf("InsertBulk, invalid table: %v", err)
+	}
+	if tbl.Size4() != gold.Size4() || tbl.Size6() != gold.Size6() {
+		t.Fatalf("InsertBulk, got size: %d/%d, want: %d/%d", tbl.Size4(), tbl.Size6(), gold.Size4(), gold.Size6())
+	}
+	if !tbl.Equal(gold) {
+		t.Fatal("InsertBulk, not equal to table with single inserts")
+	}
+	if !reflect.DeepEqual(tbl.Dump(), gold.Dump()) {
+		t.Fatal("InsertBulk, trie differs from table with single inserts")
+	}
+
+	// bulk insert into a non-empty table
+	half := len(shuffled) / 2
+
+	tbl = new(liteTable[int])
+	for _, e := range shuffled[:half] {
+		tbl.Insert(e.Prefix, e.Value)
+	}
+	tbl.InsertBulk(shuffled[half:])
+
+	if err := tbl.Validate(); err != nil {
+		t.Fatalf("InsertBulk, non-empty table, invalid table: %v", err)
+	}
+	if !tbl.Equal(gold) || tbl.Size() != gold.Size() {
+		t.Fatal("InsertBulk, non-empty table, not equal to table with single inserts")
+	}
+}
+
+func TestTableCloneSubtree_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(liteTable[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	clone := tbl.Clone()
+
+	scopes := []netip.Prefix{
+		mpp("0.0.0.0/0"),
+		mpp("::/0"),
+		mpp("2000::/3"),
+		pfxs[0],
+		pfxs[1],
+	}
+	for _, pfx := range pfxs[:n/10] {
+		scopes = append(scopes, netip.PrefixFrom(pfx.Addr(), pfx.Bits()/2).Masked())
+		scopes = append(scopes, netip.PrefixFrom(pfx.Addr(), pfx.Bits()&^7).Masked())
+		scopes = append(scopes, netip.PrefixFrom(pfx.Addr(), pfx.Addr().BitLen()))
+		scopes = append(scopes, random.Prefix(prng))
+	}
+
+	for _, scope := range scopes {
+		want := new(liteTable[int])
+		for pfx, val := range tbl.All() {
+			if scope.Overlaps(pfx) && scope.Bits() <= pfx.Bits() {
+				want.Insert(pfx, val)
+			}
+		}
+
+		got := tbl.CloneSubtree(scope)
+
+		if got.Size() != want.Size() || got.Size4() != want.Size4() {
+			t.Fatalf("CloneSubtree(%s), Size, got: %d, want: %d", scope, got.Size(), want.Size())
+		}
+		if !got.Equal(want) {
+			t.Fatalf("CloneSubtree(%s), result differs from expected table", scope)
+		}
+		if err := got.Validate(); err != nil {
+			t.Fatalf("CloneSubtree(%s), invalid trie: %v", scope, err)
+		}
+
+		// result is independent of the receiver
+		for pfx := range got.All() {
+			got.Delete(pfx)
+		}
+		if !tbl.Equal(clone) {
+			t.Fatalf("CloneSubtree(%s), result shares nodes with the receiver", scope)
+		}
+	}
+
+	if got := tbl.CloneSubtree(netip.Prefix{}); got.Size() != 0 {
+		t.Fatalf("CloneSubtree(invalid), Size, got: %d, want: 0", got.Size())
+	}
+}
+
+func TestTableAppendEntries_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(liteTable[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	prefix := PrefixValue[int]{Prefix: mpp("0.0.0.0/0"), Value: -1}
+	got := tbl.AppendEntries([]PrefixValue[int]{prefix})
+
+	if len(got) != tbl.Size()+1 || got[0] != prefix {
+		t.Fatalf("AppendEntries, len: %d, want: %d", len(got), tbl.Size()+1)
+	}
+
+	i := 1
+	for pfx, val := range tbl.All() {
+		if got[i].Prefix != pfx || got[i].Value != val {
+			t.Fatalf("AppendEntries[%d], got: %v, want: {%s %v}", i, got[i], pfx, val)
+		}
+		i++
+	}
+}
+
+func TestTableAppendEntriesAllocs_liteTable(t *testing.T) {
+	// AllocsPerRun must not run in parallel tests
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(liteTable[int])
+	for i, pfx := range random.RealWorldPrefixes(prng, 1_000) {
+		tbl.Insert(pfx, i)
+	}
+
+	// no allocations with a pooled slice
+	dst := make([]PrefixValue[int], 0, tbl.Size())
+	allocs := testing.AllocsPerRun(10, func() {
+		dst = tbl.AppendEntries(dst[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("AppendEntries with sufficient capacity, allocs: %v, want: 0", allocs)
+	}
+}
+
+func TestTableLookupAllocs_liteTable(t *testing.T) {
+	// AllocsPerRun must not run in parallel tests
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(liteTable[int])
+	for i, pfx := range random.RealWorldPrefixes(prng, 1_000) {
+		tbl.Insert(pfx, i)
+	}
+
+	ips := make([]netip.Addr, 100)
+	for i := range ips {
+		ips[i] = random.IP(prng)
+	}
+	pfxs := make([]netip.Prefix, len(ips))
+	for i, ip := range ips {
+		pfxs[i] = netip.PrefixFrom(ip, prng.IntN(ip.BitLen()+1))
+	}
+
+	out := make([]int, len(ips))
+	ok := make([]bool, len(ips))
+
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"Contains", func() {
+			for _, ip := range ips {
+				tbl.Contains(ip)
+			}
+		}},
+		{"Lookup", func() {
+			for _, ip := range ips {
+				tbl.Lookup(ip)
+			}
+		}},
+		{"LookupPrefix", func() {
+			for _, pfx := range pfxs {
+				tbl.LookupPrefix(pfx)
+			}
+		}},
+		{"LookupPrefixLPM", func() {
+			for _, pfx := range pfxs {
+				tbl.LookupPrefixLPM(pfx)
+			}
+		}},
+		{"Get", func() {
+			for _, pfx := range pfxs {
+				tbl.Get(pfx)
+			}
+		}},
+		{"LookupBatch", func() { tbl.LookupBatch(ips, out, ok) }},
+		{"ContainsBatch", func() { tbl.ContainsBatch(ips, ok) }},
+	}
+
+	for _, tt := range tests {
+		if allocs := testing.AllocsPerRun(10, tt.fn); allocs != 0 {
+			t.Errorf("%s, allocs: %v, want: 0", tt.name, allocs)
+		}
+	}
+}
+
+func TestTableReplaceRoot_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(liteTable[int])
+	for i, pfx := range random.RealWorldPrefixes(prng, n) {
+		tbl.Insert(pfx, i)
+	}
+
+	feed := new(liteTable[int])
+	for i, pfx := range random.RealWorldPrefixes(prng, n) {
+		feed.Insert(pfx, -i)
+	}
+
+	want := new(liteTable[int])
+	for pfx, val := range feed.All4() {
+		want.Insert(pfx, val)
+	}
+	for pfx, val := range tbl.All6() {
+		want.Insert(pfx, val)
+	}
+
+	tbl.ReplaceRoot4(feed)
+	if !tbl.Equal(want) || tbl.Size4() != feed.Size4() {
+		t.Fatal("ReplaceRoot4, unexpected table content")
+	}
+
+	tbl.ReplaceRoot6(nil)
+	if tbl.Size6() != 0 || tbl.Size() != feed.Size4() {
+		t.Fatalf("ReplaceRoot6(nil), Size6, got: %d, want: 0", tbl.Size6())
+	}
+
+	tbl.ReplaceRoot6(feed)
+	if !tbl.Equal(feed) {
+		t.Fatal("ReplaceRoot4 and ReplaceRoot6, expected equal to feed")
+	}
+}
+
+func TestTableReplaceRootAliasing_liteTable(t *testing.T) {
+	t.Parallel()
+
+	fill := func(tbl *liteTable[int], cidrs ...string) {
+		for i, s := range cidrs {
+			tbl.Insert(mpp(s), i)
+		}
+	}
+
+	from := new(liteTable[int])
+	fill(from, "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "192.168.0.0/16", "2001:db8::/32")
+	fromWant := from.Clone()
+
+	tbl := new(liteTable[int])
+	fill(tbl, "172.16.0.0/12", "2001:db8:1::/48")
+	tbl.ReplaceRoot4(from)
+	tbl.ReplaceRoot6(from)
+	tblWant := tbl.Clone()
+
+	// mutate both tables after the swap, at the root level and deeper
+	tbl.Insert(mpp("11.0.0.0/8"), 100)
+	tbl.Insert(mpp("10.1.3.0/24"), 101)
+	tbl.Delete(mpp("10.1.2.0/24"))
+	tbl.Insert(mpp("2001:db8:2::/48"), 102)
+
+	from.Insert(mpp("12.0.0.0/8"), 200)
+	from.Insert(mpp("192.168.1.0/24"), 201)
+	from.Delete(mpp("10.1.0.0/16"))
+	from.Delete(mpp("2001:db8::/32"))
+
+	if _, ok := from.Get(mpp("11.0.0.0/8")); ok {
+		t.Error("ReplaceRoot4, insert into tbl is visible in from")
+	}
+	if _, ok := from.Get(mpp("10.1.2.0/24")); !ok {
+		t.Error("ReplaceRoot4, delete in tbl is visible in from")
+	}
+	if _, ok := tbl.Get(mpp("12.0.0.0/8")); ok {
+		t.Error("ReplaceRoot4, insert into from is visible in tbl")
+	}
+	if _, ok := tbl.Get(mpp("2001:db8::/32")); !ok {
+		t.Error("ReplaceRoot6, delete in from is visible in tbl")
+	}
+	if tbl.Size() != 7 || from.Size() != 5 {
+		t.Errorf("Size, got: (%d, %d), want: (7, 5)", tbl.Size(), from.Size())
+	}
+
+	// the persistent variants leave both input tables untouched
+	tbl, from = tblWant.Clone(), fromWant.Clone()
+	from.Insert(mpp("2001:db8:ffff::/48"), 300)
+
+	pt := tbl.ReplaceRoot4Persist(nil).ReplaceRoot6Persist(from)
+	if pt.Size4() != 0 || pt.Size6() != from.Size6() {
+		t.Errorf("ReplaceRootPersist, got sizes: (%d, %d), want: (0, %d)", pt.Size4(), pt.Size6(), from.Size6())
+	}
+	pt = pt.InsertPersist(mpp("2001:db8:ffff:1::/64"), 301)
+	pt = pt.ReplaceRoot4Persist(tbl).InsertPersist(mpp("10.2.0.0/16"), 302)
+
+	if !tbl.Equal(tblWant) {
+		t.Error("ReplaceRootPersist, receiver modified")
+	}
+	if _, ok := from.Get(mpp("2001:db8:ffff:1::/64")); ok {
+		t.Error("ReplaceRoot6Persist, insert into result is visible in from")
+	}
+	if _, ok := pt.Get(mpp("10.2.0.0/16")); !ok || pt.Size() != tbl.Size4()+from.Size6()+2 {
+		t.Errorf("ReplaceRootPersist, unexpected table content, size: %d", pt.Size())
+	}
+}
+
+func TestTableResetValues_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(liteTable[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	statsBefore := tbl.root4.StatsRec()
+	statsBefore6 := tbl.root6.StatsRec()
+
+	tbl.ResetValues(func(pfx netip.Prefix) int { return pfx.Bits() })
+
+	if tbl.root4.StatsRec() != statsBefore || tbl.root6.StatsRec() != statsBefore6 {
+		t.Fatal("ResetValues changed the trie structure")
+	}
+
+	// Skip value comparison for liteTable (no real payload)
+	_, isLite := any(tbl).(*liteTable[int])
+
+	if tbl.Size() != len(pfxs) {
+		t.Fatalf("ResetValues, Size, got: %d, want: %d", tbl.Size(), len(pfxs))
+	}
+
+	for pfx, val := range tbl.All() {
+		if !isLite && val != pfx.Bits() {
+			t.Fatalf("ResetValues, %s, got: %d, want: %d", pfx, val, pfx.Bits())
+		}
+	}
+
+	tbl.Fill(42)
+
+	for _, pfx := range pfxs {
+		val, ok := tbl.Get(pfx)
+		if !ok {
+			t.Fatalf("Fill, %s not found", pfx)
+		}
+		if !isLite && val != 42 {
+			t.Fatalf("Fill, %s, got: %d, want: 42", pfx, val)
+		}
+	}
+}
+
+func TestTableStats_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(liteTable[int])
+	if tbl.Stats() != (Stats{}) {
+		t.Fatalf("Stats of empty table, got: %+v", tbl.Stats())
+	}
+
+	for i, pfx := range random.RealWorldPrefixes(prng, n) {
+		tbl.Insert(pfx, i)
+	}
+
+	stats := tbl.Stats()
+
+	for _, tt := range []struct {
+		got  FamilyStats
+		size int
+		want nodes.StatsT
+	}{
+		{stats.IPv4, tbl.Size4(), tbl.root4.StatsRec()},
+		{stats.IPv6, tbl.Size6(), tbl.root6.StatsRec()},
+	} {
+		if tt.got.Size != tt.size {
+			t.Errorf("Stats, Size, got: %d, want: %d", tt.got.Size, tt.size)
+		}
+		if tt.got.Nodes != tt.want.SubNodes || tt.got.Prefixes != tt.want.Prefixes ||
+			tt.got.Children != tt.want.Children || tt.got.Leaves != tt.want.Leaves ||
+			tt.got.Fringes != tt.want.Fringes {
+			t.Errorf("Stats, got: %+v, want: %+v", tt.got, tt.want)
+		}
+
+		// every stored prefix is either in an inner node, a leaf or a fringe
+		if tt.got.Prefixes+tt.got.Leaves+tt.got.Fringes != tt.got.Size {
+			t.Errorf("Stats, prefixes+leaves+fringes != size: %+v", tt.got)
+		}
+	}
+}
+
+func TestTableStatsDepth_liteTable(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(liteTable[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("10.1.2.0/24"), 3)
+	tbl.Insert(mpp("2001:db8::/32"), 4)
+
+	// 10.0.0.0/8 at level 2, 10.1.0.0/16 and the fringe 10.1.2.0/24 at level 3
+	want4 := FamilyStats{
+		Size: 3, Nodes: 3, Prefixes: 2, Children: 3, Leaves: 0, Fringes: 1,
+		MaxDepth:      3,
+		AvgDepth:      8.0 / 3.0,
+		NodesPerLevel: [maxTreeDepth]int{1, 1, 1},
+		AvgPrefixes:   2.0 / 3.0,
+		AvgChildren:   1,
+	}
+
+	// the leaf 2001:db8::/32 in the root node
+	want6 := FamilyStats{
+		Size: 1, Nodes: 1, Prefixes: 0, Children: 1, Leaves: 1, Fringes: 0,
+		MaxDepth:      1,
+		AvgDepth:      1,
+		NodesPerLevel: [maxTreeDepth]int{1},
+		AvgPrefixes:   0,
+		AvgChildren:   1,
+	}
+
+	stats := tbl.Stats()
+	if stats.IPv4 != want4 {
+		t.Errorf("Stats, IPv4\ngot:  %+v\nwant: %+v", stats.IPv4, want4)
+	}
+	if stats.IPv6 != want6 {
+		t.Errorf("Stats, IPv6\ngot:  %+v\nwant: %+v", stats.IPv6, want6)
+	}
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	for i, pfx := range random.RealWorldPrefixes(prng, workLoadN()) {
+		tbl.Insert(pfx, i)
+	}
+
+	stats = tbl.Stats()
+	for _, s := range []FamilyStats{stats.IPv4, stats.IPv6} {
+		var sum, levels int
+		for level, cnt := range s.NodesPerLevel {
+			sum += cnt
+			if cnt > 0 {
+				levels = level + 1
+			}
+		}
+
+		if sum != s.Nodes {
+			t.Errorf("Stats, sum of NodesPerLevel %d, want Nodes %d", sum, s.Nodes)
+		}
+		if levels != s.MaxDepth {
+			t.Errorf("Stats, MaxDepth %d, want %d", s.MaxDepth, levels)
+		}
+		if s.AvgDepth < 1 || s.AvgDepth > float64(s.MaxDepth) {
+			t.Errorf("Stats, AvgDepth %f out of range [1, %d]", s.AvgDepth, s.MaxDepth)
+		}
+		if s.AvgPrefixes != float64(s.Prefixes)/float64(s.Nodes) ||
+			s.AvgChildren != float64(s.Children)/float64(s.Nodes) {
+			t.Errorf("Stats, averages per node: %+v", s)
+		}
+	}
+}
+
+func TestTableCompact_liteTable(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, workLoadN())
+
+	tbl := new(liteTable[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	// delete most of the table
+	for _, pfx := range pfxs[len(pfxs)/10:] {
+		tbl.Delete(pfx)
+	}
+	want := tbl.Clone()
+
+	reclaimed := tbl.Compact()
+	if reclaimed < 0 {
+		t.Fatalf("Compact, got negative reclaimed bytes: %d", reclaimed)
+	}
+
+	// the sparse slices keep their capacity after deletes
+	if _, isFast := any(tbl).(*Fast[int]); !isFast && reclaimed == 0 {
+		t.Fatal("Compact, expected reclaimed bytes after heavy deletion")
+	}
+
+	if err := tbl.Validate(); err != nil {
+		t.Fatalf("Compact, invalid table: %v", err)
+	}
+	if !tbl.Equal(want) || tbl.Size() != want.Size() {
+		t.Fatal("Compact, table content changed")
+	}
+	if got := tbl.Compact(); got != 0 {
+		t.Fatalf("Compact, second call, got: %d, want: 0", got)
+	}
+
+	// collapse an inner node with a single prefix, bypassing the
+	// compression of Delete
+	tbl = new(liteTable[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+
+	kid, _ := tbl.root4.GetChild(10)
+	kid.(interface{ DeleteChild(uint8) bool }).DeleteChild(1)
+	tbl.size4--
+
+	tbl.Compact()
+
+	if got := tbl.Stats().IPv4; got.Nodes != 1 || got.Fringes != 1 {
+		t.Fatalf("Compact, got nodes: %d, fringes: %d, want: 1, 1", got.Nodes, got.Fringes)
+	}
+	if err := tbl.Validate(); err != nil {
+		t.Fatalf("Compact, invalid table: %v", err)
+	}
+	_, isLite := any(tbl).(*liteTable[int])
+	if val, ok := tbl.Get(mpp("10.0.0.0/8")); !ok || (!isLite && val != 1) {
+		t.Fatalf("Compact, Get(10.0.0.0/8) = (%d, %v), want (1, true)", val, ok)
+	}
+}
+
+func TestTableMemoryFootprint_liteTable(t *testing.T) {
+	t.Parallel()
+
+	var nilTbl *liteTable[int]
+	if got := nilTbl.MemoryFootprint(); got != 0 {
+		t.Fatalf("MemoryFootprint, nil table, got: %d, want: 0", got)
+	}
+
+	tbl := new(liteTable[int])
+
+	// the two root nodes
+	empty := tbl.MemoryFootprint()
+	if empty <= 0 {
+		t.Fatalf("MemoryFootprint, empty table, got: %d", empty)
+	}
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, workLoadN())
+
+	last := empty
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+
+		// slices may grow in steps, but never shrink on insert
+		got := tbl.MemoryFootprint()
+		if got < last {
+			t.Fatalf("MemoryFootprint, Insert(%s), shrunk from %d to %d", pfx, last, got)
+		}
+		last = got
+	}
+
+	// at least the values and one pointer per entry
+	if minBytes := empty + int64(tbl.Size()*16); last < minBytes {
+		t.Fatalf("MemoryFootprint, got: %d, want at least: %d", last, minBytes)
+	}
+
+	for _, pfx := range pfxs {
+		tbl.Delete(pfx)
+	}
+	if got := tbl.MemoryFootprint(); got > last {
+		t.Fatalf("MemoryFootprint after delete, got: %d, want at most: %d", got, last)
+	}
+}
+
+func TestTableValidate_liteTable(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(liteTable[int])
+	if err := tbl.Validate(); err != nil {
+		t.Fatalf("Validate, empty table: %v", err)
+	}
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, workLoadN())
+
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+	if err := tbl.Validate(); err != nil {
+		t.Fatalf("Validate after Insert: %v", err)
+	}
+
+	for _, pfx := range pfxs[:len(pfxs)/2] {
+		tbl.Delete(pfx)
+	}
+	if err := tbl.Validate(); err != nil {
+		t.Fatalf("Validate after Delete: %v", err)
+	}
+
+	t.Run("size", func(t *testing.T) {
+		t.Parallel()
+
+		c := tbl.Clone()
+		c.size6++
+		if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "IPv6 trie: size") {
+			t.Fatalf("Validate, wrong size, got: %v", err)
+		}
+	})
+
+	t.Run("leaf", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			pfx     netip.Prefix
+			addr    uint8
+			wantErr string
+		}{
+			{mpp("192.168.1.0/24"), 10, "wrong child slot"},
+			{netip.PrefixFrom(mpa("192.168.1.1"), 24), 192, "not masked"},
+			{mpp("192.0.0.0/8"), 192, "too short for a leaf"},
+			{mpp("2001:db8::/32"), 0x20, "invalid prefix"},
+		}
+
+		for _, tt := range tests {
+			c := new(liteTable[int])
+			c.Insert(mpp("10.0.0.0/8"), 1)
+			c.root4.InsertChild(tt.addr, nodes.NewLeafNode(tt.pfx, 2))
+			c.size4++
+
+			if err := c.Validate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate, leaf %s at %d, got: %v, want: %q", tt.pfx, tt.addr, err, tt.wantErr)
+			}
+		}
+	})
+}
+
+func TestTableDump_liteTable(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(liteTable[int])
+	if got := tbl.Dump(); got.IPv4 != nil || got.IPv6 != nil {
+		t.Fatalf("Dump, empty table, got: %+v", got)
+	}
+
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("10.1.2.0/24"), 3)
+	tbl.Insert(mpp("10.2.3.4/32"), 4)
+	tbl.Insert(mpp("2001:db8::/32"), 5)
+
+	want := TrieDump{
+		IPv4: &TrieNode{
+			Prefix:   mpp("0.0.0.0/0"),
+			Children: []int{10},
+			Nodes: []TrieNode{{
+				Prefix:   mpp("10.0.0.0/8"),
+				Depth:    1,
+				Indexes:  []int{1},
+				Prefixes: []netip.Prefix{mpp("10.0.0.0/8")},
+				Children: []int{1, 2},
+				Leaves:   []TrieLeaf{{Octet: 2, Prefix: mpp("10.2.3.4/32")}},
+				Nodes: []TrieNode{{
+					Prefix:   mpp("10.1.0.0/16"),
+					Depth:    2,
+					Indexes:  []int{1},
+					Prefixes: []netip.Prefix{mpp("10.1.0.0/16")},
+					Children: []int{2},
+					Fringes:  []TrieLeaf{{Octet: 2, Prefix: mpp("10.1.2.0/24")}},
+				}},
+			}},
+		},
+		IPv6: &TrieNode{
+			Prefix:   mpp("::/0"),
+			Children: []int{0x20},
+			Leaves:   []TrieLeaf{{Octet: 0x20, Prefix: mpp("2001:db8::/32")}},
+		},
+	}
+
+	got := tbl.Dump()
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		t.Fatalf("Dump\ngot:\n%s\nwant:\n%s", gotJSON, wantJSON)
+	}
+
+	// every prefix of the table is in the dump, exactly once
+	prng := rand.New(rand.NewPCG(42, 42))
+	for i, pfx := range random.RealWorldPrefixes(prng, workLoadN()) {
+		tbl.Insert(pfx, i)
+	}
+
+	seen := map[netip.Prefix]int{}
+	var collect func(n *TrieNode)
+	collect = func(n *TrieNode) {
+		if n == nil {
+			return
+		}
+		for _, pfx := range n.Prefixes {
+			seen[pfx]++
+		}
+		for _, leaf := range slices.Concat(n.Leaves, n.Fringes) {
+			seen[leaf.Prefix]++
+		}
+		for i := range n.Nodes {
+			collect(&n.Nodes[i])
+		}
+	}
+
+	got = tbl.Dump()
+	collect(got.IPv4)
+	collect(got.IPv6)
+
+	if len(seen) != tbl.Size() {
+		t.Fatalf("Dump, got %d distinct prefixes, want %d", len(seen), tbl.Size())
+	}
+	for pfx, cnt := range seen {
+		if _, ok := tbl.Get(pfx); !ok || cnt != 1 {
+			t.Fatalf("Dump, prefix %s seen %d times, in table: %v", pfx, cnt, ok)
+		}
+	}
+}
+
+func TestTableLookupTrace_liteTable(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(liteTable[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("10.1.2.0/24"), 3)
+	tbl.Insert(mpp("10.2.3.4/32"), 4)
+
+	tests := []struct {
+		ip   string
+		want []TraceStep
+	}{
+		{
+			ip: "10.1.2.3",
+			want: []TraceStep{
+				{Kind: TraceDescend, Depth: 0, Octet: 10, Node: mpp("0.0.0.0/0")},
+				{Kind: TraceDescend, Depth: 1, Octet: 1, Node: mpp("10.0.0.0/8")},
+				{Kind: TraceFringe, Depth: 2, Octet: 2, Node: mpp("10.1.0.0/16"), Prefix: mpp("10.1.2.0/24"), Hit: true},
+			},
+		},
+		{
+			ip: "10.1.3.3",
+			want: []TraceStep{
+				{Kind: TraceDescend, Depth: 0, Octet: 10, Node: mpp("0.0.0.0/0")},
+				{Kind: TraceDescend, Depth: 1, Octet: 1, Node: mpp("10.0.0.0/8")},
+				{Kind: TraceNoChild, Depth: 2, Octet: 3, Node: mpp("10.1.0.0/16")},
+				{Kind: TraceBacktrack, Depth: 2, Octet: 3, Idx: 129, Node: mpp("10.1.0.0/16"), Prefix: mpp("10.1.0.0/16"), Hit: true},
+			},
+		},
+		{
+			ip: "10.2.9.9",
+			want: []TraceStep{
+				{Kind: TraceDescend, Depth: 0, Octet: 10, Node: mpp("0.0.0.0/0")},
+				{Kind: TraceLeaf, Depth: 1, Octet: 2, Node: mpp("10.0.0.0/8"), Prefix: mpp("10.2.3.4/32")},
+				{Kind: TraceBacktrack, Depth: 1, Octet: 2, Idx: 129, Node: mpp("10.0.0.0/8"), Prefix: mpp("10.0.0.0/8"), Hit: true},
+			},
+		},
+		{
+			ip: "11.0.0.1",
+			want: []TraceStep{
+				{Kind: TraceNoChild, Depth: 0, Octet: 11, Node: mpp("0.0.0.0/0")},
+				{Kind: TraceBacktrack, Depth: 0, Octet: 11, Idx: 133, Node: mpp("0.0.0.0/0")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var got []TraceStep
+		tbl.LookupTrace(mpa(tt.ip), func(s TraceStep) { got = append(got, s) })
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("LookupTrace(%s)\ngot:  %v\nwant: %v", tt.ip, got, tt.want)
+		}
+	}
+
+	// same result as Lookup, the last step reports the hit
+	_, isLite := any(tbl).(*liteTable[int])
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	for i, pfx := range random.RealWorldPrefixes(prng, workLoadN()) {
+		tbl.Insert(pfx, i)
+	}
+
+	for range workLoadN() {
+		ip := random.IP(prng)
+		wantVal, wantOK := tbl.Lookup(ip)
+
+		var steps []TraceStep
+		gotVal, gotOK := tbl.LookupTrace(ip, func(s TraceStep) { steps = append(steps, s) })
 
-		// bart2 is persistent
-		for _, pfx := range pfxs2 {
-			tbl2 = tbl2.InsertPersist(pfx, pfx.String())
+		if isLite {
+			gotVal = wantVal // no payload
 		}
-
-		if tbl1.dumpString() != tbl2.dumpString() {
-			t.Fatal("mutable and immutable table have different dumpString representation")
+		if gotVal != wantVal || gotOK != wantOK {
+			t.Fatalf("LookupTrace(%s) = (%v, %v), want (%v, %v)", ip, gotVal, gotOK, wantVal, wantOK)
 		}
-
-		if !tbl1.Equal(tbl2) {
-			t.Fatal("expected Equal")
+		if len(steps) == 0 || steps[len(steps)-1].Hit != gotOK {
+			t.Fatalf("LookupTrace(%s), unexpected steps: %v", ip, steps)
 		}
 	}
 }
 
-func TestTableDeleteCompare_liteTable(t *testing.T) {
-	// Create large route tables repeatedly, delete half of their
-	// prefixes, and compare Table's behavior to a naive and slow but
-	// correct implementation.
+func TestTableOccupancy_liteTable(t *testing.T) {
 	t.Parallel()
 
-	var (
-		n            = workLoadN()
-		prng         = rand.New(rand.NewPCG(42, 42))
-		numPrefixes  = n // total prefixes to insert (test deletes 50% of them)
-		numPerFamily = numPrefixes / 2
-		deleteCut    = numPerFamily / 2
-		probes       = 3
-	)
+	popcnt := func(b [4]uint64) (cnt int) {
+		for _, w := range b {
+			cnt += bits.OnesCount64(w)
+		}
+		return cnt
+	}
 
-	for range probes {
-		all4 := random.RealWorldPrefixes4(prng, numPerFamily)
-		all6 := random.RealWorldPrefixes6(prng, numPerFamily)
+	tbl := new(liteTable[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("10.1.2.0/24"), 3)
 
-		pfxs := slices.Concat(all4, all6)
-		toDelete := slices.Concat(all4[deleteCut:], all6[deleteCut:])
+	var got []NodeOccupancy
+	for o := range tbl.Occupancy() {
+		got = append(got, o)
+	}
 
-		gold := new(golden.Table[string])
-		tbl := new(liteTable[string])
+	if len(got) == 0 || got[0].Prefix != mpp("0.0.0.0/0") || got[0].Depth != 0 {
+		t.Fatalf("Occupancy, expected the IPv4 root node first, got: %v", got)
+	}
 
-		for _, pfx := range pfxs {
-			gold.Insert(pfx, pfx.String())
-			tbl.Insert(pfx, pfx.String())
-		}
+	prng := rand.New(rand.NewPCG(42, 42))
+	for i, pfx := range random.RealWorldPrefixes(prng, workLoadN()) {
+		tbl.Insert(pfx, i)
+	}
 
-		for _, pfx := range toDelete {
-			gold.Delete(pfx)
-			tbl.Delete(pfx)
+	var sum FamilyStats
+	for o := range tbl.Occupancy() {
+		sum.Nodes++
+		sum.Prefixes += popcnt(o.Prefixes)
+		sum.Children += popcnt(o.Children)
+		sum.Leaves += popcnt(o.Leaves)
+		sum.Fringes += popcnt(o.Fringes)
+
+		if o.Prefix.Bits() != o.Depth*8 {
+			t.Fatalf("Occupancy, node prefix %s at depth %d", o.Prefix, o.Depth)
 		}
+	}
 
-		gold.Sort()
+	stats := tbl.Stats()
+	want := FamilyStats{
+		Nodes:    stats.IPv4.Nodes + stats.IPv6.Nodes,
+		Prefixes: stats.IPv4.Prefixes + stats.IPv6.Prefixes,
+		Children: stats.IPv4.Children + stats.IPv6.Children,
+		Leaves:   stats.IPv4.Leaves + stats.IPv6.Leaves,
+		Fringes:  stats.IPv4.Fringes + stats.IPv6.Fringes,
+	}
 
-		tblFlat := tbl.flatSorted()
+	if sum != want {
+		t.Fatalf("Occupancy, got sum: %+v, want: %+v", sum, want)
+	}
 
-		// Skip value comparison for liteTable (no real payload)
-		if _, isLite := any(tbl).(*liteTable[string]); isLite {
-			if !slices.Equal(gold.AllSorted(), tblFlat.AllSorted()) {
-				t.Fatal("expected Equal")
-			}
-		} else {
-			if !slices.Equal(*gold, tblFlat) {
-				t.Fatal("expected Equal")
-			}
-		}
+	// early exit
+	for range tbl.Occupancy() {
+		break
 	}
 }
 
@@ -972,6 +2224,57 @@ func TestTableModifyCompare_liteTable(t *testing.T) {
 	}
 }
 
+func TestTableUpdateCompare_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	// with duplicates, updates and inserts are mixed
+	pfxs = append(pfxs, pfxs[:n/2]...)
+	prng.Shuffle(len(pfxs), func(i, j int) { pfxs[i], pfxs[j] = pfxs[j], pfxs[i] })
+
+	gold := new(golden.Table[int])
+	tbl := new(liteTable[int])
+
+	cb := func(val int, ok bool) int {
+		if !ok {
+			return 100
+		}
+		return val + 1
+	}
+
+	for _, pfx := range pfxs {
+		goldVal := gold.Update(pfx, cb)
+		tblVal := tbl.Update(pfx, cb)
+
+		// Skip value comparison for liteTable (no real payload)
+		if _, isLite := any(tbl).(*liteTable[int]); isLite {
+			continue
+		}
+
+		if goldVal != tblVal {
+			t.Fatalf("Update(%s), got: %d, want: %d", pfx, tblVal, goldVal)
+		}
+	}
+
+	gold.Sort()
+	tblFlat := tbl.flatSorted()
+
+	// Skip value comparison for liteTable (no real payload)
+	if _, isLite := any(tbl).(*liteTable[int]); isLite {
+		if !slices.Equal(gold.AllSorted(), tblFlat.AllSorted()) {
+			t.Fatal("expected Equal")
+		}
+	} else {
+		if !slices.Equal(*gold, tblFlat) {
+			t.Fatal("expected Equal")
+		}
+	}
+}
+
 func TestTableModifyPersistCompare_liteTable(t *testing.T) {
 	t.Parallel()
 
@@ -1520,6 +2823,74 @@ func TestTableOverlapsPrefixEdgeCases_liteTable(t *testing.T) {
 	}
 }
 
+func TestTableOverlapsInCompare_liteTable(t *testing.T) {
+	t.Parallel()
+
+	// naive and slow but correct: the intersection of two overlapping
+	// prefixes is the more specific one, it must overlap the region.
+	naive := func(a, b []netip.Prefix, region netip.Prefix) bool {
+		for _, pa := range a {
+			for _, pb := range b {
+				if !pa.Overlaps(pb) {
+					continue
+				}
+				inter := pa
+				if pb.Bits() > pa.Bits() {
+					inter = pb
+				}
+				if inter.Overlaps(region) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	for range 100 {
+		pfxsA := random.RealWorldPrefixes(prng, 20)
+		pfxsB := random.RealWorldPrefixes(prng, 20)
+
+		ta := new(liteTable[int])
+		tb := new(liteTable[int])
+		for _, pfx := range pfxsA {
+			ta.Insert(pfx, 0)
+		}
+		for _, pfx := range pfxsB {
+			tb.Insert(pfx, 0)
+		}
+
+		regions := make([]netip.Prefix, 0, 140)
+		for range 100 {
+			region := random.Prefix(prng)
+			// bias the regions towards short prefixes, otherwise
+			// nearly all checks end with no overlap
+			region, _ = region.Addr().Prefix(region.Bits() / 4)
+			regions = append(regions, region)
+		}
+		// regions ending deep inside the tries
+		for _, pfx := range append(pfxsA[:20:20], pfxsB[:20]...) {
+			region, _ := pfx.Addr().Prefix(prng.IntN(pfx.Bits() + 1))
+			regions = append(regions, region)
+		}
+
+		taClone, tbClone := ta.Clone(), tb.Clone()
+
+		for _, region := range regions {
+			want := naive(pfxsA, pfxsB, region)
+			got := ta.OverlapsIn(region, tb)
+			if got != want {
+				t.Fatalf("OverlapsIn(%s), got: %v, want: %v\nA: %v\nB: %v", region, got, want, pfxsA, pfxsB)
+			}
+		}
+
+		if !ta.Equal(taClone) || !tb.Equal(tbClone) {
+			t.Fatal("OverlapsIn, tables modified")
+		}
+	}
+}
+
 func TestTableSize_liteTable(t *testing.T) {
 	t.Parallel()
 
@@ -1891,6 +3262,49 @@ func TestTableSubnetsCompare_liteTable(t *testing.T) {
 	}
 }
 
+func TestTableWouldShadowCompare_liteTable(t *testing.T) {
+	t.Parallel()
+	n := workLoadN()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	gold := new(golden.Table[int])
+	tbl := new(liteTable[int])
+
+	for i, pfx := range pfxs {
+		gold.Insert(pfx, i)
+		tbl.Insert(pfx, i)
+	}
+
+	without := func(list []netip.Prefix, pfx netip.Prefix) []netip.Prefix {
+		return slices.DeleteFunc(list, func(p netip.Prefix) bool { return p == pfx })
+	}
+
+	// existing and new prefixes
+	queries := append(pfxs[:n/2:n/2], random.RealWorldPrefixes(prng, n/2)...)
+
+	for _, pfx := range queries {
+		want := without(gold.Subnets(pfx), pfx)
+		got := []netip.Prefix{}
+		for sub := range tbl.WouldShadow(pfx) {
+			got = append(got, sub)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("WouldShadow(%s) = %v, want %v", pfx, got, want)
+		}
+
+		want = without(gold.Supernets(pfx), pfx)
+		got = []netip.Prefix{}
+		for super := range tbl.WouldBeShadowedBy(pfx) {
+			got = append(got, super)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("WouldBeShadowedBy(%s) = %v, want %v", pfx, got, want)
+		}
+	}
+}
+
 func TestTableSupernetsEdgeCase_liteTable(t *testing.T) {
 	t.Parallel()
 
@@ -1973,6 +3387,44 @@ func TestTableSupernetsCompare_liteTable(t *testing.T) {
 	}
 }
 
+func TestTableLookupAllCompare_liteTable(t *testing.T) {
+	t.Parallel()
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	gold := new(golden.Table[int])
+	tbl := new(liteTable[int])
+
+	for i, pfx := range pfxs {
+		gold.Insert(pfx, i)
+		tbl.Insert(pfx, i)
+	}
+
+	for range n {
+		ip := random.IP(prng)
+
+		gotGold := gold.Supernets(netip.PrefixFrom(ip, ip.BitLen()))
+		gotTbl := []netip.Prefix{}
+
+		for p := range tbl.LookupAll(ip) {
+			gotTbl = append(gotTbl, p)
+		}
+
+		if !slices.Equal(gotGold, gotTbl) {
+			t.Fatalf("LookupAll(%s) = %v, want %v", ip, gotTbl, gotGold)
+		}
+
+		// the first match is the longest prefix match
+		lpm, _, ok := tbl.LookupPrefixLPM(netip.PrefixFrom(ip, ip.BitLen()))
+		if ok != (len(gotTbl) > 0) || ok && lpm != gotTbl[0] {
+			t.Fatalf("LookupAll(%s), first item %v, want LPM %v", ip, gotTbl, lpm)
+		}
+	}
+}
+
 func TestTableMarshalText_liteTable(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -2020,6 +3472,97 @@ func TestTableMarshalText_liteTable(t *testing.T) {
 	}
 }
 
+func TestTableFprintFunc_liteTable(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(liteTable[int])
+	for i, s := range []string{"10.0.0.0/8", "10.0.0.0/24", "10.0.1.0/24", "192.168.0.0/16", "::/0", "2001:db8::/32"} {
+		tbl.Insert(mpp(s), i)
+	}
+
+	_, isLite := any(tbl).(*liteTable[int])
+
+	format := func(pfx netip.Prefix, val int) string {
+		if want, _ := tbl.Get(pfx); !isLite && val != want {
+			t.Errorf("FprintFunc, format(%s), got value %d, want %d", pfx, val, want)
+		}
+		return fmt.Sprintf("bits: %d", pfx.Bits())
+	}
+
+	want := `▼
+├─ 10.0.0.0/8 (bits: 8)
+│  ├─ 10.0.0.0/24 (bits: 24)
+│  └─ 10.0.1.0/24 (bits: 24)
+└─ 192.168.0.0/16 (bits: 16)
+▼
+└─ ::/0 (bits: 0)
+   └─ 2001:db8::/32 (bits: 32)
+`
+
+	w := new(strings.Builder)
+	if err := tbl.FprintFunc(w, format); err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != want {
+		t.Fatalf("FprintFunc\ngot:\n%s\nwant:\n%s", w, want)
+	}
+
+	// nil format is Fprint
+	w1, w2 := new(strings.Builder), new(strings.Builder)
+	if err := tbl.FprintFunc(w1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Fprint(w2); err != nil {
+		t.Fatal(err)
+	}
+	if w1.String() != w2.String() {
+		t.Fatalf("FprintFunc(nil)\ngot:\n%s\nwant:\n%s", w1, w2)
+	}
+
+	if err := tbl.FprintFunc(nil, format); err == nil {
+		t.Fatal("FprintFunc(nil writer), expected error")
+	}
+}
+
+func TestTableString_liteTable(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(liteTable[int])
+
+	want := "IPv4: 0 prefixes, 0 nodes, 0 leaves, 0 fringes, depth 0; " +
+		"IPv6: 0 prefixes, 0 nodes, 0 leaves, 0 fringes, depth 0"
+	if got := tbl.String(); got != want {
+		t.Fatalf("String, empty\ngot:  %s\nwant: %s", got, want)
+	}
+
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("10.1.2.0/24"), 3)
+	tbl.Insert(mpp("2001:db8::/32"), 4)
+
+	want = "IPv4: 3 prefixes, 3 nodes, 0 leaves, 1 fringes, depth 3; " +
+		"IPv6: 1 prefixes, 1 nodes, 1 leaves, 0 fringes, depth 1"
+	if got := fmt.Sprint(tbl); got != want {
+		t.Fatalf("String\ngot:  %s\nwant: %s", got, want)
+	}
+
+	text, err := tbl.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tbl.DumpString(); got != string(text) {
+		t.Fatalf("DumpString\ngot:\n%s\nwant:\n%s", got, text)
+	}
+
+	var nilTbl *liteTable[int]
+	if got := nilTbl.String(); got != "<nil>" {
+		t.Fatalf("String, nil table, got: %s, want: <nil>", got)
+	}
+	if got := nilTbl.DumpString(); got != "" {
+		t.Fatalf("DumpString, nil table, got: %q, want: empty", got)
+	}
+}
+
 func TestTableMarshalJSON_liteTable(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -2072,6 +3615,98 @@ func TestTableMarshalJSON_liteTable(t *testing.T) {
 	}
 }
 
+func TestTableUnmarshalJSON_liteTable(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(liteTable[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	// round trip of the hierarchical format
+	data, err := json.Marshal(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := new(liteTable[int])
+	got.Insert(mpp("0.0.0.0/0"), -1) // replaced by unmarshal
+
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("Unmarshal hierarchical format: %v", err)
+	}
+	if !got.Equal(tbl) {
+		t.Fatal("round trip of the hierarchical format, tables differ")
+	}
+
+	// round trip of the flat format
+	data, err = json.Marshal(tbl.AppendEntries(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = new(liteTable[int])
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("Unmarshal flat format: %v", err)
+	}
+	if !got.Equal(tbl) {
+		t.Fatal("round trip of the flat format, tables differ")
+	}
+
+	// round trip of the flat encoder
+	data, err = tbl.MarshalJSONFlat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []PrefixValue[int]
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("MarshalJSONFlat, not a flat list: %v", err)
+	}
+	if len(entries) != tbl.Size() || !slices.IsSortedFunc(entries, func(a, b PrefixValue[int]) int {
+		return nodes.CmpPrefix(a.Prefix, b.Prefix)
+	}) {
+		t.Fatalf("MarshalJSONFlat, got %d entries, want %d in CIDR sort order", len(entries), tbl.Size())
+	}
+
+	got = new(liteTable[int])
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("Unmarshal MarshalJSONFlat: %v", err)
+	}
+	if !got.Equal(tbl) {
+		t.Fatal("round trip of MarshalJSONFlat, tables differ")
+	}
+
+	if data, _ := new(liteTable[int]).MarshalJSONFlat(); string(data) != "[]" {
+		t.Errorf("MarshalJSONFlat of empty table, got: %s, want: []", data)
+	}
+
+	// errors leave the table unchanged
+	for _, bad := range []string{
+		`[{"prefix":"10.0.0.0/33","value":1}]`,
+		`[{"value":1}]`,
+		`{"ipv4":[{"cidr":"foo"}]}`,
+		`[{"prefix":"10.0.0.0/8","value":"no int"}]`,
+		`"foo"`,
+	} {
+		if err := json.Unmarshal([]byte(bad), got); err == nil {
+			t.Errorf("Unmarshal(%s), expected error", bad)
+		}
+		if !got.Equal(tbl) {
+			t.Fatalf("Unmarshal(%s), failed unmarshal modified the table", bad)
+		}
+	}
+
+	if err := json.Unmarshal([]byte("null"), got); err != nil || !got.Equal(tbl) {
+		t.Fatalf("Unmarshal(null), expected no-op, err: %v", err)
+	}
+}
+
 func TestTableDumpList4_liteTable(t *testing.T) {
 	tests := []struct {
 		name         string
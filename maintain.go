@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// MaintOpts configures the background maintenance of [Maintain].
+//
+// All hooks are optional, nil hooks are skipped. The hooks are
+// called sequentially from the maintenance goroutine, they must
+// synchronize with concurrent table users themselves.
+type MaintOpts struct {
+	// Interval is the base period between maintenance runs, required.
+	Interval time.Duration
+
+	// Jitter is the maximum random delay added to every period,
+	// to avoid synchronized maintenance of many tables.
+	Jitter time.Duration
+
+	// Compact is called to compact or rebuild tables.
+	Compact func()
+
+	// Reap is called to remove expired entries.
+	Reap func()
+
+	// TruncateJournal is called to truncate change journals or histories.
+	TruncateJournal func()
+
+	// Decay is called to decay hit counters.
+	Decay func()
+}
+
+// Maintain runs the maintenance hooks of opts periodically until ctx
+// is done. The hooks run in the order Reap, Compact, TruncateJournal
+// and Decay, the first run starts after one period.
+//
+// Maintain blocks, it is intended to be started in its own goroutine:
+//
+//	go bart.Maintain(ctx, bart.MaintOpts{
+//		Interval: time.Minute,
+//		Jitter:   10 * time.Second,
+//		Decay:    func() { mu.Lock(); hits.Fill(0); mu.Unlock() },
+//	})
+//
+// It returns the context error, or an error for invalid options.
+func Maintain(ctx context.Context, opts MaintOpts) error {
+	if opts.Interval <= 0 {
+		return errors.New("bart: maintenance interval must be positive")
+	}
+	if opts.Jitter < 0 {
+		return errors.New("bart: maintenance jitter must not be negative")
+	}
+
+	hooks := []func(){opts.Reap, opts.Compact, opts.TruncateJournal, opts.Decay}
+
+	timer := time.NewTimer(opts.period())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		for _, hook := range hooks {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if hook != nil {
+				hook()
+			}
+		}
+
+		timer.Reset(opts.period())
+	}
+}
+
+// period returns the interval plus a random jitter.
+func (o MaintOpts) period() time.Duration {
+	if o.Jitter <= 0 {
+		return o.Interval
+	}
+	return o.Interval + rand.N(o.Jitter+1)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMaintain(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	var calls []string
+
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, name)
+			if len(calls) == 6 {
+				cancel()
+			}
+		}
+	}
+
+	opts := MaintOpts{
+		Interval: time.Millisecond,
+		Jitter:   time.Millisecond,
+		Compact:  record("compact"),
+		Reap:     record("reap"),
+		Decay:    record("decay"),
+	}
+
+	err := Maintain(ctx, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Maintain, got err: %v, want: %v", err, context.Canceled)
+	}
+
+	want := []string{"reap", "compact", "decay", "reap", "compact", "decay"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("Maintain, got calls: %v, want: %v", calls, want)
+	}
+}
+
+func TestMaintainInvalid(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	if err := Maintain(ctx, MaintOpts{}); err == nil {
+		t.Error("Maintain with zero interval, expected error")
+	}
+	if err := Maintain(ctx, MaintOpts{Interval: time.Second, Jitter: -1}); err == nil {
+		t.Error("Maintain with negative jitter, expected error")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if err := Maintain(canceled, MaintOpts{Interval: time.Hour}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Maintain with canceled context, got err: %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+
+	"github.com/admpub/bart/internal/nodes"
+)
+
+// MapTable returns a new table with the same prefixes as t and the
+// values converted by fn, e.g. to derive a compact FIB from a RIB.
+//
+// The trie structure is copied verbatim instead of re-inserting every
+// prefix, which is much faster for large tables. The receiver is not
+// modified and shares no nodes with the result.
+func MapTable[V, W any](t *Table[V], fn func(pfx netip.Prefix, val V) W) *Table[W] {
+	if t == nil {
+		return nil
+	}
+
+	return &Table[W]{
+		root4: *nodes.MapRec(&t.root4, nodes.StridePath{}, 0, true, fn),
+		root6: *nodes.MapRec(&t.root6, nodes.StridePath{}, 0, false, fn),
+		size4: t.size4,
+		size6: t.size6,
+	}
+}
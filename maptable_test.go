@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"strconv"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestMapTable(t *testing.T) {
+	t.Parallel()
+
+	if MapTable(nil, func(netip.Prefix, int) string { return "" }) != nil {
+		t.Fatal("MapTable(nil), expected nil")
+	}
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(Table[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	fn := func(pfx netip.Prefix, val int) string {
+		return pfx.String() + "=" + strconv.Itoa(val)
+	}
+
+	got := MapTable(tbl, fn)
+
+	// compare with the slow rebuild
+	want := new(Table[string])
+	for pfx, val := range tbl.All() {
+		want.Insert(pfx, fn(pfx, val))
+	}
+
+	if got.Size4() != want.Size4() || got.Size6() != want.Size6() {
+		t.Fatalf("MapTable, Size, got: %d/%d, want: %d/%d", got.Size4(), got.Size6(), want.Size4(), want.Size6())
+	}
+	if !got.Equal(want) {
+		t.Fatal("MapTable, result differs from rebuilt table")
+	}
+	if got.root4.StatsRec() != tbl.root4.StatsRec() || got.root6.StatsRec() != tbl.root6.StatsRec() {
+		t.Fatal("MapTable, trie structure differs")
+	}
+
+	// lookups see the converted values
+	for range n {
+		ip := random.IP(prng)
+
+		val, ok := tbl.Lookup(ip)
+		gotVal, gotOK := got.Lookup(ip)
+		if ok != gotOK {
+			t.Fatalf("Lookup(%s), got: %v, want: %v", ip, gotOK, ok)
+		}
+		if ok {
+			lpm, _, _ := tbl.LookupPrefixLPM(netip.PrefixFrom(ip, ip.BitLen()))
+			if gotVal != fn(lpm, val) {
+				t.Fatalf("Lookup(%s), got: %q, want: %q", ip, gotVal, fn(lpm, val))
+			}
+		}
+	}
+
+	// no shared nodes
+	got.Insert(mpp("0.0.0.0/0"), "default")
+	got.Delete(pfxs[0])
+	if _, ok := tbl.Get(mpp("0.0.0.0/0")); ok {
+		t.Fatal("MapTable, result shares nodes with the receiver")
+	}
+	if _, ok := tbl.Get(pfxs[0]); !ok {
+		t.Fatal("MapTable, result shares nodes with the receiver")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync/atomic"
+
+	"github.com/admpub/bart"
+)
+
+// Var publishes live table statistics as [expvar.Var], a zero-dependency
+// alternative to the Prometheus [Exporter], e.g. under /debug/vars:
+//
+//	{"ipv4":{"size":2,"nodes":1,...},"ipv6":{...},"memory_bytes":4096,"updates":3,...}
+//
+// The counters are fed by the application, see [Var.ObserveUpdate] and
+// [Var.ObserveLookup]. The same rules for concurrent access to the
+// [Source] apply as for the [Exporter].
+type Var struct {
+	src Source
+
+	updates atomic.Uint64
+	lookups atomic.Uint64
+	hits    atomic.Uint64
+}
+
+// NewVar returns an unpublished Var for the statistics of src.
+func NewVar(src Source) *Var {
+	return &Var{src: src}
+}
+
+// Publish returns a new Var for the statistics of src, published with
+// [expvar.Publish] under name. Like expvar.Publish it panics if the
+// name is already registered.
+func Publish(name string, src Source) *Var {
+	v := NewVar(src)
+	expvar.Publish(name, v)
+	return v
+}
+
+// ObserveUpdate counts a table update, e.g. an insert or delete.
+// It is safe for concurrent use.
+func (v *Var) ObserveUpdate() {
+	v.updates.Add(1)
+}
+
+// ObserveLookup counts a lookup, ok reports whether it had a match.
+// It is safe for concurrent use.
+func (v *Var) ObserveLookup(ok bool) {
+	v.lookups.Add(1)
+	if ok {
+		v.hits.Add(1)
+	}
+}
+
+// String implements the [expvar.Var] interface, the statistics
+// and counters as JSON object.
+func (v *Var) String() string {
+	stats := v.src.Stats()
+
+	var memory int64
+	if ms, ok := v.src.(MemorySource); ok {
+		memory = ms.MemoryFootprint()
+	}
+
+	data, err := json.Marshal(struct {
+		IPv4       bart.FamilyStats `json:"ipv4"`
+		IPv6       bart.FamilyStats `json:"ipv6"`
+		Memory     int64            `json:"memory_bytes,omitempty"`
+		Updates    uint64           `json:"updates"`
+		Lookups    uint64           `json:"lookups"`
+		LookupHits uint64           `json:"lookup_hits"`
+	}{
+		IPv4:       stats.IPv4,
+		IPv6:       stats.IPv6,
+		Memory:     memory,
+		Updates:    v.updates.Load(),
+		Lookups:    v.lookups.Load(),
+		LookupHits: v.hits.Load(),
+	})
+	if err != nil {
+		// unreachable, the struct has only plain integer fields
+		return "{}"
+	}
+
+	return string(data)
+}
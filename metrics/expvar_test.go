@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/netip"
+	"testing"
+
+	"github.com/admpub/bart"
+)
+
+func TestVar(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(bart.Fast[string])
+
+	v := Publish("bart_test_var", tbl)
+	if expvar.Get("bart_test_var") != v {
+		t.Fatal("Publish, Var not registered")
+	}
+
+	for _, s := range []string{"10.0.0.0/8", "192.168.0.0/16", "2001:db8::/32"} {
+		tbl.Insert(mpp(s), s)
+		v.ObserveUpdate()
+	}
+	v.ObserveLookup(tbl.Contains(netip.MustParseAddr("10.1.1.1")))
+	v.ObserveLookup(tbl.Contains(netip.MustParseAddr("::1")))
+
+	var got struct {
+		IPv4       bart.FamilyStats `json:"ipv4"`
+		IPv6       bart.FamilyStats `json:"ipv6"`
+		Memory     int64            `json:"memory_bytes"`
+		Updates    uint64           `json:"updates"`
+		Lookups    uint64           `json:"lookups"`
+		LookupHits uint64           `json:"lookup_hits"`
+	}
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatalf("String, invalid JSON: %v", err)
+	}
+
+	stats := tbl.Stats()
+	if got.IPv4 != stats.IPv4 || got.IPv6 != stats.IPv6 {
+		t.Errorf("String, stats, got %+v %+v, want %+v %+v", got.IPv4, got.IPv6, stats.IPv4, stats.IPv6)
+	}
+	if got.Memory != tbl.MemoryFootprint() {
+		t.Errorf("String, memory_bytes, got %d, want %d", got.Memory, tbl.MemoryFootprint())
+	}
+	if got.Updates != 3 || got.Lookups != 2 || got.LookupHits != 1 {
+		t.Errorf("String, counters, got %d/%d/%d, want 3/2/1", got.Updates, got.Lookups, got.LookupHits)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+// Package metrics exports statistics of bart tables in the Prometheus
+// text exposition format, without a dependency on the Prometheus client
+// library. An [Exporter] can be scraped directly as [http.Handler] or
+// written to a file for the node exporter textfile collector.
+//
+// An Exporter is not a prometheus.Collector, it has no Describe and
+// Collect methods and can't be registered with a prometheus.Registry.
+// Mount it as its own scrape endpoint instead.
+//
+// Exported metrics, with the default namespace "bart":
+//
+//	bart_prefixes{family="ipv4|ipv6"}   gauge    stored prefixes
+//	bart_nodes{family="ipv4|ipv6"}      gauge    inner trie nodes
+//	bart_leaves{family="ipv4|ipv6"}     gauge    path-compressed leaves
+//	bart_fringes{family="ipv4|ipv6"}    gauge    path-compressed fringes
+//	bart_depth{family="ipv4|ipv6"}      gauge    inner trie node levels
+//	bart_memory_bytes                   gauge    estimated heap bytes, see [MemorySource]
+//	bart_lookups_total                  counter  observed lookups
+//	bart_lookup_hits_total              counter  observed lookups with a match
+//
+// The lookup counters are only exported if enabled, they are fed by
+// the application with [Exporter.ObserveLookup].
+//
+// A [Var] publishes the same statistics with [expvar], as JSON object.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/admpub/bart"
+)
+
+// Source provides the statistics of a table, implemented by
+// [bart.Table], [bart.Fast] and [bart.Lite].
+//
+// Stats is called on every scrape, concurrently to the application.
+// Tables updated concurrently must be guarded, e.g. with a [StatsFunc]
+// taking a read lock or loading an atomically swapped table.
+type Source interface {
+	Stats() bart.Stats
+}
+
+// MemorySource is optionally implemented by a [Source],
+// the estimated heap bytes are then exported, see [bart.Table.MemoryFootprint].
+type MemorySource interface {
+	MemoryFootprint() int64
+}
+
+// StatsFunc is an adapter to use an ordinary function as [Source].
+type StatsFunc func() bart.Stats
+
+// Stats returns f().
+func (f StatsFunc) Stats() bart.Stats {
+	return f()
+}
+
+// Exporter exports the statistics of a table.
+type Exporter struct {
+	// Namespace is the prefix of the metric names, "bart" if empty.
+	Namespace string
+
+	// Labels are added to every metric, e.g. {"table": "main"}.
+	Labels map[string]string
+
+	// Lookups enables the export of the lookup counters.
+	Lookups bool
+
+	src Source
+
+	lookups atomic.Uint64
+	hits    atomic.Uint64
+}
+
+// NewExporter returns an exporter for the statistics of src.
+func NewExporter(src Source) *Exporter {
+	return &Exporter{src: src}
+}
+
+// ObserveLookup counts a lookup, ok reports whether it had a match.
+// It is safe for concurrent use, e.g. on the lookup fast path.
+func (e *Exporter) ObserveLookup(ok bool) {
+	e.lookups.Add(1)
+	if ok {
+		e.hits.Add(1)
+	}
+}
+
+// WriteTo implements the [io.WriterTo] interface, it writes all metrics
+// in the Prometheus text exposition format to w.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	ns := e.Namespace
+	if ns == "" {
+		ns = "bart"
+	}
+
+	stats := e.src.Stats()
+
+	gauges := []struct {
+		name, help string
+		v4, v6     int
+	}{
+		{"prefixes", "Number of stored prefixes.", stats.IPv4.Size, stats.IPv6.Size},
+		{"nodes", "Number of inner trie nodes.", stats.IPv4.Nodes, stats.IPv6.Nodes},
+		{"leaves", "Number of path-compressed leaf nodes.", stats.IPv4.Leaves, stats.IPv6.Leaves},
+		{"fringes", "Number of path-compressed fringe nodes.", stats.IPv4.Fringes, stats.IPv6.Fringes},
+		{"depth", "Number of inner trie node levels.", stats.IPv4.MaxDepth, stats.IPv6.MaxDepth},
+	}
+
+	for _, g := range gauges {
+		name := ns + "_" + g.name
+		fmt.Fprintf(cw, "# HELP %s %s\n# TYPE %s gauge\n", name, g.help, name)
+		fmt.Fprintf(cw, "%s%s %d\n", name, e.labels("family", "ipv4"), g.v4)
+		fmt.Fprintf(cw, "%s%s %d\n", name, e.labels("family", "ipv6"), g.v6)
+	}
+
+	if ms, ok := e.src.(MemorySource); ok {
+		name := ns + "_memory_bytes"
+		fmt.Fprintf(cw, "# HELP %s Estimated heap bytes of the table.\n# TYPE %s gauge\n", name, name)
+		fmt.Fprintf(cw, "%s%s %d\n", name, e.labels(), ms.MemoryFootprint())
+	}
+
+	if e.Lookups {
+		counters := []struct {
+			name, help string
+			val        uint64
+		}{
+			{"lookups_total", "Number of observed lookups.", e.lookups.Load()},
+			{"lookup_hits_total", "Number of observed lookups with a match.", e.hits.Load()},
+		}
+
+		for _, m := range counters {
+			name := ns + "_" + m.name
+			fmt.Fprintf(cw, "# HELP %s %s\n# TYPE %s counter\n", name, m.help, name)
+			fmt.Fprintf(cw, "%s%s %d\n", name, e.labels(), m.val)
+		}
+	}
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// ServeHTTP implements the [http.Handler] interface, e.g. as /metrics endpoint.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = e.WriteTo(w)
+}
+
+// labels formats the constant labels and the extra label pairs,
+// sorted by name.
+func (e *Exporter) labels(extra ...string) string {
+	pairs := make([][2]string, 0, len(e.Labels)+len(extra)/2)
+	for k, v := range e.Labels {
+		pairs = append(pairs, [2]string{k, v})
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, [2]string{extra[i], extra[i+1]})
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	slices.SortFunc(pairs, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, p := range pairs {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(p[0])
+		sb.WriteString(`="`)
+		sb.WriteString(labelEscaper.Replace(p[1]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// countWriter counts the written bytes and keeps the first error.
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/admpub/bart"
+)
+
+var mpp = netip.MustParsePrefix
+
+func TestExporterWriteTo(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(bart.Table[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("2001:db8::/32"), 3)
+
+	e := NewExporter(tbl)
+
+	var sb strings.Builder
+	n, err := e.WriteTo(&sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(sb.Len()) {
+		t.Fatalf("WriteTo, got n: %d, written: %d", n, sb.Len())
+	}
+
+	stats := tbl.Stats()
+
+	want := `# HELP bart_prefixes Number of stored prefixes.
+# TYPE bart_prefixes gauge
+bart_prefixes{family="ipv4"} 2
+bart_prefixes{family="ipv6"} 1
+# HELP bart_nodes Number of inner trie nodes.
+# TYPE bart_nodes gauge
+bart_nodes{family="ipv4"} ` + strconv.Itoa(stats.IPv4.Nodes) + `
+bart_nodes{family="ipv6"} ` + strconv.Itoa(stats.IPv6.Nodes) + `
+# HELP bart_leaves Number of path-compressed leaf nodes.
+# TYPE bart_leaves gauge
+bart_leaves{family="ipv4"} ` + strconv.Itoa(stats.IPv4.Leaves) + `
+bart_leaves{family="ipv6"} ` + strconv.Itoa(stats.IPv6.Leaves) + `
+# HELP bart_fringes Number of path-compressed fringe nodes.
+# TYPE bart_fringes gauge
+bart_fringes{family="ipv4"} ` + strconv.Itoa(stats.IPv4.Fringes) + `
+bart_fringes{family="ipv6"} ` + strconv.Itoa(stats.IPv6.Fringes) + `
+# HELP bart_depth Number of inner trie node levels.
+# TYPE bart_depth gauge
+bart_depth{family="ipv4"} ` + strconv.Itoa(stats.IPv4.MaxDepth) + `
+bart_depth{family="ipv6"} ` + strconv.Itoa(stats.IPv6.MaxDepth) + `
+# HELP bart_memory_bytes Estimated heap bytes of the table.
+# TYPE bart_memory_bytes gauge
+bart_memory_bytes ` + strconv.FormatInt(tbl.MemoryFootprint(), 10) + `
+`
+
+	if sb.String() != want {
+		t.Fatalf("WriteTo\ngot:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestExporterLookups(t *testing.T) {
+	t.Parallel()
+
+	lite := new(bart.Lite)
+	lite.Insert(mpp("10.0.0.0/8"))
+
+	e := NewExporter(lite)
+	e.Namespace = "rib"
+	e.Labels = map[string]string{"vrf": `blue"\`, "table": "main"}
+	e.Lookups = true
+
+	for _, s := range []string{"10.0.0.1", "10.0.0.2", "192.0.2.1"} {
+		e.ObserveLookup(lite.Contains(netip.MustParseAddr(s)))
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("ServeHTTP, Content-Type: %s", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`rib_prefixes{family="ipv4",table="main",vrf="blue\"\\"} 1`,
+		"# TYPE rib_lookups_total counter\n",
+		`rib_lookups_total{table="main",vrf="blue\"\\"} 3`,
+		`rib_lookup_hits_total{table="main",vrf="blue\"\\"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("ServeHTTP, missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestStatsFunc(t *testing.T) {
+	t.Parallel()
+
+	e := NewExporter(StatsFunc(func() bart.Stats {
+		return bart.Stats{IPv6: bart.FamilyStats{Size: 42}}
+	}))
+
+	var sb strings.Builder
+	if _, err := e.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), `bart_prefixes{family="ipv6"} 42`) {
+		t.Fatalf("WriteTo, missing ipv6 prefixes in:\n%s", sb.String())
+	}
+	if strings.Contains(sb.String(), "lookups_total") {
+		t.Fatalf("WriteTo, lookup counters not enabled:\n%s", sb.String())
+	}
+}
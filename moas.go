@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// OriginConflict reports a more-specific announcement whose origins
+// are not among the origins of the closest covering announcement,
+// e.g. a possible sub-prefix hijack or a missing ROA/IRR entry.
+type OriginConflict[O comparable] struct {
+	Prefix  netip.Prefix // the more-specific prefix
+	Origins []O          // the origins of Prefix not announcing Covering
+
+	Covering        netip.Prefix // the closest covering prefix
+	CoveringOrigins []O          // the distinct origins of Covering
+}
+
+// MOAS returns an iterator over all prefixes in t with multiple
+// distinct origins (Multiple Origin AS), in natural CIDR sort order.
+//
+// The table maps each prefix to the list of origins it was
+// announced by, duplicate origins in the list are ignored.
+// The yielded origin slices are deduplicated copies.
+//
+// The analysis is lazy, it can be stopped early by breaking
+// from the range loop.
+func MOAS[O comparable](t *Table[[]O]) iter.Seq2[netip.Prefix, []O] {
+	return func(yield func(netip.Prefix, []O) bool) {
+		if t == nil {
+			return
+		}
+		for pfx, origins := range t.AllSorted() {
+			if distinct := distinctOrigins(origins); len(distinct) > 1 {
+				if !yield(pfx, distinct) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// OriginConflicts returns an iterator over all more-specific prefixes
+// in t announced by at least one origin that does not announce the
+// closest covering prefix, in natural CIDR sort order.
+//
+// Prefixes without a covering prefix in t or with an empty origin
+// list are never reported.
+//
+// The analysis is lazy, it can be stopped early by breaking
+// from the range loop.
+func OriginConflicts[O comparable](t *Table[[]O]) iter.Seq[OriginConflict[O]] {
+	return func(yield func(OriginConflict[O]) bool) {
+		if t == nil {
+			return
+		}
+		for pfx, origins := range t.AllSorted() {
+			covering, coveringOrigins, ok := coveringRoute(t, pfx)
+			if !ok {
+				continue
+			}
+
+			parent := distinctOrigins(coveringOrigins)
+
+			var foreign []O
+			for _, o := range distinctOrigins(origins) {
+				if !containsOrigin(parent, o) {
+					foreign = append(foreign, o)
+				}
+			}
+
+			if len(foreign) == 0 {
+				continue
+			}
+
+			conflict := OriginConflict[O]{
+				Prefix:          pfx,
+				Origins:         foreign,
+				Covering:        covering,
+				CoveringOrigins: parent,
+			}
+
+			if !yield(conflict) {
+				return
+			}
+		}
+	}
+}
+
+// coveringRoute returns the closest stored supernet of pfx, pfx itself excluded.
+func coveringRoute[O comparable](t *Table[[]O], pfx netip.Prefix) (netip.Prefix, []O, bool) {
+	for super, origins := range t.Supernets(pfx) {
+		if super == pfx {
+			continue
+		}
+		return super, origins, true
+	}
+	return netip.Prefix{}, nil, false
+}
+
+// distinctOrigins returns a copy of origins without duplicates,
+// the order of first occurrence is preserved.
+func distinctOrigins[O comparable](origins []O) []O {
+	result := make([]O, 0, len(origins))
+	for _, o := range origins {
+		if !containsOrigin(result, o) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// containsOrigin, origin lists are short, a linear search is sufficient.
+func containsOrigin[O comparable](origins []O, o O) bool {
+	for _, x := range origins {
+		if x == o {
+			return true
+		}
+	}
+	return false
+}
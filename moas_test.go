@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestMOAS(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[[]uint32])
+	tbl.Insert(mpp("10.0.0.0/8"), []uint32{65001})
+	tbl.Insert(mpp("10.1.0.0/16"), []uint32{65001, 65002, 65001})
+	tbl.Insert(mpp("10.2.0.0/16"), []uint32{65001, 65001})
+	tbl.Insert(mpp("2001:db8::/32"), []uint32{65003, 65004})
+
+	var got []netip.Prefix
+	for pfx, origins := range MOAS(tbl) {
+		got = append(got, pfx)
+		if len(origins) != 2 {
+			t.Errorf("MOAS(%s), got origins: %v, want 2 distinct", pfx, origins)
+		}
+	}
+
+	want := []netip.Prefix{mpp("10.1.0.0/16"), mpp("2001:db8::/32")}
+	if !slices.Equal(got, want) {
+		t.Errorf("MOAS, got: %v, want: %v", got, want)
+	}
+
+	// early break
+	for range MOAS(tbl) {
+		break
+	}
+
+	for range MOAS[uint32](nil) {
+		t.Error("MOAS(nil), expected empty iterator")
+	}
+}
+
+func TestOriginConflicts(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[[]uint32])
+	tbl.Insert(mpp("10.0.0.0/8"), []uint32{65001, 65002})
+	tbl.Insert(mpp("10.1.0.0/16"), []uint32{65002})            // ok, announced by covering origin
+	tbl.Insert(mpp("10.1.1.0/24"), []uint32{65002, 65666})     // conflict with 10.1.0.0/16
+	tbl.Insert(mpp("10.2.0.0/16"), []uint32{65666})            // conflict with 10.0.0.0/8
+	tbl.Insert(mpp("192.168.0.0/16"), []uint32{65003})         // no covering prefix
+	tbl.Insert(mpp("2001:db8::/32"), []uint32{65003})          // no covering prefix
+	tbl.Insert(mpp("2001:db8:1::/48"), []uint32{65003})        // ok
+	tbl.Insert(mpp("2001:db8:2::/48"), []uint32{65004, 65004}) // conflict
+
+	var got []OriginConflict[uint32]
+	for c := range OriginConflicts(tbl) {
+		got = append(got, c)
+	}
+
+	want := []OriginConflict[uint32]{
+		{mpp("10.1.1.0/24"), []uint32{65666}, mpp("10.1.0.0/16"), []uint32{65002}},
+		{mpp("10.2.0.0/16"), []uint32{65666}, mpp("10.0.0.0/8"), []uint32{65001, 65002}},
+		{mpp("2001:db8:2::/48"), []uint32{65004}, mpp("2001:db8::/32"), []uint32{65003}},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("OriginConflicts, got: %v, want: %v", got, want)
+	}
+
+	for i := range want {
+		if got[i].Prefix != want[i].Prefix || got[i].Covering != want[i].Covering ||
+			!slices.Equal(got[i].Origins, want[i].Origins) ||
+			!slices.Equal(got[i].CoveringOrigins, want[i].CoveringOrigins) {
+			t.Errorf("OriginConflicts[%d], got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+
+	for range OriginConflicts[uint32](nil) {
+		t.Error("OriginConflicts(nil), expected empty iterator")
+	}
+}
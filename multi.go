@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"net/netip"
+
+	"github.com/admpub/bart/internal/value"
+)
+
+// ErrTenantOverlap is returned by [MultiTable.InsertExclusive] if the
+// prefix overlaps with a route of another tenant.
+var ErrTenantOverlap = errors.New("bart: prefix overlaps with another tenant")
+
+// MultiTable is a set of routing tables, sharded by a tenant key,
+// e.g. a customer ID or a tag.
+//
+// Every tenant has its own isolated [Table]. The cross-tenant
+// queries work directly on the tenant tables, nothing is copied.
+// The iteration order over the tenants is unspecified.
+//
+// The zero value is ready to use. The same concurrency rules apply as for [Table].
+type MultiTable[K comparable, V any] struct {
+	tenants map[K]*Table[V]
+}
+
+// VRFTable is a [MultiTable] keyed by a VRF or routing table ID,
+// e.g. the Linux kernel table number.
+type VRFTable[V any] = MultiTable[uint32, V]
+
+// MultiStats are the aggregated statistics over all tenants.
+type MultiStats struct {
+	Tenants int   `json:"tenants"` // number of tenants
+	Size4   int   `json:"size4"`   // IPv4 prefixes over all tenants
+	Size6   int   `json:"size6"`   // IPv6 prefixes over all tenants
+	Bytes   int64 `json:"bytes"`   // estimated heap bytes, see [Table.MemoryFootprint]
+}
+
+// Tenant returns the table of the tenant, a new empty table is created
+// for unknown tenants.
+func (m *MultiTable[K, V]) Tenant(key K) *Table[V] {
+	if tbl, ok := m.tenants[key]; ok {
+		return tbl
+	}
+
+	if m.tenants == nil {
+		m.tenants = make(map[K]*Table[V])
+	}
+
+	tbl := new(Table[V])
+	m.tenants[key] = tbl
+	return tbl
+}
+
+// Get returns the table of the tenant, if it exists.
+func (m *MultiTable[K, V]) Get(key K) (*Table[V], bool) {
+	tbl, ok := m.tenants[key]
+	return tbl, ok
+}
+
+// DeleteTenant removes the tenant with its table.
+func (m *MultiTable[K, V]) DeleteTenant(key K) {
+	delete(m.tenants, key)
+}
+
+// Len returns the number of tenants.
+func (m *MultiTable[K, V]) Len() int {
+	return len(m.tenants)
+}
+
+// Size returns the prefix count over all tenants.
+func (m *MultiTable[K, V]) Size() int {
+	var size int
+	for _, tbl := range m.tenants {
+		size += tbl.Size()
+	}
+	return size
+}
+
+// Tenants returns an iterator over all tenants and their tables.
+func (m *MultiTable[K, V]) Tenants() iter.Seq2[K, *Table[V]] {
+	return func(yield func(K, *Table[V]) bool) {
+		for key, tbl := range m.tenants {
+			if !yield(key, tbl) {
+				return
+			}
+		}
+	}
+}
+
+// Insert adds or updates a prefix-value pair in the table of the tenant.
+func (m *MultiTable[K, V]) Insert(key K, pfx netip.Prefix, val V) {
+	m.Tenant(key).Insert(pfx, val)
+}
+
+// InsertExclusive is like [MultiTable.Insert], but it enforces the
+// non-overlap guarantee between tenants: the prefix is rejected with
+// an error wrapping [ErrTenantOverlap] if it overlaps with any route
+// of another tenant.
+func (m *MultiTable[K, V]) InsertExclusive(key K, pfx netip.Prefix, val V) error {
+	if !pfx.IsValid() {
+		return nil
+	}
+
+	for other, tbl := range m.tenants {
+		if other != key && tbl.OverlapsPrefix(pfx) {
+			return fmt.Errorf("%w: %s, tenant %v", ErrTenantOverlap, pfx, other)
+		}
+	}
+
+	m.Insert(key, pfx, val)
+	return nil
+}
+
+// Lookup performs a longest prefix match for ip in every tenant table
+// and returns an iterator over all matching tenants with their values.
+func (m *MultiTable[K, V]) Lookup(ip netip.Addr) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, tbl := range m.tenants {
+			if val, ok := tbl.Lookup(ip); ok && !yield(key, val) {
+				return
+			}
+		}
+	}
+}
+
+// LookupIn performs a longest prefix match for ip in the table of the
+// tenant, e.g. the forwarding lookup in one VRF.
+// Unknown tenants have no routes.
+func (m *MultiTable[K, V]) LookupIn(key K, ip netip.Addr) (val V, ok bool) {
+	if tbl, exists := m.tenants[key]; exists {
+		return tbl.Lookup(ip)
+	}
+	return
+}
+
+// Leak copies all routes of tenant from, covered by pfx, into the table
+// of tenant to, e.g. for route leaking between VRFs.
+// Use 0.0.0.0/0 or ::/0 to leak all routes of an address family.
+//
+// The optional callback may rewrite the value, e.g. the next hop,
+// or skip the route. The values are cloned if V implements the Cloner
+// interface. Leak returns the number of copied routes.
+func (m *MultiTable[K, V]) Leak(from, to K, pfx netip.Prefix, fn func(pfx netip.Prefix, val V) (newVal V, ok bool)) int {
+	src, exists := m.tenants[from]
+	if !exists || from == to {
+		return 0
+	}
+
+	var n int
+	dst := m.Tenant(to)
+	for p, val := range src.Subnets(pfx) {
+		val = value.CloneVal(val)
+		if fn != nil {
+			var ok bool
+			if val, ok = fn(p, val); !ok {
+				continue
+			}
+		}
+		dst.Insert(p, val)
+		n++
+	}
+	return n
+}
+
+// Stats returns the aggregated statistics over all tenants.
+func (m *MultiTable[K, V]) Stats() MultiStats {
+	s := MultiStats{Tenants: len(m.tenants)}
+	for _, tbl := range m.tenants {
+		s.Size4 += tbl.Size4()
+		s.Size6 += tbl.Size6()
+		s.Bytes += tbl.MemoryFootprint()
+	}
+	return s
+}
+
+// Contains reports whether the route of any tenant covers ip.
+func (m *MultiTable[K, V]) Contains(ip netip.Addr) bool {
+	for _, tbl := range m.tenants {
+		if tbl.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps reports whether any route of tenant a overlaps with
+// any route of tenant b. Unknown tenants have no routes.
+func (m *MultiTable[K, V]) Overlaps(a, b K) bool {
+	ta, okA := m.tenants[a]
+	tb, okB := m.tenants[b]
+	if !okA || !okB {
+		return false
+	}
+	return ta.Overlaps(tb)
+}
+
+// Conflicts returns an iterator over all pairs of distinct tenants
+// with overlapping routes, every pair is reported once.
+func (m *MultiTable[K, V]) Conflicts() iter.Seq2[K, K] {
+	return func(yield func(K, K) bool) {
+		keys := make([]K, 0, len(m.tenants))
+		for key := range m.tenants {
+			keys = append(keys, key)
+		}
+
+		for i, a := range keys {
+			for _, b := range keys[i+1:] {
+				if m.tenants[a].Overlaps(m.tenants[b]) && !yield(a, b) {
+					return
+				}
+			}
+		}
+	}
+}
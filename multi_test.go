@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestMultiTable(t *testing.T) {
+	t.Parallel()
+
+	m := new(MultiTable[string, int])
+
+	if m.Len() != 0 || m.Size() != 0 || m.Contains(mpa("10.0.0.1")) || m.Overlaps("a", "b") {
+		t.Fatal("zero value, expected empty MultiTable")
+	}
+
+	m.Insert("a", mpp("10.0.0.0/8"), 1)
+	m.Insert("a", mpp("2001:db8::/32"), 2)
+	m.Insert("b", mpp("10.1.0.0/16"), 3)
+	m.Insert("c", mpp("192.168.0.0/16"), 4)
+
+	if m.Len() != 3 || m.Size() != 4 {
+		t.Fatalf("Len: %d, Size: %d, want: 3, 4", m.Len(), m.Size())
+	}
+
+	if tbl, ok := m.Get("a"); !ok || tbl.Size() != 2 || tbl != m.Tenant("a") {
+		t.Fatal("Get, expected the tenant table")
+	}
+	if _, ok := m.Get("x"); ok {
+		t.Fatal("Get, unknown tenant found")
+	}
+
+	got := map[string]int{}
+	for key, val := range m.Lookup(mpa("10.1.2.3")) {
+		got[key] = val
+	}
+	if len(got) != 2 || got["a"] != 1 || got["b"] != 3 {
+		t.Errorf("Lookup, got: %v", got)
+	}
+
+	for range m.Lookup(mpa("10.1.2.3")) {
+		break
+	}
+
+	if !m.Contains(mpa("192.168.1.1")) || m.Contains(mpa("172.16.0.1")) {
+		t.Error("Contains, unexpected result")
+	}
+
+	if !m.Overlaps("a", "b") || m.Overlaps("a", "c") || m.Overlaps("a", "x") {
+		t.Error("Overlaps, unexpected result")
+	}
+
+	var conflicts [][2]string
+	for a, b := range m.Conflicts() {
+		conflicts = append(conflicts, [2]string{a, b})
+	}
+	if len(conflicts) != 1 || conflicts[0] != [2]string{"a", "b"} && conflicts[0] != [2]string{"b", "a"} {
+		t.Errorf("Conflicts, got: %v", conflicts)
+	}
+
+	n := 0
+	for range m.Tenants() {
+		n++
+	}
+	if n != 3 {
+		t.Errorf("Tenants, got: %d, want: 3", n)
+	}
+
+	m.DeleteTenant("b")
+	for range m.Conflicts() {
+		t.Error("Conflicts after DeleteTenant, expected none")
+	}
+}
+
+func TestMultiTableInsertExclusive(t *testing.T) {
+	t.Parallel()
+
+	m := new(MultiTable[int, string])
+
+	if err := m.InsertExclusive(1, mpp("10.0.0.0/8"), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.InsertExclusive(1, mpp("10.1.0.0/16"), "a"); err != nil {
+		t.Fatalf("overlap within the same tenant, unexpected error: %v", err)
+	}
+	if err := m.InsertExclusive(2, mpp("10.2.0.0/16"), "b"); !errors.Is(err, ErrTenantOverlap) {
+		t.Fatalf("overlap with other tenant, got err: %v", err)
+	}
+	if err := m.InsertExclusive(2, mpp("0.0.0.0/0"), "b"); !errors.Is(err, ErrTenantOverlap) {
+		t.Fatalf("covering overlap with other tenant, got err: %v", err)
+	}
+	if err := m.InsertExclusive(2, mpp("11.0.0.0/8"), "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Size() != 3 {
+		t.Errorf("Size, got: %d, want: 3", m.Size())
+	}
+}
+
+func TestVRFTable(t *testing.T) {
+	t.Parallel()
+
+	var vrf VRFTable[string]
+
+	if _, ok := vrf.LookupIn(10, mpa("10.0.0.1")); ok {
+		t.Fatal("LookupIn, unknown VRF, expected no match")
+	}
+
+	vrf.Insert(10, mpp("10.0.0.0/8"), "red")
+	vrf.Insert(10, mpp("10.1.0.0/16"), "red-1")
+	vrf.Insert(10, mpp("2001:db8::/32"), "red-6")
+	vrf.Insert(20, mpp("0.0.0.0/0"), "blue")
+
+	if val, ok := vrf.LookupIn(10, mpa("10.1.2.3")); !ok || val != "red-1" {
+		t.Errorf("LookupIn, got: (%s, %v), want: (red-1, true)", val, ok)
+	}
+	if val, ok := vrf.LookupIn(20, mpa("10.1.2.3")); !ok || val != "blue" {
+		t.Errorf("LookupIn, got: (%s, %v), want: (blue, true)", val, ok)
+	}
+
+	// leak the IPv4 routes of VRF 10 into VRF 20, skip the more specifics
+	n := vrf.Leak(10, 20, mpp("0.0.0.0/0"), func(pfx netip.Prefix, val string) (string, bool) {
+		return "leaked-" + val, pfx.Bits() <= 8
+	})
+	if n != 1 {
+		t.Fatalf("Leak, got: %d routes, want: 1", n)
+	}
+	if val, ok := vrf.LookupIn(20, mpa("10.1.2.3")); !ok || val != "leaked-red" {
+		t.Errorf("LookupIn after Leak, got: (%s, %v), want: (leaked-red, true)", val, ok)
+	}
+
+	// leak into a new VRF
+	if n := vrf.Leak(10, 30, mpp("::/0"), nil); n != 1 {
+		t.Errorf("Leak, got: %d routes, want: 1", n)
+	}
+	if n := vrf.Leak(99, 30, mpp("::/0"), nil); n != 0 {
+		t.Errorf("Leak, unknown VRF, got: %d routes, want: 0", n)
+	}
+
+	s := vrf.Stats()
+	if s.Tenants != 3 || s.Size4 != 4 || s.Size6 != 2 || s.Bytes <= 0 {
+		t.Errorf("Stats, got: %+v", s)
+	}
+}
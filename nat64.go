@@ -0,0 +1,194 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"fmt"
+	"iter"
+	"net/netip"
+)
+
+// NAT64 is an IPv4-embedded IPv6 translation prefix as defined in RFC 6052,
+// e.g. the well-known prefix 64:ff9b::/96 or a network-specific prefix
+// of length 32, 40, 48, 56, 64 or 96.
+//
+// NAT64 maps IPv4 addresses and prefixes into the IPv6 address space
+// and back, as needed by NAT64 and 464XLAT translation gateways.
+// The zero value is not usable, see [NewNAT64].
+type NAT64 struct {
+	pfx netip.Prefix
+}
+
+// NewNAT64 returns the translator for the IPv6 prefix pfx.
+// The prefix must be a masked IPv6 prefix with one of the
+// lengths allowed by RFC 6052.
+func NewNAT64(pfx netip.Prefix) (NAT64, error) {
+	if !pfx.IsValid() || !pfx.Addr().Is6() || pfx.Addr().Is4In6() {
+		return NAT64{}, fmt.Errorf("nat64: %s is no valid IPv6 prefix", pfx)
+	}
+
+	if pfx != pfx.Masked() {
+		return NAT64{}, fmt.Errorf("nat64: prefix %s is not masked", pfx)
+	}
+
+	switch pfx.Bits() {
+	case 32, 40, 48, 56, 64, 96:
+	default:
+		return NAT64{}, fmt.Errorf("nat64: invalid prefix length /%d", pfx.Bits())
+	}
+
+	return NAT64{pfx: pfx}, nil
+}
+
+// Prefix returns the IPv6 translation prefix.
+func (n NAT64) Prefix() netip.Prefix {
+	return n.pfx
+}
+
+// byteIndex returns the position of the i-th IPv4 octet in the IPv6 address,
+// skipping the reserved u-octet (bits 64-71).
+func (n NAT64) byteIndex(i int) int {
+	idx := n.pfx.Bits()/8 + i
+	if idx >= 8 && n.pfx.Bits() <= 64 {
+		idx++
+	}
+	return idx
+}
+
+// bits6 returns the IPv6 prefix length for an embedded IPv4 prefix length.
+func (n NAT64) bits6(bits4 int) int {
+	bits := n.pfx.Bits() + bits4
+	if n.pfx.Bits() <= 64 && bits > 64 {
+		bits += 8 // u-octet
+	}
+	return bits
+}
+
+// bits4 returns the IPv4 prefix length for an embedded IPv6 prefix length,
+// ok is false if bits6 is longer than the embedded IPv4 address.
+func (n NAT64) bits4(bits6 int) (bits int, ok bool) {
+	switch {
+	case n.pfx.Bits() <= 64 && bits6 > 72:
+		bits = bits6 - 8 - n.pfx.Bits()
+	case n.pfx.Bits() <= 64 && bits6 > 64:
+		bits = 64 - n.pfx.Bits()
+	default:
+		bits = bits6 - n.pfx.Bits()
+	}
+	return bits, bits <= 32
+}
+
+// Embed returns the IPv4-embedded IPv6 address of the IPv4 address a.
+// The suffix bits and the u-octet are zero.
+func (n NAT64) Embed(a netip.Addr) (netip.Addr, bool) {
+	if !n.pfx.IsValid() || !a.Is4() {
+		return netip.Addr{}, false
+	}
+
+	b6 := n.pfx.Addr().As16()
+	b4 := a.As4()
+	for i, b := range b4 {
+		b6[n.byteIndex(i)] = b
+	}
+
+	return netip.AddrFrom16(b6), true
+}
+
+// Extract returns the IPv4 address embedded in the IPv6 address a,
+// ok is false if a is not covered by the translation prefix.
+func (n NAT64) Extract(a netip.Addr) (netip.Addr, bool) {
+	if !n.pfx.IsValid() || !a.Is6() || a.Is4In6() || !n.pfx.Contains(a) {
+		return netip.Addr{}, false
+	}
+
+	b6 := a.As16()
+	var b4 [4]byte
+	for i := range b4 {
+		b4[i] = b6[n.byteIndex(i)]
+	}
+
+	return netip.AddrFrom4(b4), true
+}
+
+// EmbedPrefix returns the IPv6 equivalent of the IPv4 prefix pfx.
+func (n NAT64) EmbedPrefix(pfx netip.Prefix) (netip.Prefix, bool) {
+	if !pfx.IsValid() || !pfx.Addr().Is4() {
+		return netip.Prefix{}, false
+	}
+
+	pfx = pfx.Masked()
+	a6, ok := n.Embed(pfx.Addr())
+	if !ok {
+		return netip.Prefix{}, false
+	}
+
+	return netip.PrefixFrom(a6, n.bits6(pfx.Bits())), true
+}
+
+// ExtractPrefix returns the IPv4 equivalent of the IPv6 prefix pfx.
+// The prefix must be covered by the translation prefix and must not
+// be more specific than the embedded IPv4 host address.
+func (n NAT64) ExtractPrefix(pfx netip.Prefix) (netip.Prefix, bool) {
+	if !pfx.IsValid() || !n.pfx.IsValid() || pfx.Bits() < n.pfx.Bits() {
+		return netip.Prefix{}, false
+	}
+
+	pfx = pfx.Masked()
+	a4, ok := n.Extract(pfx.Addr())
+	if !ok {
+		return netip.Prefix{}, false
+	}
+
+	bits, ok := n.bits4(pfx.Bits())
+	if !ok {
+		return netip.Prefix{}, false
+	}
+
+	return netip.PrefixFrom(a4, bits), true
+}
+
+// NAT64Embed returns an iterator over all IPv4 entries of t,
+// projected into the IPv6 address space of n.
+func NAT64Embed[V any](n NAT64, t *Table[V]) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		if t == nil {
+			return
+		}
+		for pfx, val := range t.All4() {
+			if pfx6, ok := n.EmbedPrefix(pfx); ok && !yield(pfx6, val) {
+				return
+			}
+		}
+	}
+}
+
+// NAT64Extract returns an iterator over all IPv6 entries of t
+// within the translation prefix of n, projected back into the
+// IPv4 address space. Entries more specific than an embedded
+// IPv4 host address are skipped.
+func NAT64Extract[V any](n NAT64, t *Table[V]) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		if t == nil {
+			return
+		}
+		for pfx, val := range t.Subnets(n.pfx) {
+			if pfx4, ok := n.ExtractPrefix(pfx); ok && !yield(pfx4, val) {
+				return
+			}
+		}
+	}
+}
+
+// NAT64Lookup performs a longest-prefix-match for ip in t. IPv6 addresses
+// within the translation prefix of n are translated and looked up
+// in the IPv4 routes of t, all other addresses are looked up as is.
+func NAT64Lookup[V any](n NAT64, t *Table[V], ip netip.Addr) (val V, ok bool) {
+	if t == nil {
+		return
+	}
+	if a4, embedded := n.Extract(ip); embedded {
+		ip = a4
+	}
+	return t.Lookup(ip)
+}
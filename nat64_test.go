@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestNewNAT64(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"2001:db8::/32", "2001:db8:100::/40", "64:ff9b::/96", "2001:db8:122:344::/64"} {
+		if _, err := NewNAT64(mpp(s)); err != nil {
+			t.Errorf("NewNAT64(%s), unexpected error: %v", s, err)
+		}
+	}
+
+	for _, pfx := range []netip.Prefix{{}, mpp("10.0.0.0/8"), mpp("2001:db8::/33"), netip.MustParsePrefix("2001:db8::1/96")} {
+		if _, err := NewNAT64(pfx); err == nil {
+			t.Errorf("NewNAT64(%s), expected error", pfx)
+		}
+	}
+}
+
+// examples from RFC 6052, section 2.4
+func TestNAT64EmbedRFC6052(t *testing.T) {
+	t.Parallel()
+
+	a4 := mpa("192.0.2.33")
+
+	tests := []struct {
+		pfx  string
+		want string
+	}{
+		{"2001:db8::/32", "2001:db8:c000:221::"},
+		{"2001:db8:100::/40", "2001:db8:1c0:2:21::"},
+		{"2001:db8:122::/48", "2001:db8:122:c000:2:2100::"},
+		{"2001:db8:122:300::/56", "2001:db8:122:3c0:0:221::"},
+		{"2001:db8:122:344::/64", "2001:db8:122:344:c0:2:2100:0"},
+		{"2001:db8:122:344::/96", "2001:db8:122:344::192.0.2.33"},
+		{"64:ff9b::/96", "64:ff9b::192.0.2.33"},
+	}
+
+	for _, tt := range tests {
+		n, err := NewNAT64(mpp(tt.pfx))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		a6, ok := n.Embed(a4)
+		if !ok || a6 != mpa(tt.want) {
+			t.Errorf("%s: Embed(%s), got: %s, %v, want: %s", tt.pfx, a4, a6, ok, tt.want)
+		}
+
+		back, ok := n.Extract(a6)
+		if !ok || back != a4 {
+			t.Errorf("%s: Extract(%s), got: %s, %v, want: %s", tt.pfx, a6, back, ok, a4)
+		}
+	}
+}
+
+func TestNAT64PrefixRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"2001:db8::/32", "2001:db8:100::/40", "2001:db8:122::/48", "2001:db8:122:300::/56", "2001:db8:122:344::/64", "64:ff9b::/96"} {
+		n, _ := NewNAT64(mpp(s))
+
+		for bits := 0; bits <= 32; bits++ {
+			pfx4 := netip.PrefixFrom(mpa("192.0.2.33"), bits).Masked()
+
+			pfx6, ok := n.EmbedPrefix(pfx4)
+			if !ok || !n.Prefix().Overlaps(pfx6) || pfx6.Bits() < n.Prefix().Bits() {
+				t.Fatalf("%s: EmbedPrefix(%s), got: %s, %v", s, pfx4, pfx6, ok)
+			}
+
+			got, ok := n.ExtractPrefix(pfx6)
+			if !ok || got != pfx4 {
+				t.Fatalf("%s: ExtractPrefix(%s), got: %s, %v, want: %s", s, pfx6, got, ok, pfx4)
+			}
+		}
+	}
+
+	n, _ := NewNAT64(mpp("2001:db8::/32"))
+	for _, pfx := range []string{"2001:d00::/24", "2001:db9::/48", "2001:db8::/120", "10.0.0.0/8"} {
+		if got, ok := n.ExtractPrefix(mpp(pfx)); ok {
+			t.Errorf("ExtractPrefix(%s), got: %s, want: !ok", pfx, got)
+		}
+	}
+}
+
+func TestNAT64Table(t *testing.T) {
+	t.Parallel()
+
+	n, _ := NewNAT64(mpp("64:ff9b::/96"))
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("192.0.2.0/24"), 1)
+	tbl.Insert(mpp("0.0.0.0/0"), 2)
+	tbl.Insert(mpp("2001:db8::/32"), 3)
+	tbl.Insert(mpp("64:ff9b::c633:6400/120"), 4)
+
+	got := map[netip.Prefix]int{}
+	for pfx, val := range NAT64Embed(n, tbl) {
+		got[pfx] = val
+	}
+	if len(got) != 2 || got[mpp("64:ff9b::c000:200/120")] != 1 || got[mpp("64:ff9b::/96")] != 2 {
+		t.Errorf("NAT64Embed, got: %v", got)
+	}
+
+	got = map[netip.Prefix]int{}
+	for pfx, val := range NAT64Extract(n, tbl) {
+		got[pfx] = val
+	}
+	if len(got) != 1 || got[mpp("198.51.100.0/24")] != 4 {
+		t.Errorf("NAT64Extract, got: %v", got)
+	}
+
+	tests := []struct {
+		ip   string
+		want int
+	}{
+		{"64:ff9b::192.0.2.1", 1},
+		{"64:ff9b::8.8.8.8", 2},
+		{"192.0.2.1", 1},
+		{"2001:db8::1", 3},
+	}
+	for _, tt := range tests {
+		if val, ok := NAT64Lookup(n, tbl, mpa(tt.ip)); !ok || val != tt.want {
+			t.Errorf("NAT64Lookup(%s), got: %d, %v, want: %d", tt.ip, val, ok, tt.want)
+		}
+	}
+
+	if _, ok := NAT64Lookup(n, tbl, mpa("2001:db9::1")); ok {
+		t.Errorf("NAT64Lookup(2001:db9::1), expected !ok")
+	}
+}
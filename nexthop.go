@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math"
+	"math/bits"
+	"net/netip"
+	"slices"
+)
+
+// Nexthop is a member of a [NexthopGroup].
+type Nexthop struct {
+	Addr   netip.Addr
+	Weight uint32 // relative weight, 0 excludes the member from Pick
+}
+
+// NexthopGroup is a weighted ECMP group of next hops, a ready-made
+// value type for a [Table], e.g. Table[NexthopGroup].
+//
+// A group is immutable and may be shared between tables and versions.
+// The members are kept in canonical order, sorted by address with the
+// weights of duplicate addresses summed up, so equal member sets pick
+// the same next hop for the same flow, regardless of the insertion order.
+//
+// Usage after a route lookup:
+//
+//	if nhg, ok := table.Lookup(dst); ok {
+//	    nh, ok := nhg.Pick(bart.FlowHash(src, dst, proto, sport, dport))
+//	    ...
+//	}
+type NexthopGroup struct {
+	members []Nexthop
+	upper   []uint64 // cumulative weights, upper bounds of the members
+}
+
+// NewNexthopGroup returns the group of the members.
+func NewNexthopGroup(members ...Nexthop) NexthopGroup {
+	ms := slices.Clone(members)
+	slices.SortStableFunc(ms, func(a, b Nexthop) int {
+		return a.Addr.Compare(b.Addr)
+	})
+
+	// merge duplicates, the weights saturate
+	merged := ms[:0]
+	for _, m := range ms {
+		if n := len(merged); n > 0 && merged[n-1].Addr == m.Addr {
+			sum := uint64(merged[n-1].Weight) + uint64(m.Weight)
+			merged[n-1].Weight = uint32(min(sum, math.MaxUint32))
+			continue
+		}
+		merged = append(merged, m)
+	}
+	ms = slices.Clip(merged)
+
+	g := NexthopGroup{members: ms, upper: make([]uint64, len(ms))}
+	var total uint64
+	for i, m := range ms {
+		total += uint64(m.Weight)
+		g.upper[i] = total
+	}
+	return g
+}
+
+// Len returns the number of members.
+func (g NexthopGroup) Len() int {
+	return len(g.members)
+}
+
+// Members returns a copy of the members in canonical order.
+func (g NexthopGroup) Members() []Nexthop {
+	return slices.Clone(g.members)
+}
+
+// TotalWeight returns the sum of all member weights.
+func (g NexthopGroup) TotalWeight() uint64 {
+	if len(g.upper) == 0 {
+		return 0
+	}
+	return g.upper[len(g.upper)-1]
+}
+
+// Pick selects a member by the flow hash, proportional to the weights.
+// The same hash always selects the same member of a group, all packets
+// of a flow take the same path. It returns false if the group has no
+// member with a weight > 0.
+//
+// The hash is scaled to the total weight by multiplication, not modulo,
+// all bits of the hash contribute and no member is biased.
+func (g NexthopGroup) Pick(hash uint64) (nh Nexthop, ok bool) {
+	total := g.TotalWeight()
+	if total == 0 {
+		return
+	}
+
+	// x is uniform in [0, total)
+	x, _ := bits.Mul64(hash, total)
+
+	i, _ := slices.BinarySearchFunc(g.upper, x, func(upper, x uint64) int {
+		// the first member with x < upper, members
+		// with zero weight are never selected
+		if upper <= x {
+			return -1
+		}
+		return 1
+	})
+	return g.members[i], true
+}
+
+// Equal reports whether both groups have the same members and weights,
+// it is used by the table methods comparing values.
+func (g NexthopGroup) Equal(o NexthopGroup) bool {
+	return slices.Equal(g.members, o.members)
+}
+
+// FlowHash returns a hash of the five-tuple of a flow for
+// [NexthopGroup.Pick]. The hash has no random seed, it is the same
+// on every host and after restarts.
+func FlowHash(src, dst netip.Addr, proto uint8, srcPort, dstPort uint16) uint64 {
+	// FNV-1a, finalized with the murmur3 avalanche mixer
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+
+	h := uint64(offset64)
+	add := func(b []byte) {
+		for _, c := range b {
+			h ^= uint64(c)
+			h *= prime64
+		}
+	}
+
+	s, d := src.As16(), dst.As16()
+	add(s[:])
+	add(d[:])
+	add([]byte{proto, byte(srcPort >> 8), byte(srcPort), byte(dstPort >> 8), byte(dstPort)})
+
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNexthopGroup(t *testing.T) {
+	t.Parallel()
+
+	var empty NexthopGroup
+	if _, ok := empty.Pick(42); ok || empty.Len() != 0 {
+		t.Fatal("zero value, expected empty group")
+	}
+
+	g := NewNexthopGroup(
+		Nexthop{mpa("192.0.2.3"), 1},
+		Nexthop{mpa("192.0.2.1"), 2},
+		Nexthop{mpa("192.0.2.2"), 0},
+		Nexthop{mpa("192.0.2.1"), 1}, // duplicate
+	)
+
+	if g.Len() != 3 || g.TotalWeight() != 4 {
+		t.Fatalf("got Len: %d, TotalWeight: %d, want: 3, 4", g.Len(), g.TotalWeight())
+	}
+	if ms := g.Members(); ms[0] != (Nexthop{mpa("192.0.2.1"), 3}) {
+		t.Errorf("Members, duplicates not merged or not sorted: %v", ms)
+	}
+
+	// insertion order doesn't matter
+	g2 := NewNexthopGroup(
+		Nexthop{mpa("192.0.2.2"), 0},
+		Nexthop{mpa("192.0.2.1"), 3},
+		Nexthop{mpa("192.0.2.3"), 1},
+	)
+	if !g.Equal(g2) {
+		t.Fatal("Equal, expected equal groups")
+	}
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	count := map[Nexthop]int{}
+	for range 40_000 {
+		hash := prng.Uint64()
+		nh, ok := g.Pick(hash)
+		if !ok {
+			t.Fatal("Pick, expected a member")
+		}
+		if nh2, _ := g2.Pick(hash); nh2 != nh {
+			t.Fatalf("Pick, equal groups, got: %v and %v", nh, nh2)
+		}
+		count[nh]++
+	}
+
+	// 3:1 distribution, zero weight never picked
+	if n := count[Nexthop{mpa("192.0.2.2"), 0}]; n != 0 {
+		t.Errorf("Pick, member with zero weight picked %d times", n)
+	}
+	if n := count[Nexthop{mpa("192.0.2.1"), 3}]; math.Abs(float64(n)/30_000-1) > 0.03 {
+		t.Errorf("Pick, got: %d, want: ~30000", n)
+	}
+
+	// boundaries
+	if nh, _ := g.Pick(0); nh.Addr != mpa("192.0.2.1") {
+		t.Errorf("Pick(0), got: %v", nh)
+	}
+	if nh, _ := g.Pick(math.MaxUint64); nh.Addr != mpa("192.0.2.3") {
+		t.Errorf("Pick(MaxUint64), got: %v", nh)
+	}
+
+	// as table value
+	tbl := new(Table[NexthopGroup])
+	tbl.Insert(mpp("0.0.0.0/0"), g)
+	if nhg, ok := tbl.Lookup(mpa("198.51.100.1")); !ok || !nhg.Equal(g) {
+		t.Error("Lookup, expected the group")
+	}
+}
+
+func TestFlowHash(t *testing.T) {
+	t.Parallel()
+
+	h1 := FlowHash(mpa("10.0.0.1"), mpa("10.0.0.2"), 6, 50000, 443)
+	if h2 := FlowHash(mpa("10.0.0.1"), mpa("10.0.0.2"), 6, 50000, 443); h1 != h2 {
+		t.Fatal("FlowHash, not deterministic")
+	}
+	if h2 := FlowHash(mpa("10.0.0.1"), mpa("10.0.0.2"), 6, 50001, 443); h1 == h2 {
+		t.Error("FlowHash, source port ignored")
+	}
+	if h2 := FlowHash(mpa("10.0.0.2"), mpa("10.0.0.1"), 6, 50000, 443); h1 == h2 {
+		t.Error("FlowHash, direction ignored")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+	"strconv"
+
+	"github.com/admpub/bart/internal/value"
+)
+
+// Op is the kind of a mutation, reported by [ObservedTable].
+type Op uint8
+
+const (
+	OpInsert Op = iota // a new prefix
+	OpUpdate           // a changed value of an existing prefix
+	OpDelete           // a removed prefix
+)
+
+// String implements fmt.Stringer.
+func (op Op) String() string {
+	switch op {
+	case OpInsert:
+		return "insert"
+	case OpUpdate:
+		return "update"
+	case OpDelete:
+		return "delete"
+	default:
+		return "Op(" + strconv.Itoa(int(op)) + ")"
+	}
+}
+
+// ObservedTable is a routing table reporting every mutation to a
+// callback, e.g. to program the changes incrementally into a FIB
+// without computing a [Diff] afterwards.
+//
+// The callback is called synchronously after each effective mutation
+// with the prefix, the old and the new value; oldVal is the zero value
+// for OpInsert, newVal for OpDelete. No-ops, e.g. deleting a missing
+// prefix or an update with an equal value, are not reported.
+// The values are compared with their Equal method, if implemented,
+// or reflect.DeepEqual.
+//
+// The bulk operations Union, UnionWith, InsertBulk and Clear report
+// every single prefix. The callback must not call the mutating methods
+// of the table.
+//
+// The same concurrency rules apply as for [Table].
+type ObservedTable[V any] struct {
+	tbl      Table[V]
+	onChange func(op Op, pfx netip.Prefix, oldVal, newVal V)
+}
+
+// NewObservedTable returns an empty table, onChange may be nil.
+func NewObservedTable[V any](onChange func(op Op, pfx netip.Prefix, oldVal, newVal V)) *ObservedTable[V] {
+	return &ObservedTable[V]{onChange: onChange}
+}
+
+// notify reports the mutation of pfx, no-ops are suppressed.
+func (o *ObservedTable[V]) notify(pfx netip.Prefix, oldVal V, oldOK bool, newVal V, newOK bool) {
+	if o.onChange == nil {
+		return
+	}
+
+	var zero V
+	switch {
+	case !oldOK && newOK:
+		o.onChange(OpInsert, pfx, zero, newVal)
+	case oldOK && !newOK:
+		o.onChange(OpDelete, pfx, oldVal, zero)
+	case oldOK && newOK && !value.Equal(oldVal, newVal):
+		o.onChange(OpUpdate, pfx, oldVal, newVal)
+	}
+}
+
+// Modify applies an insert, update, or delete for pfx, see [Table.Modify].
+// All other mutating methods are built on Modify.
+func (o *ObservedTable[V]) Modify(pfx netip.Prefix, cb func(val V, ok bool) (newVal V, del bool)) {
+	if !pfx.IsValid() {
+		return
+	}
+	pfx = pfx.Masked()
+
+	var oldVal, newVal V
+	var oldOK, newOK bool
+
+	o.tbl.Modify(pfx, func(val V, ok bool) (V, bool) {
+		oldVal, oldOK = val, ok
+
+		nv, del := cb(val, ok)
+		if !del {
+			newVal, newOK = nv, true
+		}
+		return nv, del
+	})
+
+	o.notify(pfx, oldVal, oldOK, newVal, newOK)
+}
+
+// Insert adds or updates a prefix-value pair, see [Table.Insert].
+func (o *ObservedTable[V]) Insert(pfx netip.Prefix, val V) {
+	o.Modify(pfx, func(V, bool) (V, bool) { return val, false })
+}
+
+// Update inserts or updates the value of pfx with the callback,
+// see [Table.Update].
+func (o *ObservedTable[V]) Update(pfx netip.Prefix, cb func(val V, ok bool) V) (newVal V) {
+	o.Modify(pfx, func(val V, ok bool) (V, bool) {
+		newVal = cb(val, ok)
+		return newVal, false
+	})
+	return newVal
+}
+
+// Delete removes the exact prefix pfx, see [Table.Delete].
+func (o *ObservedTable[V]) Delete(pfx netip.Prefix) {
+	o.GetAndDelete(pfx)
+}
+
+// GetAndDelete removes the exact prefix pfx and returns its value,
+// see [Table.GetAndDelete].
+func (o *ObservedTable[V]) GetAndDelete(pfx netip.Prefix) (val V, exists bool) {
+	o.Modify(pfx, func(old V, ok bool) (V, bool) {
+		val, exists = old, ok
+		return old, true
+	})
+	return val, exists
+}
+
+// InsertBulk inserts all entries, see [Table.InsertBulk].
+// Every entry is reported, for duplicate prefixes the last value wins.
+func (o *ObservedTable[V]) InsertBulk(entries []PrefixValue[V]) {
+	for _, e := range entries {
+		o.Insert(e.Prefix, e.Value)
+	}
+}
+
+// Union inserts all prefixes of other, the values of other win,
+// see [Table.Union].
+func (o *ObservedTable[V]) Union(other *Table[V]) {
+	o.UnionWith(other, nil)
+}
+
+// UnionWith is like Union, but duplicate prefixes are resolved by merge,
+// see [Table.UnionWith].
+func (o *ObservedTable[V]) UnionWith(other *Table[V], merge func(existing, incoming V) V) {
+	if other == nil {
+		return
+	}
+
+	for pfx, incoming := range other.All() {
+		incoming = value.CloneVal(incoming)
+		o.Modify(pfx, func(existing V, ok bool) (V, bool) {
+			if ok && merge != nil {
+				return merge(existing, incoming), false
+			}
+			return incoming, false
+		})
+	}
+}
+
+// Clear removes all prefixes, every prefix is reported as deleted.
+func (o *ObservedTable[V]) Clear() {
+	if o.onChange != nil {
+		var zero V
+		for pfx, val := range o.tbl.All() {
+			o.onChange(OpDelete, pfx, val, zero)
+		}
+	}
+	o.tbl.Clear()
+}
+
+// Get returns the value of the exact prefix pfx, see [Table.Get].
+func (o *ObservedTable[V]) Get(pfx netip.Prefix) (val V, exists bool) {
+	return o.tbl.Get(pfx)
+}
+
+// Contains reports whether any stored prefix matches ip, see [Table.Contains].
+func (o *ObservedTable[V]) Contains(ip netip.Addr) bool {
+	return o.tbl.Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (o *ObservedTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return o.tbl.Lookup(ip)
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (o *ObservedTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	return o.tbl.LookupPrefix(pfx)
+}
+
+// Size returns the prefix count.
+func (o *ObservedTable[V]) Size() int {
+	return o.tbl.Size()
+}
+
+// All returns an iterator over all prefix-value pairs, see [Table.All].
+func (o *ObservedTable[V]) All() iter.Seq2[netip.Prefix, V] {
+	return o.tbl.All()
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestObservedTable(t *testing.T) {
+	t.Parallel()
+
+	var events []string
+	o := NewObservedTable(func(op Op, pfx netip.Prefix, oldVal, newVal int) {
+		events = append(events, fmt.Sprintf("%s %s %d %d", op, pfx, oldVal, newVal))
+	})
+
+	expect := func(name string, want ...string) {
+		t.Helper()
+		if !slices.Equal(events, want) {
+			t.Errorf("%s, got events: %q, want: %q", name, events, want)
+		}
+		events = events[:0]
+	}
+
+	o.Insert(mpp("10.0.0.0/8"), 1)
+	o.Insert(mpp("10.0.0.0/8"), 2)
+	o.Insert(mpp("10.0.0.0/8"), 2)                   // no-op
+	o.Insert(netip.MustParsePrefix("10.1.2.3/8"), 3) // masked
+	expect("Insert", "insert 10.0.0.0/8 0 1", "update 10.0.0.0/8 1 2", "update 10.0.0.0/8 2 3")
+
+	o.Delete(mpp("10.0.0.0/8"))
+	o.Delete(mpp("10.0.0.0/8")) // no-op
+	expect("Delete", "delete 10.0.0.0/8 3 0")
+
+	if val := o.Update(mpp("2001:db8::/32"), func(val int, _ bool) int { return val + 1 }); val != 1 {
+		t.Errorf("Update, got: %d, want: 1", val)
+	}
+	expect("Update", "insert 2001:db8::/32 0 1")
+
+	o.InsertBulk([]PrefixValue[int]{{mpp("192.168.0.0/16"), 4}, {mpp("2001:db8::/32"), 5}})
+	expect("InsertBulk", "insert 192.168.0.0/16 0 4", "update 2001:db8::/32 1 5")
+
+	other := new(Table[int])
+	other.Insert(mpp("192.168.0.0/16"), 40)
+	other.Insert(mpp("172.16.0.0/12"), 6)
+
+	o.UnionWith(other, func(existing, incoming int) int { return existing + incoming })
+	expect("UnionWith", "insert 172.16.0.0/12 0 6", "update 192.168.0.0/16 4 44")
+
+	o.Union(other)
+	expect("Union", "update 192.168.0.0/16 44 40")
+
+	if val, ok := o.GetAndDelete(mpp("172.16.0.0/12")); !ok || val != 6 {
+		t.Errorf("GetAndDelete, got: (%d, %v), want: (6, true)", val, ok)
+	}
+	expect("GetAndDelete", "delete 172.16.0.0/12 6 0")
+
+	o.Clear()
+	slices.Sort(events)
+	expect("Clear", "delete 192.168.0.0/16 40 0", "delete 2001:db8::/32 5 0")
+
+	if o.Size() != 0 {
+		t.Errorf("Clear, got size: %d, want: 0", o.Size())
+	}
+
+	// nil callback
+	o = NewObservedTable[int](nil)
+	o.Insert(mpp("10.0.0.0/8"), 1)
+	o.Clear()
+}
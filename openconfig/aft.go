@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+// Package openconfig provides codecs between bart routing tables and the
+// OpenConfig AFT (abstract forwarding table) JSON encoding, as used by
+// gNMI-based telemetry and network controllers.
+//
+// The JSON follows the RFC 7951 encoding of the openconfig-aft model:
+//
+//	{
+//	  "openconfig-aft:afts": {
+//	    "ipv4-unicast": {
+//	      "ipv4-entry": [
+//	        {
+//	          "prefix": "10.0.0.0/8",
+//	          "state": { "prefix": "10.0.0.0/8", "next-hop-group": "42" }
+//	        }
+//	      ]
+//	    },
+//	    "ipv6-unicast": {
+//	      "ipv6-entry": [ ... ]
+//	    }
+//	  }
+//	}
+//
+// Only the entry state leaves listed in [State] are supported,
+// unknown leaves are ignored on decoding.
+package openconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/admpub/bart"
+)
+
+// State is the state container of an AFT ipv4-entry or ipv6-entry.
+// The prefix itself is the key of the routing table entry.
+type State struct {
+	// NextHopGroup references the next-hop-group of the entry.
+	NextHopGroup uint64 `json:"next-hop-group,omitempty,string"`
+
+	// NextHopGroupNetworkInstance is the network instance of the next-hop-group.
+	NextHopGroupNetworkInstance string `json:"next-hop-group-network-instance,omitempty"`
+
+	// OriginProtocol is the protocol that installed the entry, e.g. "openconfig-policy-types:BGP".
+	OriginProtocol string `json:"origin-protocol,omitempty"`
+
+	// OriginNetworkInstance is the network instance the entry was learned from.
+	OriginNetworkInstance string `json:"origin-network-instance,omitempty"`
+
+	// DecapsulateHeader is the header type to decapsulate, if any.
+	DecapsulateHeader string `json:"decapsulate-header,omitempty"`
+
+	// EntryMetadata is opaque metadata, base64 encoded in JSON.
+	EntryMetadata []byte `json:"entry-metadata,omitempty"`
+}
+
+// jsonState is the JSON representation of State with the prefix leaf.
+type jsonState struct {
+	Prefix string `json:"prefix"`
+	State
+}
+
+type jsonEntry struct {
+	Prefix string    `json:"prefix"`
+	State  jsonState `json:"state"`
+}
+
+type jsonAfts struct {
+	IPv4 *struct {
+		Entries []jsonEntry `json:"ipv4-entry,omitempty"`
+	} `json:"ipv4-unicast,omitempty"`
+	IPv6 *struct {
+		Entries []jsonEntry `json:"ipv6-entry,omitempty"`
+	} `json:"ipv6-unicast,omitempty"`
+}
+
+// Marshal encodes the table as openconfig-aft JSON, the entries are
+// in canonical CIDR sort order.
+func Marshal(t *bart.Table[State]) ([]byte, error) {
+	var afts jsonAfts
+
+	if t != nil {
+		if t.Size4() != 0 {
+			afts.IPv4 = &struct {
+				Entries []jsonEntry `json:"ipv4-entry,omitempty"`
+			}{Entries: entries(t.AllSorted4())}
+		}
+		if t.Size6() != 0 {
+			afts.IPv6 = &struct {
+				Entries []jsonEntry `json:"ipv6-entry,omitempty"`
+			}{Entries: entries(t.AllSorted6())}
+		}
+	}
+
+	return json.Marshal(map[string]jsonAfts{"openconfig-aft:afts": afts})
+}
+
+// entries converts the table entries to the JSON representation.
+func entries(seq func(yield func(netip.Prefix, State) bool)) []jsonEntry {
+	var result []jsonEntry
+	for pfx, st := range seq {
+		s := pfx.String()
+		result = append(result, jsonEntry{Prefix: s, State: jsonState{Prefix: s, State: st}})
+	}
+	return result
+}
+
+// Unmarshal decodes openconfig-aft JSON and inserts all entries into t.
+// The top-level container may be qualified with the module name
+// ("openconfig-aft:afts") or not ("afts").
+//
+// Entries with invalid prefixes or with a prefix of the wrong address
+// family for their list are rejected with an error.
+// On error the table is left unchanged.
+func Unmarshal(data []byte, t *bart.Table[State]) error {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return err
+	}
+
+	raw, ok := top["openconfig-aft:afts"]
+	if !ok {
+		if raw, ok = top["afts"]; !ok {
+			return errors.New("openconfig: missing afts container")
+		}
+	}
+
+	var afts jsonAfts
+	if err := json.Unmarshal(raw, &afts); err != nil {
+		return err
+	}
+
+	var pvs []bart.PrefixValue[State]
+	var err error
+
+	if afts.IPv4 != nil {
+		if pvs, err = appendEntries(pvs, afts.IPv4.Entries, true); err != nil {
+			return err
+		}
+	}
+	if afts.IPv6 != nil {
+		if pvs, err = appendEntries(pvs, afts.IPv6.Entries, false); err != nil {
+			return err
+		}
+	}
+
+	for _, pv := range pvs {
+		t.Insert(pv.Prefix, pv.Value)
+	}
+
+	return nil
+}
+
+// appendEntries validates the entries of one address family
+// and appends them to dst.
+func appendEntries(dst []bart.PrefixValue[State], entries []jsonEntry, is4 bool) ([]bart.PrefixValue[State], error) {
+	for _, e := range entries {
+		pfx, err := netip.ParsePrefix(e.Prefix)
+		if err != nil {
+			return nil, fmt.Errorf("openconfig: %w", err)
+		}
+		if pfx.Addr().Is4() != is4 {
+			return nil, fmt.Errorf("openconfig: prefix %s in wrong address family list", pfx)
+		}
+		dst = append(dst, bart.PrefixValue[State]{Prefix: pfx, Value: e.State.State})
+	}
+	return dst, nil
+}
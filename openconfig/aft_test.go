@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package openconfig
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/admpub/bart"
+)
+
+var mpp = netip.MustParsePrefix
+
+func TestMarshalRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(bart.Table[State])
+	tbl.Insert(mpp("10.0.0.0/8"), State{NextHopGroup: 42, OriginProtocol: "openconfig-policy-types:BGP"})
+	tbl.Insert(mpp("0.0.0.0/0"), State{NextHopGroup: 1})
+	tbl.Insert(mpp("2001:db8::/32"), State{NextHopGroup: 7, EntryMetadata: []byte{1, 2, 3}})
+
+	data, err := Marshal(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"openconfig-aft:afts":{"ipv4-unicast":{"ipv4-entry":[` +
+		`{"prefix":"0.0.0.0/0","state":{"prefix":"0.0.0.0/0","next-hop-group":"1"}},` +
+		`{"prefix":"10.0.0.0/8","state":{"prefix":"10.0.0.0/8","next-hop-group":"42","origin-protocol":"openconfig-policy-types:BGP"}}]},` +
+		`"ipv6-unicast":{"ipv6-entry":[` +
+		`{"prefix":"2001:db8::/32","state":{"prefix":"2001:db8::/32","next-hop-group":"7","entry-metadata":"AQID"}}]}}}`
+
+	if string(data) != want {
+		t.Fatalf("Marshal\ngot:  %s\nwant: %s", data, want)
+	}
+
+	got := new(bart.Table[State])
+	if err := Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(tbl) {
+		t.Fatal("Unmarshal(Marshal(tbl)), expected equal tables")
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		size    int
+		wantErr string
+	}{
+		{
+			name: "unqualified container",
+			data: `{"afts":{"ipv4-unicast":{"ipv4-entry":[{"prefix":"10.0.0.0/8","state":{"next-hop-group":"1","unknown":true}}]}}}`,
+			size: 1,
+		},
+		{
+			name:    "missing container",
+			data:    `{"interfaces":{}}`,
+			wantErr: "missing afts",
+		},
+		{
+			name:    "invalid prefix",
+			data:    `{"afts":{"ipv4-unicast":{"ipv4-entry":[{"prefix":"10.0.0.0/33"}]}}}`,
+			wantErr: "out of range",
+		},
+		{
+			name:    "wrong family",
+			data:    `{"afts":{"ipv6-unicast":{"ipv6-entry":[{"prefix":"10.0.0.0/8"}]}}}`,
+			wantErr: "wrong address family",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tbl := new(bart.Table[State])
+			err := Unmarshal([]byte(tt.data), tbl)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal, got err: %v, want: %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tbl.Size() != tt.size {
+				t.Fatalf("Size, got: %d, want: %d", tbl.Size(), tt.size)
+			}
+		})
+	}
+}
+
+func TestUnmarshalUnchanged(t *testing.T) {
+	t.Parallel()
+
+	// the bad entry is in the middle of the IPv6 list,
+	// after all IPv4 entries and a valid IPv6 entry
+	data := `{"afts":{
+  "ipv4-unicast":{"ipv4-entry":[{"prefix":"10.0.0.0/8"},{"prefix":"192.168.0.0/16"}]},
+  "ipv6-unicast":{"ipv6-entry":[{"prefix":"2001:db8::/32"},{"prefix":"2001:db8::/129"},{"prefix":"fe80::/10"}]}}}`
+
+	tbl := new(bart.Table[State])
+	tbl.Insert(mpp("172.16.0.0/12"), State{NextHopGroup: 1})
+	want := tbl.Clone()
+
+	if err := Unmarshal([]byte(data), tbl); err == nil {
+		t.Fatal("Unmarshal, expected error")
+	}
+	if !tbl.Equal(want) {
+		t.Fatal("failed Unmarshal modified the table")
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// overlayVal is a patch entry, either a value or a tombstone.
+type overlayVal[V any] struct {
+	val     V
+	deleted bool
+}
+
+// Overlay combines a large, frozen base table with a small mutable
+// patch table. Inserts and deletes only touch the patch, deletes
+// of base prefixes are recorded as tombstones.
+//
+// Reads consult both tables with correct longest prefix match semantics.
+// [Overlay.Merge] periodically folds the patch into a new base, sharing
+// all unchanged nodes with the previous base.
+//
+// The base table must not be modified while in use by the Overlay.
+// The same concurrency rules apply as for [Table].
+type Overlay[V any] struct {
+	base  *Table[V]
+	patch Table[overlayVal[V]]
+	size  int
+}
+
+// NewOverlay returns an overlay with an empty patch on top of base.
+// A nil base is treated as the empty table.
+func NewOverlay[V any](base *Table[V]) *Overlay[V] {
+	if base == nil {
+		base = new(Table[V])
+	}
+	return &Overlay[V]{base: base, size: base.Size()}
+}
+
+// Base returns the current base table.
+func (o *Overlay[V]) Base() *Table[V] {
+	return o.base
+}
+
+// PatchSize returns the number of entries in the patch, tombstones included.
+func (o *Overlay[V]) PatchSize() int {
+	return o.patch.Size()
+}
+
+// Size returns the prefix count of the combined view.
+func (o *Overlay[V]) Size() int {
+	return o.size
+}
+
+// Insert adds or updates a prefix-value pair in the patch.
+func (o *Overlay[V]) Insert(pfx netip.Prefix, val V) {
+	if !pfx.IsValid() {
+		return
+	}
+	pfx = pfx.Masked()
+
+	_, inBase := o.base.Get(pfx)
+
+	o.patch.Modify(pfx, func(old overlayVal[V], exists bool) (_ overlayVal[V], del bool) {
+		if exists && old.deleted || !exists && !inBase {
+			o.size++
+		}
+		return overlayVal[V]{val: val}, false
+	})
+}
+
+// Delete removes the prefix from the combined view. Prefixes of the
+// base table are shadowed by a tombstone in the patch.
+func (o *Overlay[V]) Delete(pfx netip.Prefix) {
+	if !pfx.IsValid() {
+		return
+	}
+	pfx = pfx.Masked()
+
+	_, inBase := o.base.Get(pfx)
+
+	o.patch.Modify(pfx, func(old overlayVal[V], exists bool) (_ overlayVal[V], del bool) {
+		switch {
+		case exists && old.deleted:
+			return old, false
+		case exists || inBase:
+			o.size--
+		}
+
+		if !inBase {
+			return old, true
+		}
+		return overlayVal[V]{deleted: true}, false
+	})
+}
+
+// Get returns the value of the exact prefix pfx in the combined view.
+func (o *Overlay[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	if pv, exists := o.patch.Get(pfx); exists {
+		return pv.val, !pv.deleted
+	}
+	return o.base.Get(pfx)
+}
+
+// Contains reports whether any prefix of the combined view covers ip.
+func (o *Overlay[V]) Contains(ip netip.Addr) bool {
+	_, ok := o.Lookup(ip)
+	return ok
+}
+
+// Lookup performs a longest prefix match for ip in the combined view.
+func (o *Overlay[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	if !ip.IsValid() {
+		return
+	}
+	return o.LookupPrefix(netip.PrefixFrom(ip, ip.BitLen()))
+}
+
+// LookupPrefix performs a longest prefix match for pfx in the combined view.
+func (o *Overlay[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	_, val, ok = o.LookupPrefixLPM(pfx)
+	return
+}
+
+// LookupPrefixLPM is similar to [Overlay.LookupPrefix],
+// but it returns the lpm prefix in addition to value,ok.
+func (o *Overlay[V]) LookupPrefixLPM(pfx netip.Prefix) (lpmPfx netip.Prefix, val V, ok bool) {
+	if !pfx.IsValid() {
+		return
+	}
+
+	// most specific live patch entry, tombstones are skipped
+	for p, pv := range o.patch.Supernets(pfx) {
+		if !pv.deleted {
+			lpmPfx, val, ok = p, pv.val, true
+			break
+		}
+	}
+
+	// most specific base entry not overridden by the patch
+	for p, bv := range o.base.Supernets(pfx) {
+		if ok && p.Bits() <= lpmPfx.Bits() {
+			break
+		}
+		if _, shadowed := o.patch.Get(p); shadowed {
+			continue
+		}
+		return p, bv, true
+	}
+
+	return
+}
+
+// All returns an iterator over all prefix–value pairs of the combined view.
+// The patch entries are yielded first, the iteration order is unspecified.
+func (o *Overlay[V]) All() iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		for pfx, pv := range o.patch.All() {
+			if !pv.deleted && !yield(pfx, pv.val) {
+				return
+			}
+		}
+		for pfx, val := range o.base.All() {
+			if _, shadowed := o.patch.Get(pfx); shadowed {
+				continue
+			}
+			if !yield(pfx, val) {
+				return
+			}
+		}
+	}
+}
+
+// Merge folds the patch into a new base table and clears the patch.
+// The previous base is not modified, the new base shares all
+// unchanged nodes with it, see [Table.InsertPersist].
+func (o *Overlay[V]) Merge() *Table[V] {
+	base := o.base
+	for pfx, pv := range o.patch.All() {
+		if pv.deleted {
+			base = base.DeletePersist(pfx)
+		} else {
+			base = base.InsertPersist(pfx, pv.val)
+		}
+	}
+
+	o.base = base
+	o.patch = Table[overlayVal[V]]{}
+	o.size = base.Size()
+
+	return base
+}
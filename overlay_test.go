@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestOverlayCompare(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	pfxs := random.RealWorldPrefixes(prng, 2_000)
+
+	base := new(Table[int])
+	for i, pfx := range pfxs[:1_500] {
+		base.Insert(pfx, i)
+	}
+
+	want := base.Clone()
+	o := NewOverlay(base)
+
+	// mixed inserts and deletes, on base and on new prefixes
+	for i := range 1_000 {
+		pfx := pfxs[prng.IntN(len(pfxs))]
+		if prng.IntN(2) == 0 {
+			o.Insert(pfx, -i)
+			want.Insert(pfx, -i)
+		} else {
+			o.Delete(pfx)
+			want.Delete(pfx)
+		}
+	}
+
+	if base.Size() != 1_500 {
+		t.Fatalf("base was modified, Size: %d", base.Size())
+	}
+
+	if o.Size() != want.Size() {
+		t.Fatalf("Size, got: %d, want: %d", o.Size(), want.Size())
+	}
+
+	for _, pfx := range pfxs {
+		gotVal, gotOk := o.Get(pfx)
+		wantVal, wantOk := want.Get(pfx)
+		if gotVal != wantVal || gotOk != wantOk {
+			t.Fatalf("Get(%s), got: (%d, %v), want: (%d, %v)", pfx, gotVal, gotOk, wantVal, wantOk)
+		}
+	}
+
+	for range 10_000 {
+		ip := random.IP(prng)
+		gotVal, gotOk := o.Lookup(ip)
+		wantVal, wantOk := want.Lookup(ip)
+		if gotVal != wantVal || gotOk != wantOk {
+			t.Fatalf("Lookup(%s), got: (%d, %v), want: (%d, %v)", ip, gotVal, gotOk, wantVal, wantOk)
+		}
+		if o.Contains(ip) != want.Contains(ip) {
+			t.Fatalf("Contains(%s), got: %v", ip, !want.Contains(ip))
+		}
+	}
+
+	for _, pfx := range pfxs {
+		gotPfx, gotVal, gotOk := o.LookupPrefixLPM(pfx)
+		wantPfx, wantVal, wantOk := want.LookupPrefixLPM(pfx)
+		if gotPfx != wantPfx || gotVal != wantVal || gotOk != wantOk {
+			t.Fatalf("LookupPrefixLPM(%s), got: (%s, %d, %v), want: (%s, %d, %v)",
+				pfx, gotPfx, gotVal, gotOk, wantPfx, wantVal, wantOk)
+		}
+	}
+
+	all := new(Table[int])
+	for pfx, val := range o.All() {
+		all.Insert(pfx, val)
+	}
+	if !all.Equal(want) || all.Size() != want.Size() {
+		t.Fatal("All, not equal to the reference table")
+	}
+
+	merged := o.Merge()
+	if !merged.Equal(want) {
+		t.Fatal("Merge, not equal to the reference table")
+	}
+	if o.PatchSize() != 0 || o.Base() != merged || o.Size() != want.Size() {
+		t.Fatalf("after Merge, PatchSize: %d, Size: %d", o.PatchSize(), o.Size())
+	}
+	if base.Size() != 1_500 {
+		t.Fatalf("Merge modified the previous base, Size: %d", base.Size())
+	}
+}
+
+func TestOverlayTombstone(t *testing.T) {
+	t.Parallel()
+
+	base := new(Table[string])
+	base.Insert(mpp("10.0.0.0/8"), "base8")
+	base.Insert(mpp("10.1.0.0/16"), "base16")
+
+	o := NewOverlay(base)
+	o.Delete(mpp("10.1.0.0/16"))
+	o.Delete(mpp("10.1.0.0/16"))
+	o.Delete(mpp("192.168.0.0/16")) // not in base, no tombstone
+
+	if o.PatchSize() != 1 || o.Size() != 1 {
+		t.Fatalf("PatchSize: %d, Size: %d, want: 1, 1", o.PatchSize(), o.Size())
+	}
+
+	// the tombstone shadows the base, the less specific base route matches
+	if val, _ := o.Lookup(mpa("10.1.1.1")); val != "base8" {
+		t.Errorf("Lookup, got: %q, want: %q", val, "base8")
+	}
+
+	o.Insert(mpp("10.1.0.0/16"), "patch16")
+	o.Insert(mpp("10.1.1.0/24"), "patch24")
+	o.Delete(mpp("10.1.1.0/24"))
+
+	if val, _ := o.Lookup(mpa("10.1.1.1")); val != "patch16" {
+		t.Errorf("Lookup, got: %q, want: %q", val, "patch16")
+	}
+	if o.Size() != 2 {
+		t.Errorf("Size, got: %d, want: 2", o.Size())
+	}
+
+	nilBase := NewOverlay[string](nil)
+	if _, ok := nilBase.Lookup(mpa("10.0.0.1")); ok || nilBase.Size() != 0 {
+		t.Errorf("overlay on nil base, expected empty")
+	}
+	if _, ok := nilBase.Lookup(netip.Addr{}); ok {
+		t.Errorf("Lookup(invalid), expected !ok")
+	}
+}
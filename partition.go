@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// LPMRegion is a part of the address space with a uniform longest-prefix match,
+// see [Table.LPMPartition].
+type LPMRegion[V any] struct {
+	// Prefix is the region of the address space.
+	Prefix netip.Prefix
+
+	// Route is the stored prefix winning the longest-prefix match
+	// for all addresses in Prefix, the zero value if no route matches.
+	Route netip.Prefix
+
+	// Value is the value of Route.
+	Value V
+}
+
+// LPMPartition returns an iterator over the partition of the address space
+// of pfx by the longest-prefix match winner, e.g. to analyze what will
+// actually happen to the traffic in this block.
+//
+// The regions are the minimal set of CIDR blocks with a uniform winner,
+// yielded in natural CIDR sort order. Regions not covered by any route
+// are yielded with the zero Route.
+func (t *Table[V]) LPMPartition(pfx netip.Prefix) iter.Seq[LPMRegion[V]] {
+	return func(yield func(LPMRegion[V]) bool) {
+		if t == nil || !pfx.IsValid() {
+			return
+		}
+		pfx = pfx.Masked()
+
+		var win LPMRegion[V]
+		if lpm, val, ok := t.LookupPrefixLPM(pfx); ok {
+			win = LPMRegion[V]{Route: lpm, Value: val}
+		}
+
+		t.partitionRec(pfx, win, yield)
+	}
+}
+
+// partitionRec yields the regions of pfx, win is the winner for pfx
+// inherited from the covering routes.
+func (t *Table[V]) partitionRec(pfx netip.Prefix, win LPMRegion[V], yield func(LPMRegion[V]) bool) bool {
+	if val, ok := t.Get(pfx); ok {
+		win = LPMRegion[V]{Route: pfx, Value: val}
+	}
+
+	if !t.hasMoreSpecifics(pfx) {
+		win.Prefix = pfx
+		return yield(win)
+	}
+
+	lo, hi := splitPrefix(pfx)
+	return t.partitionRec(lo, win, yield) && t.partitionRec(hi, win, yield)
+}
+
+// hasMoreSpecifics reports whether any stored prefix is strictly covered by pfx.
+func (t *Table[V]) hasMoreSpecifics(pfx netip.Prefix) bool {
+	for sub := range t.Subnets(pfx) {
+		if sub != pfx {
+			return true
+		}
+	}
+	return false
+}
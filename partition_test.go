@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestLPMPartition(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[string])
+	tbl.Insert(mpp("10.0.0.0/8"), "a")
+	tbl.Insert(mpp("10.64.0.0/10"), "b")
+	tbl.Insert(mpp("10.64.1.0/24"), "c")
+	tbl.Insert(mpp("11.0.0.0/16"), "d")
+
+	type region struct {
+		pfx, route string
+		val        string
+	}
+
+	tests := []struct {
+		query string
+		want  []region
+	}{
+		{
+			query: "10.0.0.0/8",
+			want: []region{
+				{"10.0.0.0/10", "10.0.0.0/8", "a"},
+				{"10.64.0.0/24", "10.64.0.0/10", "b"},
+				{"10.64.1.0/24", "10.64.1.0/24", "c"},
+				{"10.64.2.0/23", "10.64.0.0/10", "b"},
+				{"10.64.4.0/22", "10.64.0.0/10", "b"},
+				{"10.64.8.0/21", "10.64.0.0/10", "b"},
+				{"10.64.16.0/20", "10.64.0.0/10", "b"},
+				{"10.64.32.0/19", "10.64.0.0/10", "b"},
+				{"10.64.64.0/18", "10.64.0.0/10", "b"},
+				{"10.64.128.0/17", "10.64.0.0/10", "b"},
+				{"10.65.0.0/16", "10.64.0.0/10", "b"},
+				{"10.66.0.0/15", "10.64.0.0/10", "b"},
+				{"10.68.0.0/14", "10.64.0.0/10", "b"},
+				{"10.72.0.0/13", "10.64.0.0/10", "b"},
+				{"10.80.0.0/12", "10.64.0.0/10", "b"},
+				{"10.96.0.0/11", "10.64.0.0/10", "b"},
+				{"10.128.0.0/9", "10.0.0.0/8", "a"},
+			},
+		},
+		{
+			query: "10.64.1.128/25",
+			want:  []region{{"10.64.1.128/25", "10.64.1.0/24", "c"}},
+		},
+		{
+			query: "11.0.0.0/15",
+			want: []region{
+				{"11.0.0.0/16", "11.0.0.0/16", "d"},
+				{"11.1.0.0/16", "", ""},
+			},
+		},
+		{
+			query: "2001:db8::/32",
+			want:  []region{{"2001:db8::/32", "", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		var got []region
+		for r := range tbl.LPMPartition(mpp(tt.query)) {
+			route := ""
+			if r.Route.IsValid() {
+				route = r.Route.String()
+			}
+			got = append(got, region{r.Prefix.String(), route, r.Value})
+		}
+
+		if len(got) != len(tt.want) {
+			t.Fatalf("LPMPartition(%s), got: %v, want: %v", tt.query, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("LPMPartition(%s)[%d], got: %v, want: %v", tt.query, i, got[i], tt.want[i])
+			}
+		}
+	}
+
+	// early stop
+	n := 0
+	for range tbl.LPMPartition(mpp("10.0.0.0/8")) {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("LPMPartition, early stop, got: %d", n)
+	}
+
+	var nilTbl *Table[string]
+	for range nilTbl.LPMPartition(mpp("10.0.0.0/8")) {
+		t.Error("LPMPartition on nil table, expected no regions")
+	}
+}
+
+func TestLPMPartitionRandom(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes4(prng, 1_000)
+
+	tbl := new(Table[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+	}
+
+	for _, query := range []netip.Prefix{mpp("0.0.0.0/0"), pfxs[0], netip.PrefixFrom(pfxs[1].Addr(), 8)} {
+		query = query.Masked()
+
+		next := query.Addr()
+		for r := range tbl.LPMPartition(query) {
+			// regions are contiguous and cover the query
+			if r.Prefix.Addr() != next {
+				t.Fatalf("LPMPartition(%s), gap before %s", query, r.Prefix)
+			}
+			next = lastAddr(r.Prefix).Next()
+
+			// the winner is the LPM of every address in the region
+			for _, ip := range []netip.Addr{r.Prefix.Addr(), lastAddr(r.Prefix)} {
+				lpm, val, ok := tbl.LookupPrefixLPM(netip.PrefixFrom(ip, ip.BitLen()))
+				if ok != r.Route.IsValid() || lpm != r.Route || val != r.Value {
+					t.Fatalf("LPMPartition(%s), region %s, got: %s, want: %s", query, r.Prefix, r.Route, lpm)
+				}
+			}
+		}
+
+		if want := lastAddr(query).Next(); next != want {
+			t.Fatalf("LPMPartition(%s), ends at %s, want: %s", query, next, want)
+		}
+	}
+}
+
+// lastAddr returns the last address of pfx.
+func lastAddr(pfx netip.Prefix) netip.Addr {
+	a16 := pfx.Addr().As16()
+	bits := pfx.Bits()
+	if pfx.Addr().Is4() {
+		bits += 96
+	}
+	for i := bits; i < 128; i++ {
+		a16[i/8] |= 0x80 >> (i % 8)
+	}
+
+	addr := netip.AddrFrom16(a16)
+	if pfx.Addr().Is4() {
+		addr = addr.Unmap()
+	}
+	return addr
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+)
+
+// PromoteMode selects the value for the gaps in [Table.DeleteAndPromote].
+type PromoteMode int
+
+const (
+	// PromoteAggregate materializes the value of the deleted aggregate
+	// onto the gaps, lookups are unchanged.
+	PromoteAggregate PromoteMode = iota
+
+	// PromoteParent materializes the value of the closest covering route
+	// of the deleted aggregate onto the gaps. Without a covering route
+	// no gaps are inserted.
+	PromoteParent
+)
+
+// DeleteAndPromote deletes the aggregate pfx and re-homes the lookups for
+// the address space of pfx that isn't covered by any of its more-specifics,
+// the gaps. The gaps are inserted as the minimal set of prefixes with the
+// value selected by mode, e.g. to preserve the forwarding behavior during
+// staged withdrawals of an aggregate.
+//
+// The inserted gap prefixes are returned in natural CIDR sort order.
+// If pfx isn't in the table, nothing is deleted and nil is returned.
+// An aggregate without any more-specifics is a single gap.
+func (t *Table[V]) DeleteAndPromote(pfx netip.Prefix, mode PromoteMode) (gaps []netip.Prefix) {
+	if !pfx.IsValid() {
+		return nil
+	}
+	pfx = pfx.Masked()
+
+	val, exists := t.GetAndDelete(pfx)
+	if !exists {
+		return nil
+	}
+
+	if mode == PromoteParent {
+		var ok bool
+		if _, val, ok = t.LookupPrefixLPM(pfx); !ok {
+			return nil
+		}
+	}
+
+	gaps = t.appendGaps(gaps, pfx)
+	for _, gap := range gaps {
+		t.Insert(gap, val)
+	}
+
+	return gaps
+}
+
+// appendGaps appends the parts of pfx not covered by any stored
+// prefix of length >= pfx.Bits(), in natural CIDR sort order.
+func (t *Table[V]) appendGaps(gaps []netip.Prefix, pfx netip.Prefix) []netip.Prefix {
+	if _, ok := t.Get(pfx); ok {
+		return gaps
+	}
+
+	hasSubnets := false
+	for range t.Subnets(pfx) {
+		hasSubnets = true
+		break
+	}
+
+	if !hasSubnets {
+		return append(gaps, pfx)
+	}
+
+	lo, hi := splitPrefix(pfx)
+	gaps = t.appendGaps(gaps, lo)
+	return t.appendGaps(gaps, hi)
+}
+
+// splitPrefix splits pfx into its two halves, pfx must not be a host route.
+func splitPrefix(pfx netip.Prefix) (lo, hi netip.Prefix) {
+	bits := pfx.Bits()
+	lo = netip.PrefixFrom(pfx.Addr(), bits+1)
+
+	a16 := pfx.Addr().As16()
+	pos := bits
+	if pfx.Addr().Is4() {
+		pos += 96
+	}
+	a16[pos/8] |= 0x80 >> (pos % 8)
+
+	addr := netip.AddrFrom16(a16)
+	if pfx.Addr().Is4() {
+		addr = addr.Unmap()
+	}
+
+	return lo, netip.PrefixFrom(addr, bits+1)
+}
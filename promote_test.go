@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestDeleteAndPromote(t *testing.T) {
+	t.Parallel()
+
+	newTable := func() *Table[string] {
+		tbl := new(Table[string])
+		tbl.Insert(mpp("0.0.0.0/0"), "default")
+		tbl.Insert(mpp("10.0.0.0/8"), "agg")
+		tbl.Insert(mpp("10.0.0.0/10"), "a")
+		tbl.Insert(mpp("10.128.0.0/9"), "b")
+		tbl.Insert(mpp("10.128.1.0/24"), "c")
+		return tbl
+	}
+
+	wantGaps := []netip.Prefix{mpp("10.64.0.0/10")}
+
+	tbl := newTable()
+	gaps := tbl.DeleteAndPromote(mpp("10.0.0.0/8"), PromoteAggregate)
+	if !slices.Equal(gaps, wantGaps) {
+		t.Fatalf("DeleteAndPromote, got gaps: %v, want: %v", gaps, wantGaps)
+	}
+	if _, ok := tbl.Get(mpp("10.0.0.0/8")); ok {
+		t.Fatal("aggregate still in table")
+	}
+	if val, _ := tbl.Lookup(mpa("10.100.0.1")); val != "agg" {
+		t.Errorf("Lookup in gap, got: %q, want: %q", val, "agg")
+	}
+
+	tbl = newTable()
+	gaps = tbl.DeleteAndPromote(mpp("10.0.0.0/8"), PromoteParent)
+	if !slices.Equal(gaps, wantGaps) {
+		t.Fatalf("DeleteAndPromote, got gaps: %v, want: %v", gaps, wantGaps)
+	}
+	if val, _ := tbl.Get(mpp("10.64.0.0/10")); val != "default" {
+		t.Errorf("gap value, got: %q, want: %q", val, "default")
+	}
+
+	// no covering route for the parent mode
+	tbl = newTable()
+	tbl.Delete(mpp("0.0.0.0/0"))
+	if gaps = tbl.DeleteAndPromote(mpp("10.0.0.0/8"), PromoteParent); gaps != nil {
+		t.Errorf("DeleteAndPromote without parent, got gaps: %v", gaps)
+	}
+
+	// not in table
+	if gaps = tbl.DeleteAndPromote(mpp("11.0.0.0/8"), PromoteAggregate); gaps != nil {
+		t.Errorf("DeleteAndPromote of missing prefix, got gaps: %v", gaps)
+	}
+	if gaps = tbl.DeleteAndPromote(netip.Prefix{}, PromoteAggregate); gaps != nil {
+		t.Errorf("DeleteAndPromote of invalid prefix, got gaps: %v", gaps)
+	}
+}
+
+func TestDeleteAndPromoteLookupsUnchanged(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	for range 100 {
+		tbl := new(Table[int])
+		agg := random.Prefix(prng)
+		for agg.Bits() > 120 || agg.Addr().Is4() && agg.Bits() > 24 {
+			agg = random.Prefix(prng)
+		}
+		tbl.Insert(agg, -1)
+
+		// random more-specifics of agg
+		for i := range 20 {
+			bits := agg.Bits() + 1 + prng.IntN(8)
+			addr := random.IP4(prng)
+			if agg.Addr().Is6() {
+				addr = random.IP6(prng)
+			}
+			sub := netip.PrefixFrom(addr, bits).Masked()
+
+			// move sub into agg
+			sub = mergeBits(agg, sub)
+			tbl.Insert(sub, i)
+		}
+
+		before := tbl.Clone()
+		tbl.DeleteAndPromote(agg, PromoteAggregate)
+
+		for range 1_000 {
+			ip := random.IP4(prng)
+			if agg.Addr().Is6() {
+				ip = random.IP6(prng)
+			}
+			ip = mergeBits(agg, netip.PrefixFrom(ip, ip.BitLen())).Addr()
+
+			want, _ := before.Lookup(ip)
+			if got, _ := tbl.Lookup(ip); got != want {
+				t.Fatalf("Lookup(%s) after DeleteAndPromote(%s), got: %d, want: %d", ip, agg, got, want)
+			}
+		}
+	}
+}
+
+// mergeBits returns p with the leading bits replaced by the bits of agg,
+// the address families must match.
+func mergeBits(agg, p netip.Prefix) netip.Prefix {
+	a := agg.Addr().As16()
+	b := p.Addr().As16()
+
+	off := 0
+	if agg.Addr().Is4() {
+		off = 96
+	}
+	for i := off; i < off+agg.Bits(); i++ {
+		mask := byte(0x80 >> (i % 8))
+		b[i/8] = b[i/8]&^mask | a[i/8]&mask
+	}
+
+	addr := netip.AddrFrom16(b)
+	if agg.Addr().Is4() {
+		addr = addr.Unmap()
+	}
+	return netip.PrefixFrom(addr, max(agg.Bits(), p.Bits())).Masked()
+}
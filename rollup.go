@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"cmp"
+	"net/netip"
+)
+
+// number is the constraint for the predefined rollup aggregations.
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Rollup returns a new table with the same prefixes as t, the value of each
+// prefix is the aggregation of its own value and the values of all its
+// more-specifics in t, e.g. the total traffic per /16 from per-/24 counters.
+//
+// agg must be associative and commutative like sum, min or max,
+// see [RollupSum], [RollupMin] and [RollupMax].
+//
+// The rollup is computed in one bottom-up pass over the sorted prefixes.
+// Only stored prefixes get a result, insert the covering prefixes
+// of interest with a neutral value (e.g. 0 for sums) beforehand.
+func Rollup[V any](t *Table[V], agg func(a, b V) V) *Table[V] {
+	result := new(Table[V])
+	if t == nil {
+		return result
+	}
+
+	type item struct {
+		pfx netip.Prefix
+		acc V
+	}
+
+	// stack of open ancestors, the top is the innermost
+	var stack []item
+
+	// pop finalizes the top of stack and folds it into the new top, if any
+	pop := func() {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		result.Insert(top.pfx, top.acc)
+		if len(stack) > 0 {
+			parent := &stack[len(stack)-1]
+			parent.acc = agg(parent.acc, top.acc)
+		}
+	}
+
+	// in natural CIDR sort order a prefix precedes all its more-specifics
+	for pfx, val := range t.AllSorted() {
+		for len(stack) > 0 && !coversPrefix(stack[len(stack)-1].pfx, pfx) {
+			pop()
+		}
+		stack = append(stack, item{pfx, val})
+	}
+
+	for len(stack) > 0 {
+		pop()
+	}
+
+	return result
+}
+
+// coversPrefix reports whether the prefix super contains the prefix sub.
+func coversPrefix(super, sub netip.Prefix) bool {
+	return super.Bits() <= sub.Bits() && super.Contains(sub.Addr())
+}
+
+// RollupSum is [Rollup] with the sum as aggregation.
+func RollupSum[V number](t *Table[V]) *Table[V] {
+	return Rollup(t, func(a, b V) V { return a + b })
+}
+
+// RollupMin is [Rollup] with the minimum as aggregation.
+func RollupMin[V cmp.Ordered](t *Table[V]) *Table[V] {
+	return Rollup(t, func(a, b V) V { return min(a, b) })
+}
+
+// RollupMax is [Rollup] with the maximum as aggregation.
+func RollupMax[V cmp.Ordered](t *Table[V]) *Table[V] {
+	return Rollup(t, func(a, b V) V { return max(a, b) })
+}
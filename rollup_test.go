@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestRollup(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 0)
+	tbl.Insert(mpp("10.1.0.0/16"), 0)
+	tbl.Insert(mpp("10.1.1.0/24"), 5)
+	tbl.Insert(mpp("10.1.2.0/24"), 7)
+	tbl.Insert(mpp("10.2.0.0/16"), 3)
+	tbl.Insert(mpp("10.2.3.0/24"), 1)
+	tbl.Insert(mpp("11.0.0.0/8"), 100)
+	tbl.Insert(mpp("2001:db8::/32"), 2)
+	tbl.Insert(mpp("2001:db8:1::/48"), 4)
+
+	tests := []struct {
+		name string
+		got  *Table[int]
+		want map[string]int
+	}{
+		{
+			name: "sum",
+			got:  RollupSum(tbl),
+			want: map[string]int{
+				"10.0.0.0/8": 16, "10.1.0.0/16": 12, "10.1.1.0/24": 5, "10.1.2.0/24": 7,
+				"10.2.0.0/16": 4, "10.2.3.0/24": 1, "11.0.0.0/8": 100,
+				"2001:db8::/32": 6, "2001:db8:1::/48": 4,
+			},
+		},
+		{
+			name: "max",
+			got:  RollupMax(tbl),
+			want: map[string]int{
+				"10.0.0.0/8": 7, "10.1.0.0/16": 7, "10.1.1.0/24": 5, "10.1.2.0/24": 7,
+				"10.2.0.0/16": 3, "10.2.3.0/24": 1, "11.0.0.0/8": 100,
+				"2001:db8::/32": 4, "2001:db8:1::/48": 4,
+			},
+		},
+		{
+			name: "min",
+			got:  RollupMin(tbl),
+			want: map[string]int{
+				"10.0.0.0/8": 0, "10.1.0.0/16": 0, "10.1.1.0/24": 5, "10.1.2.0/24": 7,
+				"10.2.0.0/16": 1, "10.2.3.0/24": 1, "11.0.0.0/8": 100,
+				"2001:db8::/32": 2, "2001:db8:1::/48": 4,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.got.Size() != len(tt.want) {
+			t.Errorf("%s: Size, got: %d, want: %d", tt.name, tt.got.Size(), len(tt.want))
+		}
+		for s, want := range tt.want {
+			if got, _ := tt.got.Get(mpp(s)); got != want {
+				t.Errorf("%s: %s, got: %d, want: %d", tt.name, s, got, want)
+			}
+		}
+	}
+
+	if RollupSum[int](nil).Size() != 0 {
+		t.Error("Rollup(nil), expected empty table")
+	}
+}
+
+func TestRollupCompare(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(Table[int])
+	for _, pfx := range random.RealWorldPrefixes(prng, 2_000) {
+		tbl.Insert(pfx, prng.IntN(1_000))
+	}
+
+	got := RollupSum(tbl)
+
+	// naive reference, sum over all subnets
+	for pfx := range tbl.All() {
+		want := 0
+		for _, val := range tbl.Subnets(pfx) {
+			want += val
+		}
+		if val, _ := got.Get(pfx); val != want {
+			t.Fatalf("RollupSum, %s, got: %d, want: %d", pfx, val, want)
+		}
+	}
+}
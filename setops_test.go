@@ -0,0 +1,220 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+// setOpsTables returns two random tables with partially common prefixes.
+func setOpsTables(n int) (a, b *Table[int]) {
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	a, b = new(Table[int]), new(Table[int])
+	for i, pfx := range pfxs {
+		switch i % 3 {
+		case 0:
+			a.Insert(pfx, i)
+		case 1:
+			b.Insert(pfx, -i)
+		default:
+			a.Insert(pfx, i)
+			b.Insert(pfx, -i)
+		}
+	}
+	return a, b
+}
+
+func TestIntersect(t *testing.T) {
+	t.Parallel()
+
+	a, b := setOpsTables(workLoadN())
+	aClone, bClone := a.Clone(), b.Clone()
+
+	sum := func(_ netip.Prefix, x, y int) int { return x*1000 + y }
+
+	got := a.Intersect(b, sum)
+	for pfx, val := range got.All() {
+		va, okA := a.Get(pfx)
+		vb, okB := b.Get(pfx)
+		if !okA || !okB {
+			t.Fatalf("Intersect, %s not in both tables", pfx)
+		}
+		if val != sum(pfx, va, vb) {
+			t.Fatalf("Intersect, %s, got: %d, want: %d", pfx, val, sum(pfx, va, vb))
+		}
+	}
+
+	n := 0
+	for pfx := range a.All() {
+		if _, ok := b.Get(pfx); ok {
+			n++
+		}
+	}
+	if got.Size() != n {
+		t.Fatalf("Intersect, Size, got: %d, want: %d", got.Size(), n)
+	}
+
+	// argument order is kept for the merge, regardless of the table sizes
+	big := b.Clone()
+	for i := range a.Size() {
+		big.Insert(netip.PrefixFrom(netip.AddrFrom4([4]byte{0, 0, byte(i >> 8), byte(i)}), 32), 0)
+	}
+	if !a.Intersect(big, sum).Equal(got) {
+		t.Fatal("Intersect, result depends on the table sizes")
+	}
+
+	// nil merge keeps the value of the receiver
+	for pfx, val := range b.Intersect(a, nil).All() {
+		if want, _ := b.Get(pfx); val != want {
+			t.Fatalf("Intersect(nil merge), %s, got: %d, want: %d", pfx, val, want)
+		}
+	}
+
+	if !a.Equal(aClone) || !b.Equal(bClone) {
+		t.Fatal("Intersect modified its operands")
+	}
+
+	if a.Intersect(nil, nil).Size() != 0 {
+		t.Fatal("Intersect(nil), expected empty table")
+	}
+}
+
+func TestDifference(t *testing.T) {
+	t.Parallel()
+
+	a, b := setOpsTables(workLoadN())
+	aClone, bClone := a.Clone(), b.Clone()
+
+	got := a.Difference(b)
+
+	want := new(Table[int])
+	for pfx, val := range a.All() {
+		if _, ok := b.Get(pfx); !ok {
+			want.Insert(pfx, val)
+		}
+	}
+
+	if !got.Equal(want) {
+		t.Fatal("Difference, result differs from expected table")
+	}
+
+	// reconcile a copy of b to a
+	c := b.Clone()
+	for pfx := range b.Difference(a).All() {
+		c.Delete(pfx)
+	}
+	for pfx, val := range got.All() {
+		c.Insert(pfx, val)
+	}
+	for pfx := range a.All() {
+		if _, ok := c.Get(pfx); !ok {
+			t.Fatalf("reconciled table misses %s", pfx)
+		}
+	}
+	if c.Size() != a.Size() {
+		t.Fatalf("reconciled table, Size, got: %d, want: %d", c.Size(), a.Size())
+	}
+
+	if !a.Difference(nil).Equal(a) {
+		t.Fatal("Difference(nil), expected a copy of the receiver")
+	}
+	if a.Difference(a).Size() != 0 {
+		t.Fatal("Difference with itself, expected empty table")
+	}
+
+	if !a.Equal(aClone) || !b.Equal(bClone) {
+		t.Fatal("Difference modified its operands")
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	t.Parallel()
+
+	a, b := setOpsTables(workLoadN())
+
+	onlyA, onlyB := a.SymmetricDifference(b)
+
+	if !onlyA.Equal(a.Difference(b)) || !onlyB.Equal(b.Difference(a)) {
+		t.Fatal("SymmetricDifference, expected the differences in both directions")
+	}
+
+	common := a.Intersect(b, nil)
+	if onlyA.Size()+common.Size() != a.Size() || onlyB.Size()+common.Size() != b.Size() {
+		t.Fatalf("SymmetricDifference, sizes don't add up: %d + %d != %d", onlyA.Size(), common.Size(), a.Size())
+	}
+	if onlyA.Overlaps(common) && onlyA.Intersect(common, nil).Size() != 0 {
+		t.Fatal("SymmetricDifference, result contains common prefixes")
+	}
+
+	onlyA, onlyB = a.SymmetricDifference(nil)
+	if !onlyA.Equal(a) || onlyB.Size() != 0 {
+		t.Fatal("SymmetricDifference(nil), expected (a, empty)")
+	}
+}
+
+func TestUnionWith(t *testing.T) {
+	t.Parallel()
+
+	a, b := setOpsTables(workLoadN())
+	aClone := a.Clone()
+
+	// keep the lowest metric
+	lowest := func(existing, incoming int) int { return min(existing, incoming) }
+	a.UnionWith(b, lowest)
+
+	for pfx, val := range a.All() {
+		va, okA := aClone.Get(pfx)
+		vb, okB := b.Get(pfx)
+
+		var want int
+		switch {
+		case okA && okB:
+			want = min(va, vb)
+		case okA:
+			want = va
+		default:
+			want = vb
+		}
+
+		if val != want {
+			t.Fatalf("UnionWith, %s, got: %d, want: %d", pfx, val, want)
+		}
+	}
+
+	union := aClone.Clone()
+	union.Union(b)
+	if a.Size() != union.Size() {
+		t.Fatalf("UnionWith, Size, got: %d, want: %d", a.Size(), union.Size())
+	}
+
+	// nil merge is Union
+	c := aClone.Clone()
+	c.UnionWith(b, nil)
+	if !c.Equal(union) {
+		t.Fatal("UnionWith(nil merge), expected equal to Union")
+	}
+
+	c.UnionWith(nil, lowest)
+	c.UnionWith(c, lowest)
+	if !c.Equal(union) {
+		t.Fatal("UnionWith with nil or itself, expected no change")
+	}
+
+	// with itself, every value is merged with itself
+	c.UnionWith(c, func(existing, incoming int) int { return existing + incoming })
+	for pfx, val := range union.All() {
+		if got, _ := c.Get(pfx); got != 2*val {
+			t.Fatalf("UnionWith itself, %s, got: %d, want: %d", pfx, got, 2*val)
+		}
+	}
+	if c.Size() != union.Size() {
+		t.Fatalf("UnionWith itself, Size, got: %d, want: %d", c.Size(), union.Size())
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+	"time"
+)
+
+// Tombstone is a soft-deleted entry of a [SoftTable].
+type Tombstone[V any] struct {
+	Value   V         // value at deletion
+	Deleted time.Time // time of the deletion
+}
+
+// SoftTable is a routing table with soft deletes: Delete marks an entry
+// as tombstoned, it is excluded from lookups and iteration but retained
+// with its value and deletion time until purged, e.g. for grace periods,
+// undo and audit of recently removed routes in operational tooling.
+//
+// The zero value is ready to use. The same concurrency rules apply as for [Table].
+type SoftTable[V any] struct {
+	live  Table[V]
+	tombs Table[Tombstone[V]]
+
+	// time source, replaceable in tests
+	now func() time.Time
+}
+
+// clock returns the current time.
+func (s *SoftTable[V]) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+// Insert adds or updates a prefix-value pair, a tombstone for pfx is removed.
+func (s *SoftTable[V]) Insert(pfx netip.Prefix, val V) {
+	s.live.Insert(pfx, val)
+	s.tombs.Delete(pfx)
+}
+
+// Delete marks the exact prefix pfx as tombstoned.
+func (s *SoftTable[V]) Delete(pfx netip.Prefix) {
+	val, ok := s.live.GetAndDelete(pfx)
+	if !ok {
+		return
+	}
+	s.tombs.Insert(pfx, Tombstone[V]{Value: val, Deleted: s.clock()})
+}
+
+// Restore reverts the soft delete of the exact prefix pfx and reports
+// whether a tombstone was found.
+func (s *SoftTable[V]) Restore(pfx netip.Prefix) bool {
+	tomb, ok := s.tombs.GetAndDelete(pfx)
+	if !ok {
+		return false
+	}
+	s.live.Insert(pfx, tomb.Value)
+	return true
+}
+
+// Tombstone returns the tombstone of the exact prefix pfx.
+func (s *SoftTable[V]) Tombstone(pfx netip.Prefix) (Tombstone[V], bool) {
+	return s.tombs.Get(pfx)
+}
+
+// Tombstones returns an iterator over all tombstoned prefixes.
+func (s *SoftTable[V]) Tombstones() iter.Seq2[netip.Prefix, Tombstone[V]] {
+	return s.tombs.All()
+}
+
+// Purge finally removes all tombstones and returns their number.
+func (s *SoftTable[V]) Purge() int {
+	n := s.tombs.Size()
+	s.tombs.Clear()
+	return n
+}
+
+// PurgeOlderThan finally removes the tombstones deleted before the
+// last d, e.g. after a grace period, and returns their number.
+func (s *SoftTable[V]) PurgeOlderThan(d time.Duration) int {
+	cutoff := s.clock().Add(-d)
+
+	var expired []netip.Prefix
+	for pfx, tomb := range s.tombs.All() {
+		if tomb.Deleted.Before(cutoff) {
+			expired = append(expired, pfx)
+		}
+	}
+
+	for _, pfx := range expired {
+		s.tombs.Delete(pfx)
+	}
+	return len(expired)
+}
+
+// Get returns the value of the exact live prefix pfx, see [Table.Get].
+func (s *SoftTable[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	return s.live.Get(pfx)
+}
+
+// Contains reports whether any live prefix covers ip, see [Table.Contains].
+func (s *SoftTable[V]) Contains(ip netip.Addr) bool {
+	return s.live.Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip over the live
+// prefixes, see [Table.Lookup].
+func (s *SoftTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return s.live.Lookup(ip)
+}
+
+// LookupPrefix performs a longest prefix match for pfx over the live
+// prefixes, see [Table.LookupPrefix].
+func (s *SoftTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	return s.live.LookupPrefix(pfx)
+}
+
+// Size returns the live prefix count.
+func (s *SoftTable[V]) Size() int {
+	return s.live.Size()
+}
+
+// TombstoneCount returns the number of tombstones.
+func (s *SoftTable[V]) TombstoneCount() int {
+	return s.tombs.Size()
+}
+
+// All returns an iterator over all live prefix–value pairs, see [Table.All].
+func (s *SoftTable[V]) All() iter.Seq2[netip.Prefix, V] {
+	return s.live.All()
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSoftTable(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	s := new(SoftTable[string])
+	s.now = func() time.Time { return now }
+
+	s.Insert(mpp("10.0.0.0/8"), "a")
+	s.Insert(mpp("10.1.0.0/16"), "b")
+	s.Insert(mpp("11.0.0.0/8"), "c")
+
+	s.Delete(mpp("10.1.0.0/16"))
+	s.Delete(mpp("12.0.0.0/8")) // not present
+
+	if s.Size() != 2 || s.TombstoneCount() != 1 {
+		t.Fatalf("Size/TombstoneCount, got: %d/%d, want: 2/1", s.Size(), s.TombstoneCount())
+	}
+
+	// tombstones are excluded from lookups
+	if val, ok := s.Lookup(mpa("10.1.2.3")); !ok || val != "a" {
+		t.Errorf("Lookup, got: (%q, %v), want: (a, true)", val, ok)
+	}
+	if _, ok := s.Get(mpp("10.1.0.0/16")); ok {
+		t.Error("Get of tombstoned prefix, expected false")
+	}
+
+	tomb, ok := s.Tombstone(mpp("10.1.0.0/16"))
+	if !ok || tomb.Value != "b" || !tomb.Deleted.Equal(start) {
+		t.Errorf("Tombstone, got: (%v, %v)", tomb, ok)
+	}
+
+	// undo
+	if !s.Restore(mpp("10.1.0.0/16")) || s.Restore(mpp("10.1.0.0/16")) {
+		t.Error("Restore, expected true once")
+	}
+	if val, _ := s.Lookup(mpa("10.1.2.3")); val != "b" {
+		t.Errorf("Lookup after Restore, got: %q, want: b", val)
+	}
+
+	// re-insert removes the tombstone
+	s.Delete(mpp("11.0.0.0/8"))
+	s.Insert(mpp("11.0.0.0/8"), "d")
+	if s.TombstoneCount() != 0 {
+		t.Errorf("Insert, tombstone not removed")
+	}
+
+	// grace period
+	s.Delete(mpp("10.0.0.0/8"))
+	now = start.Add(time.Hour)
+	s.Delete(mpp("11.0.0.0/8"))
+
+	if n := s.PurgeOlderThan(30 * time.Minute); n != 1 {
+		t.Errorf("PurgeOlderThan, got: %d, want: 1", n)
+	}
+	for pfx := range s.Tombstones() {
+		if pfx != mpp("11.0.0.0/8") {
+			t.Errorf("Tombstones, got: %s, want: 11.0.0.0/8", pfx)
+		}
+	}
+
+	if n := s.Purge(); n != 1 || s.TombstoneCount() != 0 {
+		t.Errorf("Purge, got: %d, want: 1", n)
+	}
+	if s.Restore(mpp("11.0.0.0/8")) {
+		t.Error("Restore after Purge, expected false")
+	}
+
+	for pfx := range s.All() {
+		if pfx != mpp("10.1.0.0/16") {
+			t.Errorf("All, got: %s, want: 10.1.0.0/16", pfx)
+		}
+	}
+}
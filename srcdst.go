@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// SrcDst is a destination/source prefix pair, the key of a [SrcDstTable].
+type SrcDst struct {
+	Dst netip.Prefix
+	Src netip.Prefix
+}
+
+// SrcDstTable is a two-dimensional classifier for source-specific
+// or policy-based routing, the rules are keyed by pairs of destination
+// and source prefixes.
+//
+// The classifier is a destination table with a nested source table per
+// destination prefix. [SrcDstTable.Lookup] selects the longest matching
+// destination prefix first and then the longest matching source prefix
+// below it. If no source prefix matches, the lookup backtracks to the
+// next shorter destination prefix, the same semantics as the
+// source-specific routes of the Linux kernel (RFC 8043).
+//
+// The zero value is ready to use. The same concurrency rules apply as for [Table].
+type SrcDstTable[V any] struct {
+	dst  Table[*Table[V]]
+	size int
+}
+
+// Insert adds or updates the rule for the prefix pair.
+// Invalid prefixes are ignored.
+func (s *SrcDstTable[V]) Insert(dst, src netip.Prefix, val V) {
+	if !dst.IsValid() || !src.IsValid() {
+		return
+	}
+
+	srcTbl, ok := s.dst.Get(dst)
+	if !ok {
+		srcTbl = new(Table[V])
+		s.dst.Insert(dst, srcTbl)
+	}
+
+	before := srcTbl.Size()
+	srcTbl.Insert(src, val)
+	s.size += srcTbl.Size() - before
+}
+
+// Delete removes the rule for the exact prefix pair.
+func (s *SrcDstTable[V]) Delete(dst, src netip.Prefix) {
+	srcTbl, ok := s.dst.Get(dst)
+	if !ok {
+		return
+	}
+
+	before := srcTbl.Size()
+	srcTbl.Delete(src)
+	s.size += srcTbl.Size() - before
+
+	if srcTbl.Size() == 0 {
+		s.dst.Delete(dst)
+	}
+}
+
+// Get returns the value of the rule for the exact prefix pair.
+func (s *SrcDstTable[V]) Get(dst, src netip.Prefix) (val V, exists bool) {
+	if srcTbl, ok := s.dst.Get(dst); ok {
+		return srcTbl.Get(src)
+	}
+	return
+}
+
+// Lookup classifies a packet by its destination and source address,
+// longest destination match first, then longest source match.
+func (s *SrcDstTable[V]) Lookup(dst, src netip.Addr) (val V, ok bool) {
+	_, _, val, ok = s.LookupSrcDst(dst, src)
+	return
+}
+
+// LookupSrcDst is like [SrcDstTable.Lookup], but also returns the
+// matching prefix pair.
+func (s *SrcDstTable[V]) LookupSrcDst(dst, src netip.Addr) (dstPfx, srcPfx netip.Prefix, val V, ok bool) {
+	if !dst.IsValid() || !src.IsValid() {
+		return
+	}
+
+	// the destination matches in reverse-CIDR order, backtrack
+	// to the next shorter destination prefix on a source miss
+	for dstPfx, srcTbl := range s.dst.Supernets(netip.PrefixFrom(dst, dst.BitLen())) {
+		if srcPfx, val, ok = srcTbl.LookupPrefixLPM(netip.PrefixFrom(src, src.BitLen())); ok {
+			return dstPfx, srcPfx, val, ok
+		}
+	}
+
+	return
+}
+
+// Size returns the number of rules.
+func (s *SrcDstTable[V]) Size() int {
+	return s.size
+}
+
+// All returns an iterator over all rules, ordered by destination
+// prefix and then by source prefix in natural CIDR sort order.
+func (s *SrcDstTable[V]) All() iter.Seq2[SrcDst, V] {
+	return func(yield func(SrcDst, V) bool) {
+		for dstPfx, srcTbl := range s.dst.AllSorted() {
+			for srcPfx, val := range srcTbl.AllSorted() {
+				if !yield(SrcDst{Dst: dstPfx, Src: srcPfx}, val) {
+					return
+				}
+			}
+		}
+	}
+}
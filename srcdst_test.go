@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestSrcDstTable(t *testing.T) {
+	t.Parallel()
+
+	var s SrcDstTable[string]
+
+	if _, ok := s.Lookup(mpa("10.0.0.1"), mpa("192.168.0.1")); ok || s.Size() != 0 {
+		t.Fatal("zero value, expected empty SrcDstTable")
+	}
+
+	s.Insert(mpp("0.0.0.0/0"), mpp("0.0.0.0/0"), "default")
+	s.Insert(mpp("0.0.0.0/0"), mpp("192.168.1.0/24"), "isp-b")
+	s.Insert(mpp("10.0.0.0/8"), mpp("192.168.0.0/16"), "vpn")
+	s.Insert(mpp("10.1.0.0/16"), mpp("172.16.0.0/12"), "lab")
+	s.Insert(mpp("10.1.0.0/16"), mpp("172.16.0.0/12"), "lab") // update
+	s.Insert(netip.Prefix{}, mpp("172.16.0.0/12"), "invalid")
+
+	if s.Size() != 4 {
+		t.Fatalf("Size, got: %d, want: 4", s.Size())
+	}
+
+	tests := []struct {
+		dst, src string
+		want     string
+		wantDst  string
+		wantSrc  string
+	}{
+		{"10.1.2.3", "172.16.1.1", "lab", "10.1.0.0/16", "172.16.0.0/12"},
+		// backtrack from 10.1.0.0/16 to 10.0.0.0/8
+		{"10.1.2.3", "192.168.1.1", "vpn", "10.0.0.0/8", "192.168.0.0/16"},
+		{"10.2.0.1", "192.168.1.1", "vpn", "10.0.0.0/8", "192.168.0.0/16"},
+		// backtrack to the default destination
+		{"10.1.2.3", "8.8.8.8", "default", "0.0.0.0/0", "0.0.0.0/0"},
+		{"8.8.8.8", "192.168.1.7", "isp-b", "0.0.0.0/0", "192.168.1.0/24"},
+	}
+
+	for _, tt := range tests {
+		dstPfx, srcPfx, val, ok := s.LookupSrcDst(mpa(tt.dst), mpa(tt.src))
+		if !ok || val != tt.want || dstPfx != mpp(tt.wantDst) || srcPfx != mpp(tt.wantSrc) {
+			t.Errorf("LookupSrcDst(%s, %s), got: (%s, %s, %s, %v), want: (%s, %s, %s, true)",
+				tt.dst, tt.src, dstPfx, srcPfx, val, ok, tt.wantDst, tt.wantSrc, tt.want)
+		}
+	}
+
+	if _, ok := s.Lookup(mpa("2001:db8::1"), mpa("2001:db8::2")); ok {
+		t.Error("Lookup, IPv6, expected no match")
+	}
+
+	if val, ok := s.Get(mpp("10.0.0.0/8"), mpp("192.168.0.0/16")); !ok || val != "vpn" {
+		t.Errorf("Get, got: (%s, %v), want: (vpn, true)", val, ok)
+	}
+
+	var got []SrcDst
+	for key := range s.All() {
+		got = append(got, key)
+	}
+	if len(got) != 4 || got[0] != (SrcDst{mpp("0.0.0.0/0"), mpp("0.0.0.0/0")}) {
+		t.Errorf("All, got: %v", got)
+	}
+
+	s.Delete(mpp("10.1.0.0/16"), mpp("172.16.0.0/12"))
+	s.Delete(mpp("10.1.0.0/16"), mpp("172.16.0.0/12"))
+	if val, _ := s.Lookup(mpa("10.1.2.3"), mpa("172.16.1.1")); val != "default" || s.Size() != 3 {
+		t.Errorf("Delete, got: %s, size: %d, want: default, 3", val, s.Size())
+	}
+	if _, ok := s.dst.Get(mpp("10.1.0.0/16")); ok {
+		t.Error("Delete, empty source table not removed")
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// The streaming snapshot format, all entries in natural CIDR sort order:
+//
+//	magic    [4]byte  "BART"
+//	version  byte     3
+//	chunks   repeated:
+//	  count    uvarint  number of entries in the chunk, 0 ends the stream
+//	  length   uvarint  payload length
+//	  payload  [length]byte, count entries as in version 1
+//	  crc      uint32   big-endian CRC-32C of the payload
+const (
+	binaryVersionStream = 3
+
+	// payload size, after which a chunk is flushed
+	streamChunkSize = 64 << 10
+
+	// sanity limit for a single chunk, a chunk is flushed after the
+	// entry exceeding the chunk size, which is at most one maximum
+	// sized value plus the entry head
+	streamMaxChunkLen = streamChunkSize + binaryMaxValueLen + 64
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WriteTo implements the [io.WriterTo] interface. It streams a snapshot
+// of the table to w in chunks of bounded size, every chunk protected by
+// a CRC, without building the whole snapshot in memory.
+//
+// The values are encoded as in [Table.MarshalBinary].
+// A nil table is written as empty snapshot.
+func (t *Table[V]) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+
+	if _, err := cw.Write(append([]byte(binaryMagic), binaryVersionStream)); err != nil {
+		return cw.n, err
+	}
+
+	var payload, head []byte
+	var count int
+
+	flush := func() error {
+		head = binary.AppendUvarint(head[:0], uint64(count))
+		head = binary.AppendUvarint(head, uint64(len(payload)))
+		payload = binary.BigEndian.AppendUint32(payload, crc32.Checksum(payload, crc32cTable))
+
+		if _, err := cw.Write(head); err != nil {
+			return err
+		}
+		if _, err := cw.Write(payload); err != nil {
+			return err
+		}
+
+		payload, count = payload[:0], 0
+		return nil
+	}
+
+	for pfx, val := range t.AllSorted() {
+		raw, err := encodeBinaryValue(val)
+		if err != nil {
+			return cw.n, fmt.Errorf("bart: encode value of %s: %w", pfx, err)
+		}
+		if len(raw) > binaryMaxValueLen {
+			return cw.n, fmt.Errorf("bart: value of %s exceeds length limit", pfx)
+		}
+
+		addr := pfx.Addr().AsSlice()
+		payload = append(payload, byte(len(addr)))
+		payload = append(payload, addr...)
+		payload = append(payload, byte(pfx.Bits()))
+		payload = binary.AppendUvarint(payload, uint64(len(raw)))
+		payload = append(payload, raw...)
+		count++
+
+		if len(payload) >= streamChunkSize {
+			if err := flush(); err != nil {
+				return cw.n, err
+			}
+		}
+	}
+
+	if count > 0 {
+		if err := flush(); err != nil {
+			return cw.n, err
+		}
+	}
+
+	// end of stream
+	_, err := cw.Write([]byte{0})
+	return cw.n, err
+}
+
+// ReadFrom implements the [io.ReaderFrom] interface, the content of the
+// table is replaced by the snapshot streamed with [Table.WriteTo].
+// The input is read chunk by chunk, up to the end of the snapshot, not
+// beyond. On error the table is left unchanged.
+//
+// The values are decoded as in [Table.UnmarshalBinary].
+func (t *Table[V]) ReadFrom(r io.Reader) (int64, error) {
+	if t == nil {
+		return 0, errors.New("bart: ReadFrom on nil table")
+	}
+
+	cr := &countReader{r: r}
+
+	var hdr [len(binaryMagic) + 1]byte
+	if _, err := io.ReadFull(cr, hdr[:]); err != nil {
+		return cr.n, fmt.Errorf("bart: read header: %w", err)
+	}
+	if string(hdr[:len(binaryMagic)]) != binaryMagic {
+		return cr.n, errors.New("bart: not a binary snapshot")
+	}
+	if hdr[len(binaryMagic)] != binaryVersionStream {
+		return cr.n, fmt.Errorf("bart: unsupported snapshot version %d", hdr[len(binaryMagic)])
+	}
+
+	tbl := new(Table[V])
+
+	var payload []byte
+	for chunk := 0; ; chunk++ {
+		count, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return cr.n, fmt.Errorf("bart: chunk %d: read count: %w", chunk, noEOF(err))
+		}
+		if count == 0 {
+			break
+		}
+
+		length, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return cr.n, fmt.Errorf("bart: chunk %d: read length: %w", chunk, noEOF(err))
+		}
+		if length > streamMaxChunkLen || count > length {
+			return cr.n, fmt.Errorf("bart: chunk %d: invalid length %d for %d entries", chunk, length, count)
+		}
+
+		if uint64(cap(payload)) < length+4 {
+			payload = make([]byte, length+4)
+		}
+		payload = payload[:length+4]
+		if _, err := io.ReadFull(cr, payload); err != nil {
+			return cr.n, fmt.Errorf("bart: chunk %d: read payload: %w", chunk, noEOF(err))
+		}
+
+		if crc32.Checksum(payload[:length], crc32cTable) != binary.BigEndian.Uint32(payload[length:]) {
+			return cr.n, fmt.Errorf("bart: chunk %d: checksum mismatch", chunk)
+		}
+
+		if err := readStreamChunk(tbl, bytes.NewReader(payload[:length]), count); err != nil {
+			return cr.n, fmt.Errorf("bart: chunk %d: %w", chunk, err)
+		}
+	}
+
+	t.root4, t.root6 = tbl.root4, tbl.root6
+	t.size4, t.size6 = tbl.size4, tbl.size6
+
+	return cr.n, nil
+}
+
+// readStreamChunk inserts count entries of a chunk payload into tbl.
+func readStreamChunk[V any](tbl *Table[V], br *bytes.Reader, count uint64) error {
+	for range count {
+		pfx, valLen, err := readBinaryEntryHead(br)
+		if err != nil {
+			return noEOF(err)
+		}
+		if valLen > uint64(br.Len()) {
+			return fmt.Errorf("value of %s: %w", pfx, io.ErrUnexpectedEOF)
+		}
+
+		raw := make([]byte, valLen)
+		_, _ = br.Read(raw)
+
+		val, err := decodeBinaryValue[V](raw)
+		if err != nil {
+			return fmt.Errorf("decode value of %s: %w", pfx, err)
+		}
+
+		tbl.Insert(pfx, val)
+	}
+
+	if br.Len() != 0 {
+		return errors.New("trailing data")
+	}
+	return nil
+}
+
+// noEOF converts a premature io.EOF into io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// countWriter counts the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countReader counts the bytes read from r. ReadByte reads
+// without buffering, the input is never consumed beyond the
+// end of the snapshot.
+type countReader struct {
+	r   io.Reader
+	n   int64
+	one [1]byte
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countReader) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(c, c.one[:]); err != nil {
+		return 0, err
+	}
+	return c.one[0], nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestStreamRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(Table[string])
+	for i, pfx := range random.RealWorldPrefixes(prng, 10_000) {
+		tbl.Insert(pfx, strings.Repeat("x", i%17)+strconv.Itoa(i))
+	}
+
+	var buf bytes.Buffer
+	n, err := tbl.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo, got n: %d, written: %d", n, buf.Len())
+	}
+	if buf.Len() < 2*streamChunkSize {
+		t.Fatalf("WriteTo, want several chunks, got only %d bytes", buf.Len())
+	}
+
+	// the snapshot is followed by other data, not consumed by ReadFrom
+	size := buf.Len()
+	buf.WriteString("trailer")
+
+	got := new(Table[string])
+	got.Insert(mpp("0.0.0.0/0"), "replaced")
+
+	n, err = got.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(size) {
+		t.Fatalf("ReadFrom, got n: %d, want: %d", n, size)
+	}
+	if buf.String() != "trailer" {
+		t.Fatalf("ReadFrom, consumed beyond the snapshot, rest: %q", buf.String())
+	}
+
+	if !got.Equal(tbl) {
+		t.Fatal("ReadFrom(WriteTo(tbl)), expected equal tables")
+	}
+}
+
+func TestStreamInt(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("10.0.0.0/8"), -1)
+	tbl.Insert(mpp("2001:db8::/32"), 1<<40)
+
+	var buf bytes.Buffer
+	if _, err := tbl.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got := new(Table[int])
+	if _, err := got.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(tbl) {
+		t.Fatal("ReadFrom(WriteTo(tbl)), expected equal tables")
+	}
+}
+
+func TestStreamEmpty(t *testing.T) {
+	t.Parallel()
+
+	for _, tbl := range []*Table[int]{nil, new(Table[int])} {
+		var buf bytes.Buffer
+		if _, err := tbl.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		got := new(Table[int])
+		got.Insert(mpp("::/0"), 1)
+		if _, err := got.ReadFrom(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if got.Size() != 0 {
+			t.Fatalf("ReadFrom(empty), got size %d, want 0", got.Size())
+		}
+	}
+
+	var nilTbl *Table[int]
+	if _, err := nilTbl.ReadFrom(strings.NewReader("")); err == nil {
+		t.Fatal("ReadFrom on nil table, expected error")
+	}
+}
+
+func TestStreamCorrupt(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	tbl := new(Table[uint32])
+	for i, pfx := range random.RealWorldPrefixes(prng, 20_000) {
+		tbl.Insert(pfx, uint32(i))
+	}
+
+	var buf bytes.Buffer
+	if _, err := tbl.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"magic", append([]byte("XXXX"), data[4:]...)},
+		{"version", append([]byte("BART\x01"), data[5:]...)},
+		{"truncated", data[:len(data)/2]},
+		{"no end", data[:len(data)-1]},
+		{"flipped bit", func() []byte {
+			c := bytes.Clone(data)
+			c[len(c)/2] ^= 0x10
+			return c
+		}()},
+	}
+
+	for _, tt := range tests {
+		got := new(Table[uint32])
+		got.Insert(mpp("10.0.0.0/8"), 42)
+		clone := got.Clone()
+
+		if _, err := got.ReadFrom(bytes.NewReader(tt.data)); err == nil {
+			t.Errorf("ReadFrom(%s), expected error", tt.name)
+		}
+		if !got.Equal(clone) {
+			t.Errorf("ReadFrom(%s), table modified on error", tt.name)
+		}
+	}
+}
+
+func TestStreamWriteError(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+
+	if _, err := tbl.WriteTo(failWriter{}); err == nil {
+		t.Fatal("WriteTo, expected error")
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, io.ErrClosedPipe }
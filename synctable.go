@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"net/netip"
+	"sync"
+)
+
+// SyncTable is a [Table] safe for concurrent use by multiple goroutines,
+// guarded by a [sync.RWMutex]. Readers run in parallel, writers are
+// exclusive.
+//
+// Besides the common methods of [Table], SyncTable provides atomic
+// multi-operation helpers, e.g. [SyncTable.Swap] or [SyncTable.Batch].
+// Iterators are not exposed, they would hold the lock for an unbounded
+// time; iterate within [SyncTable.View] or over a [SyncTable.Clone].
+//
+// For read-heavy workloads with rare writers consider [AtomicTable]
+// with lock-free reads.
+//
+// The zero value is ready to use. A SyncTable must not be copied after
+// first use.
+type SyncTable[V any] struct {
+	mu  sync.RWMutex
+	tbl Table[V]
+}
+
+// Insert adds or updates a prefix-value pair, see [Table.Insert].
+func (s *SyncTable[V]) Insert(pfx netip.Prefix, val V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tbl.Insert(pfx, val)
+}
+
+// Delete removes the exact prefix pfx, see [Table.Delete].
+func (s *SyncTable[V]) Delete(pfx netip.Prefix) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tbl.Delete(pfx)
+}
+
+// GetAndDelete removes the exact prefix pfx and returns its value,
+// see [Table.GetAndDelete].
+func (s *SyncTable[V]) GetAndDelete(pfx netip.Prefix) (val V, exists bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tbl.GetAndDelete(pfx)
+}
+
+// Modify inserts, updates or deletes the prefix pfx atomically,
+// see [Table.Modify]. The callback is called with the lock held
+// and must not call back into s.
+func (s *SyncTable[V]) Modify(pfx netip.Prefix, cb func(val V, ok bool) (newVal V, del bool)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tbl.Modify(pfx, cb)
+}
+
+// Update inserts or updates the prefix pfx atomically, see [Table.Update].
+// The callback is called with the lock held and must not call back into s.
+func (s *SyncTable[V]) Update(pfx netip.Prefix, cb func(val V, ok bool) V) (newVal V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tbl.Update(pfx, cb)
+}
+
+// InsertIfAbsent inserts the prefix pfx with val only if pfx is not
+// yet in the table and reports whether val was inserted.
+// Otherwise the stored value is returned unchanged.
+func (s *SyncTable[V]) InsertIfAbsent(pfx netip.Prefix, val V) (actual V, inserted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.tbl.Get(pfx); ok {
+		return old, false
+	}
+	s.tbl.Insert(pfx, val)
+
+	return val, pfx.IsValid()
+}
+
+// Swap replaces the prefix oldPfx by newPfx with val in one atomic step,
+// concurrent readers see either the old or the new state, e.g. for
+// renumbering or re-aggregation of a route.
+// It returns the previous value of oldPfx, if any.
+func (s *SyncTable[V]) Swap(oldPfx, newPfx netip.Prefix, val V) (oldVal V, existed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldVal, existed = s.tbl.GetAndDelete(oldPfx)
+	s.tbl.Insert(newPfx, val)
+
+	return oldVal, existed
+}
+
+// Batch calls fn with the underlying table and the write lock held,
+// all mutations in fn are applied atomically for other users of s.
+//
+// The table must not be retained or used after fn returns and fn must
+// not call back into s.
+func (s *SyncTable[V]) Batch(fn func(t *Table[V])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.tbl)
+}
+
+// View calls fn with the underlying table and the read lock held,
+// e.g. for consistent multi-step reads or iterations.
+//
+// The table must only be read, not retained after fn returns and fn
+// must not call back into s.
+func (s *SyncTable[V]) View(fn func(t *Table[V])) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fn(&s.tbl)
+}
+
+// Get returns the value of the exact prefix pfx, see [Table.Get].
+func (s *SyncTable[V]) Get(pfx netip.Prefix) (val V, exists bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Get(pfx)
+}
+
+// Contains reports whether any stored prefix matches ip, see [Table.Contains].
+func (s *SyncTable[V]) Contains(ip netip.Addr) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (s *SyncTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Lookup(ip)
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (s *SyncTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.LookupPrefix(pfx)
+}
+
+// LookupPrefixLPM is like LookupPrefix, but also returns the matching
+// prefix, see [Table.LookupPrefixLPM].
+func (s *SyncTable[V]) LookupPrefixLPM(pfx netip.Prefix) (lpmPfx netip.Prefix, val V, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.LookupPrefixLPM(pfx)
+}
+
+// OverlapsPrefix reports whether any stored prefix overlaps pfx,
+// see [Table.OverlapsPrefix].
+func (s *SyncTable[V]) OverlapsPrefix(pfx netip.Prefix) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.OverlapsPrefix(pfx)
+}
+
+// Size returns the prefix count.
+func (s *SyncTable[V]) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Size()
+}
+
+// Size4 returns the IPv4 prefix count.
+func (s *SyncTable[V]) Size4() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Size4()
+}
+
+// Size6 returns the IPv6 prefix count.
+func (s *SyncTable[V]) Size6() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Size6()
+}
+
+// Clone returns a deep copy of the table taken under the read lock,
+// the values are cloned if V implements the Cloner interface.
+// The copy is owned by the caller and is not synchronized.
+func (s *SyncTable[V]) Clone() *Table[V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Clone()
+}
+
+// Union merges o into s atomically, see [Table.Union].
+// The table o must not be modified concurrently.
+func (s *SyncTable[V]) Union(o *Table[V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tbl.Union(o)
+}
+
+// Replace replaces the content of s by a clone of t atomically,
+// e.g. after a full reload. A nil t clears the table.
+func (s *SyncTable[V]) Replace(t *Table[V]) {
+	// clone outside of the lock, t is not owned by s.
+	// The nodes of the clone are private, no sharing with t.
+	next := t.Clone()
+	if next == nil {
+		next = new(Table[V])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tbl.moveFrom(next)
+}
+
+// Clear removes all prefixes.
+func (s *SyncTable[V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tbl.Clear()
+}
+
+// Stats returns the table statistics, see [Table.Stats].
+func (s *SyncTable[V]) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tbl.Stats()
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestSyncTable(t *testing.T) {
+	t.Parallel()
+
+	var s SyncTable[int]
+	s.Insert(mpp("10.0.0.0/8"), 1)
+	s.Insert(mpp("2001:db8::/32"), 2)
+
+	if val, ok := s.Lookup(mpa("10.1.2.3")); !ok || val != 1 {
+		t.Errorf("Lookup, got: (%d, %v), want: (1, true)", val, ok)
+	}
+	if s.Size() != 2 || s.Size4() != 1 || s.Size6() != 1 {
+		t.Errorf("Size, got: %d/%d/%d, want: 2/1/1", s.Size(), s.Size4(), s.Size6())
+	}
+
+	if val, ins := s.InsertIfAbsent(mpp("10.0.0.0/8"), 3); ins || val != 1 {
+		t.Errorf("InsertIfAbsent, existing, got: (%d, %v), want: (1, false)", val, ins)
+	}
+	if val, ins := s.InsertIfAbsent(mpp("10.1.0.0/16"), 4); !ins || val != 4 {
+		t.Errorf("InsertIfAbsent, absent, got: (%d, %v), want: (4, true)", val, ins)
+	}
+	if _, ins := s.InsertIfAbsent(netip.Prefix{}, 5); ins {
+		t.Error("InsertIfAbsent, invalid prefix, expected not inserted")
+	}
+
+	if old, ok := s.Swap(mpp("10.1.0.0/16"), mpp("10.1.0.0/17"), 5); !ok || old != 4 {
+		t.Errorf("Swap, got: (%d, %v), want: (4, true)", old, ok)
+	}
+	if _, ok := s.Get(mpp("10.1.0.0/16")); ok {
+		t.Error("Swap, old prefix still present")
+	}
+	if val, ok := s.Get(mpp("10.1.0.0/17")); !ok || val != 5 {
+		t.Errorf("Swap, new prefix, got: (%d, %v), want: (5, true)", val, ok)
+	}
+
+	s.Batch(func(tbl *Table[int]) {
+		tbl.Delete(mpp("10.1.0.0/17"))
+		tbl.Insert(mpp("192.168.0.0/16"), 6)
+	})
+
+	var got []netip.Prefix
+	s.View(func(tbl *Table[int]) {
+		for pfx := range tbl.AllSorted() {
+			got = append(got, pfx)
+		}
+	})
+	want := []netip.Prefix{mpp("10.0.0.0/8"), mpp("192.168.0.0/16"), mpp("2001:db8::/32")}
+	if !slices.Equal(got, want) {
+		t.Fatalf("View, got: %v, want: %v", got, want)
+	}
+
+	// the clone is detached from s
+	clone := s.Clone()
+	s.Delete(mpp("10.0.0.0/8"))
+	if _, ok := clone.Get(mpp("10.0.0.0/8")); !ok {
+		t.Error("Clone, not detached from the SyncTable")
+	}
+
+	// replace with a private copy of clone
+	s.Replace(clone)
+	clone.Delete(mpp("192.168.0.0/16"))
+	if s.Size() != 3 {
+		t.Errorf("Replace, got size: %d, want: 3", s.Size())
+	}
+
+	s.Replace(nil)
+	if s.Size() != 0 {
+		t.Errorf("Replace(nil), got size: %d, want: 0", s.Size())
+	}
+}
+
+func TestSyncTableConcurrent(t *testing.T) {
+	t.Parallel()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, workLoadN())
+	ips := make([]netip.Addr, workLoadN())
+	for i := range ips {
+		ips[i] = random.IP(prng)
+	}
+
+	var s SyncTable[int]
+	var wg sync.WaitGroup
+
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, pfx := range pfxs {
+				if i%4 == w {
+					s.Insert(pfx, i)
+					s.Update(pfx, func(val int, _ bool) int { return val + 1 })
+				}
+			}
+		}()
+	}
+
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, ip := range ips {
+				_, _ = s.Lookup(ip)
+				_ = s.Contains(ip)
+			}
+			_ = s.Clone()
+		}()
+	}
+
+	wg.Wait()
+
+	if s.Size() != len(pfxs) {
+		t.Fatalf("Size, got: %d, want: %d", s.Size(), len(pfxs))
+	}
+	for i, pfx := range pfxs {
+		if val, ok := s.Get(pfx); !ok || val != i+1 {
+			t.Fatalf("Get(%s), got: (%d, %v), want: (%d, true)", pfx, val, ok, i+1)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// LoadText reads a plaintext list with one CIDR per line from r and
+// returns a new table with every prefix set to val, e.g. for blocklists
+// and bogon feeds.
+//
+// Blank lines and comments, starting with '#' or ';', are skipped,
+// also trailing comments after the CIDR. A plain IP address is loaded
+// as host route. Prefixes are masked.
+func LoadText[V any](r io.Reader, val V) (*Table[V], error) {
+	return LoadTextFunc(r, func(netip.Prefix, string) (V, error) { return val, nil })
+}
+
+// LoadTextFunc is like [LoadText], but the value of every line is
+// returned by parseVal, called with the prefix and the remainder
+// of the line after the CIDR, without comments and surrounding spaces.
+// Errors are reported with the line number.
+func LoadTextFunc[V any](r io.Reader, parseVal func(pfx netip.Prefix, rest string) (V, error)) (*Table[V], error) {
+	tbl := new(Table[V])
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if i := strings.IndexAny(text, "#;"); i >= 0 {
+			text = text[:i]
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		field, rest := text, ""
+		if i := strings.IndexAny(text, " \t"); i >= 0 {
+			field, rest = text[:i], strings.TrimSpace(text[i:])
+		}
+
+		pfx, err := parseTextPrefix(field)
+		if err != nil {
+			return nil, fmt.Errorf("bart: line %d: %w", line, err)
+		}
+
+		val, err := parseVal(pfx, rest)
+		if err != nil {
+			return nil, fmt.Errorf("bart: line %d: parse value of %s: %w", line, pfx, err)
+		}
+
+		tbl.Insert(pfx, val)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("bart: read text: %w", err)
+	}
+
+	return tbl, nil
+}
+
+// parseTextPrefix parses a CIDR or a plain IP address as host route.
+func parseTextPrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if addr.Zone() != "" {
+			return netip.Prefix{}, fmt.Errorf("address with zone: %s", s)
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	return netip.ParsePrefix(s)
+}
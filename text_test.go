@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestLoadText(t *testing.T) {
+	t.Parallel()
+
+	input := `# bogons
+0.0.0.0/8
+10.0.0.0/8	; private
+
+  192.168.1.77/16   # not masked
+192.0.2.1
+2001:db8::/32 ; SBL12345
+`
+
+	got, err := LoadText(strings.NewReader(input), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := new(Table[bool])
+	for _, s := range []string{"0.0.0.0/8", "10.0.0.0/8", "192.168.0.0/16", "192.0.2.1/32", "2001:db8::/32"} {
+		want.Insert(mpp(s), true)
+	}
+
+	if !got.Equal(want) {
+		t.Fatalf("LoadText, got:\n%s\nwant:\n%s", got.DumpString(), want.DumpString())
+	}
+}
+
+func TestLoadTextFunc(t *testing.T) {
+	t.Parallel()
+
+	input := "10.0.0.0/8 spam  list\n2001:db8::1\n192.0.2.0/24\tdrop # comment\n"
+
+	got, err := LoadTextFunc(strings.NewReader(input), func(_ netip.Prefix, rest string) (string, error) {
+		return rest, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pfx  string
+		want string
+	}{
+		{"10.0.0.0/8", "spam  list"},
+		{"2001:db8::1/128", ""},
+		{"192.0.2.0/24", "drop"},
+	}
+
+	for _, tt := range tests {
+		if val, ok := got.Get(mpp(tt.pfx)); !ok || val != tt.want {
+			t.Errorf("Get(%s), got (%q, %v), want (%q, true)", tt.pfx, val, ok, tt.want)
+		}
+	}
+	if got.Size() != len(tests) {
+		t.Errorf("Size, got %d, want %d", got.Size(), len(tests))
+	}
+}
+
+func TestLoadTextErrors(t *testing.T) {
+	t.Parallel()
+
+	errParse := errors.New("parse")
+
+	tests := []struct {
+		name    string
+		input   string
+		parse   func(netip.Prefix, string) (int, error)
+		wantErr string
+	}{
+		{"invalid cidr", "10.0.0.0/8\n\n10.0.0.0/33\n", nil, "line 3"},
+		{"invalid addr", "# x\n10.0.0.256\n", nil, "line 2"},
+		{"zone", "fe80::1%eth0\n", nil, "line 1"},
+		{
+			"parse value", "10.0.0.0/8\n192.0.2.0/24 x\n",
+			func(_ netip.Prefix, rest string) (int, error) {
+				if rest != "" {
+					return 0, errParse
+				}
+				return 0, nil
+			},
+			"line 2: parse value of 192.0.2.0/24",
+		},
+	}
+
+	for _, tt := range tests {
+		parse := tt.parse
+		if parse == nil {
+			parse = func(netip.Prefix, string) (int, error) { return 0, nil }
+		}
+
+		_, err := LoadTextFunc(strings.NewReader(tt.input), parse)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("LoadTextFunc(%s), got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+	"time"
+)
+
+// EntryInfo holds the metadata timestamps of a routing table entry.
+type EntryInfo struct {
+	Created time.Time // first insert of the prefix
+	Updated time.Time // last insert or update of the value
+}
+
+// timedVal is the payload of TimedTable, the timestamps are stored
+// compactly as nanoseconds since the epoch of the table.
+type timedVal[V any] struct {
+	val     V
+	created int64
+	updated int64
+	seq     uint64 // of the last update, see ageItem
+}
+
+// ageItem is an entry in the age queue of TimedTable.
+type ageItem struct {
+	pfx     netip.Prefix
+	updated int64
+	seq     uint64 // unique per update, timestamps may collide
+}
+
+// TimedTable is a routing table with automatic created and updated
+// timestamps for every entry, e.g. for audit requirements like
+// "when did this route appear".
+//
+// Apart from the timestamps it behaves like a [Table], the timestamps
+// are queryable with [TimedTable.EntryInfo].
+//
+// The zero value is ready to use. The same concurrency rules apply as for [Table].
+type TimedTable[V any] struct {
+	tbl Table[timedVal[V]]
+
+	// age queue in order of the updated timestamps, outdated items
+	// of updated or deleted entries are skipped lazily.
+	ages []ageItem
+	seq  uint64
+
+	// reference for the compact timestamps, set by the first clock
+	// reading; with the monotonic clock reading of time.Now the age
+	// order is immune to wall clock steps.
+	epoch time.Time
+
+	// time source, replaceable in tests
+	now func() time.Time
+}
+
+// clock returns the current time in nanoseconds since the epoch.
+func (t *TimedTable[V]) clock() int64 {
+	var now time.Time
+	if t.now == nil {
+		now = time.Now()
+	} else {
+		now = t.now()
+	}
+
+	if t.epoch.IsZero() {
+		t.epoch = now
+	}
+	return int64(now.Sub(t.epoch))
+}
+
+// Insert adds or updates a prefix-value pair, the updated timestamp is set
+// to now. For new entries the created timestamp is also set to now.
+func (t *TimedTable[V]) Insert(pfx netip.Prefix, val V) {
+	if !pfx.IsValid() {
+		return
+	}
+	pfx = pfx.Masked()
+
+	now := t.clock()
+	t.seq++
+
+	t.tbl.Modify(pfx, func(old timedVal[V], ok bool) (_ timedVal[V], del bool) {
+		if !ok {
+			old.created = now
+		}
+		old.val = val
+		old.updated = now
+		old.seq = t.seq
+		return old, false
+	})
+
+	t.ages = append(t.ages, ageItem{pfx: pfx, updated: now, seq: t.seq})
+
+	// drop the outdated items once they dominate the queue
+	if len(t.ages) > 2*t.tbl.Size()+64 {
+		t.compactAges()
+	}
+}
+
+// compactAges removes the outdated items from the age queue.
+func (t *TimedTable[V]) compactAges() {
+	live := t.ages[:0]
+	for _, item := range t.ages {
+		if t.isCurrent(item) {
+			live = append(live, item)
+		}
+	}
+	clear(t.ages[len(live):])
+	t.ages = live
+}
+
+// isCurrent reports whether item is the latest update of a stored entry.
+func (t *TimedTable[V]) isCurrent(item ageItem) bool {
+	tv, ok := t.tbl.Get(item.pfx)
+	return ok && tv.seq == item.seq
+}
+
+// Delete removes the exact prefix pfx from the table.
+func (t *TimedTable[V]) Delete(pfx netip.Prefix) {
+	t.tbl.Delete(pfx)
+}
+
+// Get returns the value of the exact prefix pfx.
+func (t *TimedTable[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	tv, ok := t.tbl.Get(pfx)
+	return tv.val, ok
+}
+
+// EntryInfo returns the timestamps of the exact prefix pfx.
+func (t *TimedTable[V]) EntryInfo(pfx netip.Prefix) (info EntryInfo, ok bool) {
+	tv, ok := t.tbl.Get(pfx)
+	if !ok {
+		return info, false
+	}
+	return t.info(tv), true
+}
+
+// info converts the compact timestamps.
+func (t *TimedTable[V]) info(tv timedVal[V]) EntryInfo {
+	return EntryInfo{
+		Created: t.epoch.Add(time.Duration(tv.created)),
+		Updated: t.epoch.Add(time.Duration(tv.updated)),
+	}
+}
+
+// Contains reports whether any stored prefix covers ip, see [Table.Contains].
+func (t *TimedTable[V]) Contains(ip netip.Addr) bool {
+	return t.tbl.Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (t *TimedTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	tv, ok := t.tbl.Lookup(ip)
+	return tv.val, ok
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (t *TimedTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	tv, ok := t.tbl.LookupPrefix(pfx)
+	return tv.val, ok
+}
+
+// Size returns the prefix count.
+func (t *TimedTable[V]) Size() int {
+	return t.tbl.Size()
+}
+
+// All returns an iterator over all prefix–value pairs, see [Table.All].
+func (t *TimedTable[V]) All() iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		for pfx, tv := range t.tbl.All() {
+			if !yield(pfx, tv.val) {
+				return
+			}
+		}
+	}
+}
+
+// AllInfo returns an iterator over all prefixes with their timestamps,
+// e.g. for filtering or expiry.
+func (t *TimedTable[V]) AllInfo() iter.Seq2[netip.Prefix, EntryInfo] {
+	return func(yield func(netip.Prefix, EntryInfo) bool) {
+		for pfx, tv := range t.tbl.All() {
+			if !yield(pfx, t.info(tv)) {
+				return
+			}
+		}
+	}
+}
+
+// OlderThan returns an iterator over the entries not updated within
+// the last d, oldest first, e.g. to report stale learned routes.
+//
+// Only the stale entries are visited, not the whole table.
+// The ages are measured with the monotonic clock, see [time.Time].
+func (t *TimedTable[V]) OlderThan(d time.Duration) iter.Seq2[netip.Prefix, EntryInfo] {
+	return func(yield func(netip.Prefix, EntryInfo) bool) {
+		cutoff := t.clock() - int64(d)
+
+		for _, item := range t.ages {
+			if item.updated >= cutoff {
+				return
+			}
+
+			tv, ok := t.tbl.Get(item.pfx)
+			if !ok || tv.seq != item.seq {
+				continue
+			}
+
+			if !yield(item.pfx, t.info(tv)) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteOlderThan deletes the entries not updated within the last d,
+// e.g. to garbage-collect stale learned routes, and returns the number
+// of deleted entries. See also [TimedTable.OlderThan].
+func (t *TimedTable[V]) DeleteOlderThan(d time.Duration) (deleted int) {
+	cutoff := t.clock() - int64(d)
+
+	i := 0
+	for ; i < len(t.ages) && t.ages[i].updated < cutoff; i++ {
+		if t.isCurrent(t.ages[i]) {
+			t.tbl.Delete(t.ages[i].pfx)
+			deleted++
+		}
+	}
+
+	clear(t.ages[:i])
+	t.ages = t.ages[i:]
+
+	return deleted
+}
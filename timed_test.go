@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimedTableEntryInfo(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	tbl := new(TimedTable[int])
+	tbl.now = func() time.Time { return now }
+
+	pfx := mpp("10.0.0.0/8")
+	if _, ok := tbl.EntryInfo(pfx); ok {
+		t.Fatal("EntryInfo on empty table, expected false")
+	}
+
+	tbl.Insert(pfx, 1)
+
+	now = start.Add(time.Minute)
+	tbl.Insert(pfx, 2)
+	tbl.Insert(mpp("2001:db8::/32"), 3)
+
+	info, ok := tbl.EntryInfo(pfx)
+	if !ok {
+		t.Fatal("EntryInfo, expected true")
+	}
+	if !info.Created.Equal(start) {
+		t.Errorf("Created, got: %v, want: %v", info.Created, start)
+	}
+	if !info.Updated.Equal(now) {
+		t.Errorf("Updated, got: %v, want: %v", info.Updated, now)
+	}
+
+	if val, ok := tbl.Get(pfx); !ok || val != 2 {
+		t.Errorf("Get, got: (%d, %v), want: (2, true)", val, ok)
+	}
+	if val, ok := tbl.Lookup(mpa("10.1.2.3")); !ok || val != 2 {
+		t.Errorf("Lookup, got: (%d, %v), want: (2, true)", val, ok)
+	}
+
+	var count int
+	for _, info := range tbl.AllInfo() {
+		if info.Updated.Equal(now) {
+			count++
+		}
+	}
+	if count != tbl.Size() || count != 2 {
+		t.Errorf("AllInfo, got: %d, want: 2", count)
+	}
+
+	// a re-insert after delete is a new entry
+	now = start.Add(time.Hour)
+	tbl.Delete(pfx)
+	tbl.Insert(pfx, 4)
+
+	info, _ = tbl.EntryInfo(pfx)
+	if !info.Created.Equal(now) {
+		t.Errorf("Created after re-insert, got: %v, want: %v", info.Created, now)
+	}
+}
+
+func TestTimedTableOlderThan(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	tbl := new(TimedTable[int])
+	tbl.now = func() time.Time { return now }
+
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("11.0.0.0/8"), 2)
+
+	now = start.Add(time.Minute)
+	tbl.Insert(mpp("12.0.0.0/8"), 3)
+	tbl.Insert(mpp("10.0.0.0/8"), 4) // refreshed
+
+	now = start.Add(2 * time.Minute)
+	tbl.Insert(mpp("13.0.0.0/8"), 5)
+	tbl.Delete(mpp("12.0.0.0/8"))
+
+	var got []string
+	for pfx := range tbl.OlderThan(30 * time.Second) {
+		got = append(got, pfx.String())
+	}
+	if len(got) != 2 || got[0] != "11.0.0.0/8" || got[1] != "10.0.0.0/8" {
+		t.Errorf("OlderThan(30s), got: %v, want: [11.0.0.0/8 10.0.0.0/8]", got)
+	}
+
+	for pfx := range tbl.OlderThan(90 * time.Second) {
+		if pfx != mpp("11.0.0.0/8") {
+			t.Errorf("OlderThan(90s), got: %s, want: 11.0.0.0/8", pfx)
+		}
+	}
+
+	if n := tbl.DeleteOlderThan(90 * time.Second); n != 1 {
+		t.Errorf("DeleteOlderThan(90s), got: %d, want: 1", n)
+	}
+	if _, ok := tbl.Get(mpp("11.0.0.0/8")); ok {
+		t.Error("DeleteOlderThan(90s), 11.0.0.0/8 still present")
+	}
+
+	if n := tbl.DeleteOlderThan(30 * time.Second); n != 1 {
+		t.Errorf("DeleteOlderThan(30s), got: %d, want: 1", n)
+	}
+	if tbl.Size() != 1 {
+		t.Errorf("Size, got: %d, want: 1", tbl.Size())
+	}
+	if n := tbl.DeleteOlderThan(0); n != 0 {
+		t.Errorf("DeleteOlderThan(0), got: %d, want: 0", n)
+	}
+}
+
+func TestTimedTableAgesCompact(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tbl := new(TimedTable[int])
+	tbl.now = func() time.Time { return now }
+
+	for i := range 10_000 {
+		now = now.Add(time.Second)
+		tbl.Insert(mpp("10.0.0.0/8"), i)
+	}
+
+	if len(tbl.ages) > 2*tbl.Size()+64 {
+		t.Errorf("age queue not compacted, len: %d", len(tbl.ages))
+	}
+
+	n := 0
+	for range tbl.OlderThan(0) {
+		n++
+	}
+	if n != 0 {
+		t.Errorf("OlderThan(0), got: %d entries, want: 0", n)
+	}
+}
+
+func TestTimedTableSameTimestamp(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	tbl := new(TimedTable[int])
+	tbl.now = func() time.Time { return now }
+
+	// updates within the clock resolution
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.0.0.0/8"), 2)
+	tbl.Insert(mpp("10.0.0.0/8"), 3)
+	tbl.Insert(mpp("11.0.0.0/8"), 4)
+
+	now = start.Add(time.Minute)
+
+	var got []string
+	for pfx := range tbl.OlderThan(time.Second) {
+		got = append(got, pfx.String())
+	}
+	if len(got) != 2 || got[0] != "10.0.0.0/8" || got[1] != "11.0.0.0/8" {
+		t.Errorf("OlderThan, got: %v, want: [10.0.0.0/8 11.0.0.0/8]", got)
+	}
+
+	if n := tbl.DeleteOlderThan(time.Second); n != 2 {
+		t.Errorf("DeleteOlderThan, got: %d, want: 2", n)
+	}
+}
+
+func TestTimedTableMonotonic(t *testing.T) {
+	t.Parallel()
+
+	// the zero value uses time.Now with the monotonic clock reading
+	tbl := new(TimedTable[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+
+	info, _ := tbl.EntryInfo(mpp("10.0.0.0/8"))
+	if since := time.Since(info.Updated); since < 0 || since > time.Minute {
+		t.Errorf("Updated, got: %v ago", since)
+	}
+
+	n := 0
+	for range tbl.OlderThan(time.Hour) {
+		n++
+	}
+	if n != 0 {
+		t.Errorf("OlderThan(1h), got: %d entries, want: 0", n)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"net/netip"
+)
+
+// Transaction errors.
+var (
+	// ErrTxDone is returned by Commit and Rollback of a finished transaction.
+	ErrTxDone = errors.New("bart: transaction has already been committed or rolled back")
+
+	// ErrTxConflict is returned by Commit if the table was changed
+	// by another writer since Begin.
+	ErrTxConflict = errors.New("bart: table changed since begin of transaction")
+)
+
+// Tx is a batch of mutations on a copy-on-write version of a table.
+//
+// All mutations are applied with the persistent methods, the
+// original table is never modified until Commit. Reads within the Tx
+// see the own writes. Rollback discards the whole batch.
+//
+// Mutations of a finished Tx are ignored. A Tx must not be used
+// concurrently.
+type Tx[V any] struct {
+	base *Table[V] // version at begin of tx
+	tbl  *Table[V] // working version
+
+	// publishes the working version, nil for Preview and Batch
+	commit func(next *Table[V]) error
+	done   bool
+}
+
+// Begin starts a transaction on t, e.g. to apply a batch of BGP updates:
+//
+//	tx := rib.Begin()
+//	tx.Insert(pfx1, route1)
+//	tx.Delete(pfx2)
+//	if err := tx.Commit(); err != nil { ... }
+//
+// Commit replaces the content of t with the working version in a single
+// step, lookups on t never see a partially applied batch. The table t
+// must not be modified otherwise while the transaction is open, these
+// changes would be lost. For concurrent readers see [AtomicTable.Begin].
+func (t *Table[V]) Begin() *Tx[V] {
+	if t == nil {
+		t = new(Table[V])
+	}
+
+	return &Tx[V]{
+		base: t,
+		tbl:  t,
+		commit: func(next *Table[V]) error {
+			t.moveFrom(next)
+			return nil
+		},
+	}
+}
+
+// Commit publishes all mutations of the transaction.
+// A finished transaction returns [ErrTxDone].
+func (tx *Tx[V]) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+
+	if tx.commit == nil || tx.tbl == tx.base {
+		return nil
+	}
+	return tx.commit(tx.tbl)
+}
+
+// Rollback discards all mutations of the transaction.
+// A finished transaction returns [ErrTxDone].
+func (tx *Tx[V]) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	tx.tbl = tx.base
+
+	return nil
+}
+
+// Insert adds or updates a prefix-value pair, see [Table.InsertPersist].
+func (tx *Tx[V]) Insert(pfx netip.Prefix, val V) {
+	if tx.done {
+		return
+	}
+	tx.tbl = tx.tbl.InsertPersist(pfx, val)
+}
+
+// Delete removes the exact prefix pfx, see [Table.DeletePersist].
+func (tx *Tx[V]) Delete(pfx netip.Prefix) {
+	if tx.done {
+		return
+	}
+	tx.tbl = tx.tbl.DeletePersist(pfx)
+}
+
+// Modify inserts, updates or deletes the prefix pfx, see [Table.ModifyPersist].
+func (tx *Tx[V]) Modify(pfx netip.Prefix, cb func(val V, ok bool) (newVal V, del bool)) {
+	if tx.done {
+		return
+	}
+	tx.tbl = tx.tbl.ModifyPersist(pfx, cb)
+}
+
+// Get returns the value of the exact prefix pfx, including the own writes.
+func (tx *Tx[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	return tx.tbl.Get(pfx)
+}
+
+// Lookup performs a longest prefix match for ip, including the own writes.
+func (tx *Tx[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return tx.tbl.Lookup(ip)
+}
+
+// Size returns the prefix count, including the own writes.
+func (tx *Tx[V]) Size() int {
+	return tx.tbl.Size()
+}
+
+// Preview applies the batch of mutations in fn to a copy-on-write
+// version of the table and returns the resulting change set and
+// statistics, the table itself is not modified.
+//
+// Operators can review the effect of a config push before committing:
+//
+//	changes, stats := rib.Preview(func(tx *bart.Tx[Route]) {
+//		tx.Delete(netip.MustParsePrefix("10.0.0.0/8"))
+//		tx.Insert(netip.MustParsePrefix("10.0.0.0/9"), route)
+//	})
+func (t *Table[V]) Preview(fn func(tx *Tx[V])) (ChangeSet[V], Stats) {
+	if t == nil {
+		t = new(Table[V])
+	}
+
+	tx := &Tx[V]{base: t, tbl: t}
+	fn(tx)
+
+	return Diff(t, tx.tbl), tx.tbl.Stats()
+}
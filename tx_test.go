@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("10.1.0.0/16"), 2)
+	tbl.Insert(mpp("2001:db8::/32"), 3)
+
+	before := tbl.Clone()
+
+	changes, stats := tbl.Preview(func(tx *Tx[int]) {
+		tx.Delete(mpp("10.0.0.0/8"))
+		tx.Insert(mpp("10.0.0.0/9"), 4)
+		tx.Insert(mpp("10.1.0.0/16"), 5)
+		tx.Modify(mpp("2001:db8::/32"), func(val int, _ bool) (int, bool) { return val, false })
+		tx.Insert(mpp("192.168.0.0/16"), 6)
+		tx.Delete(mpp("192.168.0.0/16"))
+
+		if val, ok := tx.Get(mpp("10.1.0.0/16")); !ok || val != 5 {
+			t.Errorf("Tx.Get, own write not visible, got: (%d, %v)", val, ok)
+		}
+		if val, ok := tx.Lookup(mpa("10.2.0.1")); !ok || val != 4 {
+			t.Errorf("Tx.Lookup, own write not visible, got: (%d, %v)", val, ok)
+		}
+		if tx.Size() != 3 {
+			t.Errorf("Tx.Size, got: %d, want: 3", tx.Size())
+		}
+	})
+
+	if !tbl.Equal(before) {
+		t.Fatal("Preview modified the table")
+	}
+
+	want := ChangeSet[int]{
+		Added:   []Change[int]{{Prefix: mpp("10.0.0.0/9"), New: 4}},
+		Removed: []Change[int]{{Prefix: mpp("10.0.0.0/8"), Old: 1}},
+		Changed: []Change[int]{{Prefix: mpp("10.1.0.0/16"), Old: 2, New: 5}},
+	}
+
+	if changes.Len() != 3 ||
+		changes.Added[0] != want.Added[0] ||
+		changes.Removed[0] != want.Removed[0] ||
+		changes.Changed[0] != want.Changed[0] {
+		t.Fatalf("Preview, got changes: %+v, want: %+v", changes, want)
+	}
+
+	if stats.IPv4.Size != 2 || stats.IPv6.Size != 1 {
+		t.Errorf("Preview, got stats: %+v", stats)
+	}
+
+	var nilTbl *Table[int]
+	changes, _ = nilTbl.Preview(func(tx *Tx[int]) { tx.Insert(mpp("10.0.0.0/8"), 1) })
+	if len(changes.Added) != 1 {
+		t.Errorf("Preview on nil table, got changes: %+v", changes)
+	}
+}
+
+func TestTxCommitRollback(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table[int])
+	tbl.Insert(mpp("10.0.0.0/8"), 1)
+	tbl.Insert(mpp("2001:db8::/32"), 2)
+
+	before := tbl.Clone()
+
+	tx := tbl.Begin()
+	tx.Delete(mpp("10.0.0.0/8"))
+	tx.Insert(mpp("10.0.0.0/9"), 3)
+	tx.Insert(mpp("2001:db8::/48"), 4)
+
+	// not yet visible
+	if !tbl.Equal(before) {
+		t.Fatal("Tx, mutations visible before Commit")
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback, unexpected error: %v", err)
+	}
+	if !tbl.Equal(before) {
+		t.Fatal("Rollback, table modified")
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("Commit after Rollback, got: %v, want: %v", err, ErrTxDone)
+	}
+
+	tx = tbl.Begin()
+	tx.Delete(mpp("10.0.0.0/8"))
+	tx.Insert(mpp("10.0.0.0/9"), 3)
+	tx.Insert(mpp("2001:db8::/48"), 4)
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit, unexpected error: %v", err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("Rollback after Commit, got: %v, want: %v", err, ErrTxDone)
+	}
+
+	// mutations of a finished tx are ignored
+	tx.Insert(mpp("192.168.0.0/16"), 5)
+
+	want := new(Table[int])
+	want.Insert(mpp("10.0.0.0/9"), 3)
+	want.Insert(mpp("2001:db8::/32"), 2)
+	want.Insert(mpp("2001:db8::/48"), 4)
+
+	if !tbl.Equal(want) || tbl.Size4() != 1 || tbl.Size6() != 2 {
+		t.Fatalf("Commit, got:\n%s\nwant:\n%s", tbl.dumpString(), want.dumpString())
+	}
+
+	// the table is usable in-place after commit
+	tbl.Insert(mpp("172.16.0.0/12"), 6)
+	if tbl.Size() != 4 {
+		t.Fatalf("Insert after Commit, got size: %d, want: 4", tbl.Size())
+	}
+}
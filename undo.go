@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// undoOp is a recorded mutation, the state of the prefix
+// before and after the mutation.
+type undoOp[V any] struct {
+	pfx       netip.Prefix
+	oldVal    V
+	oldExists bool
+	newVal    V
+	newExists bool
+}
+
+// UndoTable is a routing table with a bounded undo log, e.g. for
+// interactive IPAM or policy editors with reversible editing.
+//
+// Every mutation is recorded with its inverse operation,
+// [UndoTable.Undo] and [UndoTable.Redo] step back and forth
+// through the log. A new mutation discards the redo log.
+//
+// The zero value is ready to use with an unbounded undo log.
+// The same concurrency rules apply as for [Table].
+type UndoTable[V any] struct {
+	tbl Table[V]
+
+	maxUndo int
+	undo    []undoOp[V]
+	redo    []undoOp[V]
+}
+
+// NewUndoTable returns an empty table retaining the last maxUndo
+// mutations in the undo log, a zero value means unbounded.
+func NewUndoTable[V any](maxUndo int) *UndoTable[V] {
+	return &UndoTable[V]{maxUndo: maxUndo}
+}
+
+// Insert adds or updates a prefix-value pair, see [Table.Insert].
+func (u *UndoTable[V]) Insert(pfx netip.Prefix, val V) {
+	u.Modify(pfx, func(V, bool) (V, bool) { return val, false })
+}
+
+// Delete removes the exact prefix pfx, see [Table.Delete].
+func (u *UndoTable[V]) Delete(pfx netip.Prefix) {
+	u.Modify(pfx, func(val V, _ bool) (V, bool) { return val, true })
+}
+
+// Modify applies an insert, update, or delete for pfx, see [Table.Modify].
+// No-ops, e.g. deleting a missing prefix, are not recorded.
+func (u *UndoTable[V]) Modify(pfx netip.Prefix, cb func(val V, found bool) (_ V, del bool)) {
+	if !pfx.IsValid() {
+		return
+	}
+	pfx = pfx.Masked()
+
+	op := undoOp[V]{pfx: pfx}
+	u.tbl.Modify(pfx, func(val V, found bool) (V, bool) {
+		op.oldVal, op.oldExists = val, found
+
+		newVal, del := cb(val, found)
+		if !del {
+			op.newVal, op.newExists = newVal, true
+		}
+		return newVal, del
+	})
+
+	if !op.oldExists && !op.newExists {
+		return
+	}
+
+	u.redo = u.redo[:0]
+	u.undo = append(u.undo, op)
+
+	if u.maxUndo > 0 && len(u.undo) > u.maxUndo {
+		drop := len(u.undo) - u.maxUndo
+		clear(u.undo[:drop])
+		u.undo = u.undo[drop:]
+	}
+}
+
+// apply sets the state of pfx.
+func (u *UndoTable[V]) apply(pfx netip.Prefix, val V, exists bool) {
+	if exists {
+		u.tbl.Insert(pfx, val)
+	} else {
+		u.tbl.Delete(pfx)
+	}
+}
+
+// Undo reverts the last n mutations and returns the number of reverted
+// mutations, less than n if the undo log is exhausted.
+func (u *UndoTable[V]) Undo(n int) (undone int) {
+	for ; undone < n && len(u.undo) > 0; undone++ {
+		op := u.undo[len(u.undo)-1]
+		u.undo = u.undo[:len(u.undo)-1]
+
+		u.apply(op.pfx, op.oldVal, op.oldExists)
+		u.redo = append(u.redo, op)
+	}
+	return undone
+}
+
+// Redo re-applies the last n reverted mutations and returns the number of
+// re-applied mutations, less than n if the redo log is exhausted.
+func (u *UndoTable[V]) Redo(n int) (redone int) {
+	for ; redone < n && len(u.redo) > 0; redone++ {
+		op := u.redo[len(u.redo)-1]
+		u.redo = u.redo[:len(u.redo)-1]
+
+		u.apply(op.pfx, op.newVal, op.newExists)
+		u.undo = append(u.undo, op)
+	}
+	return redone
+}
+
+// UndoLen returns the number of mutations that can be undone.
+func (u *UndoTable[V]) UndoLen() int {
+	return len(u.undo)
+}
+
+// RedoLen returns the number of mutations that can be redone.
+func (u *UndoTable[V]) RedoLen() int {
+	return len(u.redo)
+}
+
+// Get returns the value of the exact prefix pfx, see [Table.Get].
+func (u *UndoTable[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	return u.tbl.Get(pfx)
+}
+
+// Contains reports whether any stored prefix covers ip, see [Table.Contains].
+func (u *UndoTable[V]) Contains(ip netip.Addr) bool {
+	return u.tbl.Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (u *UndoTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return u.tbl.Lookup(ip)
+}
+
+// Size returns the prefix count.
+func (u *UndoTable[V]) Size() int {
+	return u.tbl.Size()
+}
+
+// All returns an iterator over all prefix–value pairs, see [Table.All].
+func (u *UndoTable[V]) All() iter.Seq2[netip.Prefix, V] {
+	return u.tbl.All()
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"testing"
+)
+
+func TestUndoTable(t *testing.T) {
+	t.Parallel()
+
+	u := new(UndoTable[int])
+
+	u.Insert(mpp("10.0.0.0/8"), 1)
+	u.Insert(mpp("10.0.0.0/8"), 2)
+	u.Insert(mpp("11.0.0.0/8"), 3)
+	u.Delete(mpp("10.0.0.0/8"))
+	u.Delete(mpp("12.0.0.0/8")) // no-op, not recorded
+
+	if u.UndoLen() != 4 {
+		t.Fatalf("UndoLen, got: %d, want: 4", u.UndoLen())
+	}
+
+	// undo the delete
+	if n := u.Undo(1); n != 1 {
+		t.Fatalf("Undo(1), got: %d", n)
+	}
+	if val, ok := u.Get(mpp("10.0.0.0/8")); !ok || val != 2 {
+		t.Errorf("after Undo(1), got: (%d, %v), want: (2, true)", val, ok)
+	}
+
+	// undo the update and the insert of 11/8
+	u.Undo(2)
+	if val, _ := u.Get(mpp("10.0.0.0/8")); val != 1 {
+		t.Errorf("after Undo(3), got: %d, want: 1", val)
+	}
+	if _, ok := u.Get(mpp("11.0.0.0/8")); ok {
+		t.Error("after Undo(3), 11.0.0.0/8 still present")
+	}
+
+	if n := u.Undo(10); n != 1 || u.Size() != 0 {
+		t.Errorf("Undo exhausted, got: %d, Size: %d", n, u.Size())
+	}
+
+	if n := u.Redo(10); n != 4 || u.RedoLen() != 0 {
+		t.Fatalf("Redo(10), got: %d", n)
+	}
+	if val, ok := u.Lookup(mpa("11.1.1.1")); !ok || val != 3 || u.Size() != 1 {
+		t.Errorf("after Redo, got: (%d, %v), Size: %d", val, ok, u.Size())
+	}
+
+	// a new mutation discards the redo log
+	u.Undo(2)
+	u.Insert(mpp("2001:db8::/32"), 4)
+	if u.RedoLen() != 0 || u.Redo(1) != 0 {
+		t.Errorf("redo log not discarded, RedoLen: %d", u.RedoLen())
+	}
+}
+
+func TestUndoTableBounded(t *testing.T) {
+	t.Parallel()
+
+	u := NewUndoTable[int](2)
+	for i := range 5 {
+		u.Insert(mpp("10.0.0.0/8"), i)
+	}
+
+	if u.UndoLen() != 2 {
+		t.Fatalf("UndoLen, got: %d, want: 2", u.UndoLen())
+	}
+	if n := u.Undo(5); n != 2 {
+		t.Fatalf("Undo(5), got: %d, want: 2", n)
+	}
+	if val, _ := u.Get(mpp("10.0.0.0/8")); val != 2 {
+		t.Errorf("after bounded Undo, got: %d, want: 2", val)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"net/netip"
+)
+
+// Unified is a routing table in single-tree mode: IPv4 prefixes are stored
+// as IPv4-mapped IPv6 prefixes below ::ffff:0:0/96 in the IPv6 root,
+// e.g. for a unified iteration order of both address families and
+// single-tree set operations.
+//
+// IPv4 is mapped on input and unmapped on output, callers see plain
+// IPv4 prefixes and addresses. Consequently, IPv6 prefixes covering
+// ::ffff:0:0/96, e.g. ::/0, also match IPv4 addresses, and IPv4-mapped
+// IPv6 prefixes of length >= 96 are treated as IPv4.
+//
+// The split-root mode of [Table] is faster, use Unified only if the single
+// tree is needed. The zero value is ready to use. The same concurrency
+// rules apply as for [Table].
+type Unified[V any] struct {
+	tbl Table[V]
+}
+
+// mapPrefix maps an IPv4 prefix into the IPv6 address space.
+func mapPrefix(pfx netip.Prefix) netip.Prefix {
+	if !pfx.IsValid() || !pfx.Addr().Is4() {
+		return pfx
+	}
+	return netip.PrefixFrom(netip.AddrFrom16(pfx.Addr().As16()), pfx.Bits()+96)
+}
+
+// unmapPrefix is the inverse of mapPrefix.
+func unmapPrefix(pfx netip.Prefix) netip.Prefix {
+	if !pfx.Addr().Is4In6() || pfx.Bits() < 96 {
+		return pfx
+	}
+	return netip.PrefixFrom(pfx.Addr().Unmap(), pfx.Bits()-96)
+}
+
+// mapAddr maps an IPv4 address into the IPv6 address space.
+func mapAddr(ip netip.Addr) netip.Addr {
+	if !ip.Is4() {
+		return ip
+	}
+	return netip.AddrFrom16(ip.As16())
+}
+
+// Insert adds or updates a prefix-value pair, see [Table.Insert].
+func (u *Unified[V]) Insert(pfx netip.Prefix, val V) {
+	u.tbl.Insert(mapPrefix(pfx), val)
+}
+
+// Delete removes the exact prefix pfx, see [Table.Delete].
+func (u *Unified[V]) Delete(pfx netip.Prefix) {
+	u.tbl.Delete(mapPrefix(pfx))
+}
+
+// Get returns the value of the exact prefix pfx, see [Table.Get].
+func (u *Unified[V]) Get(pfx netip.Prefix) (val V, ok bool) {
+	return u.tbl.Get(mapPrefix(pfx))
+}
+
+// Contains reports whether any stored prefix covers ip, see [Table.Contains].
+func (u *Unified[V]) Contains(ip netip.Addr) bool {
+	return u.tbl.Contains(mapAddr(ip))
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (u *Unified[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return u.tbl.Lookup(mapAddr(ip))
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (u *Unified[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	return u.tbl.LookupPrefix(mapPrefix(pfx))
+}
+
+// LookupPrefixLPM is like [Unified.LookupPrefix] but also returns
+// the matching prefix, see [Table.LookupPrefixLPM].
+func (u *Unified[V]) LookupPrefixLPM(pfx netip.Prefix) (lpm netip.Prefix, val V, ok bool) {
+	lpm, val, ok = u.tbl.LookupPrefixLPM(mapPrefix(pfx))
+	return unmapPrefix(lpm), val, ok
+}
+
+// Union merges o into the receiver, see [Table.Union].
+func (u *Unified[V]) Union(o *Unified[V]) {
+	if o == nil {
+		return
+	}
+	u.tbl.Union(&o.tbl)
+}
+
+// Overlaps reports whether any prefix of u overlaps with any prefix of o,
+// see [Table.Overlaps].
+func (u *Unified[V]) Overlaps(o *Unified[V]) bool {
+	if o == nil {
+		return false
+	}
+	return u.tbl.Overlaps(&o.tbl)
+}
+
+// Intersect returns a new table with the prefixes present in both u and o,
+// see [Table.Intersect]. The prefix passed to merge is unmapped.
+func (u *Unified[V]) Intersect(o *Unified[V], merge func(pfx netip.Prefix, a, b V) V) *Unified[V] {
+	var mergeFn func(netip.Prefix, V, V) V
+	if merge != nil {
+		mergeFn = func(pfx netip.Prefix, a, b V) V {
+			return merge(unmapPrefix(pfx), a, b)
+		}
+	}
+
+	res := new(Unified[V])
+	res.tbl.moveFrom(u.table().Intersect(o.table(), mergeFn))
+	return res
+}
+
+// Difference returns a new table with the prefixes of u not present in o,
+// see [Table.Difference].
+func (u *Unified[V]) Difference(o *Unified[V]) *Unified[V] {
+	res := new(Unified[V])
+	res.tbl.moveFrom(u.table().Difference(o.table()))
+	return res
+}
+
+// SymmetricDifference returns the prefixes present in exactly one of u and o,
+// split by origin, see [Table.SymmetricDifference].
+func (u *Unified[V]) SymmetricDifference(o *Unified[V]) (onlyU, onlyO *Unified[V]) {
+	return u.Difference(o), o.Difference(u)
+}
+
+// UnionWith merges o into the receiver, duplicates are resolved by merge,
+// see [Table.UnionWith].
+func (u *Unified[V]) UnionWith(o *Unified[V], merge func(existing, incoming V) V) {
+	u.tbl.UnionWith(o.table(), merge)
+}
+
+// Filter returns a new table containing only the prefixes for which
+// pred returns true, see [Table.Filter]. The prefix passed to pred is unmapped.
+func (u *Unified[V]) Filter(pred func(pfx netip.Prefix, val V) bool) *Unified[V] {
+	res := new(Unified[V])
+	res.tbl.moveFrom(u.tbl.Filter(func(pfx netip.Prefix, val V) bool {
+		return pred(unmapPrefix(pfx), val)
+	}))
+	return res
+}
+
+// table returns the single tree, nil for a nil receiver.
+func (u *Unified[V]) table() *Table[V] {
+	if u == nil {
+		return nil
+	}
+	return &u.tbl
+}
+
+// Size returns the prefix count.
+func (u *Unified[V]) Size() int {
+	return u.tbl.Size()
+}
+
+// All returns an iterator over all prefix–value pairs, see [Table.All].
+func (u *Unified[V]) All() iter.Seq2[netip.Prefix, V] {
+	return unmapSeq2(u.tbl.All())
+}
+
+// AllSorted returns an iterator over all prefix–value pairs in the
+// sort order of the single tree, IPv4 is sorted as IPv4-mapped IPv6.
+func (u *Unified[V]) AllSorted() iter.Seq2[netip.Prefix, V] {
+	return unmapSeq2(u.tbl.AllSorted())
+}
+
+// Subnets returns an iterator over all prefix–value pairs covered by pfx,
+// see [Table.Subnets].
+func (u *Unified[V]) Subnets(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return unmapSeq2(u.tbl.Subnets(mapPrefix(pfx)))
+}
+
+// Supernets returns an iterator over all prefix–value pairs covering pfx,
+// see [Table.Supernets]. IPv6 prefixes covering ::ffff:0:0/96 are also
+// supernets of IPv4 prefixes.
+func (u *Unified[V]) Supernets(pfx netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return unmapSeq2(u.tbl.Supernets(mapPrefix(pfx)))
+}
+
+// unmapSeq2 unmaps the prefixes of seq.
+func unmapSeq2[V any](seq iter.Seq2[netip.Prefix, V]) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		for pfx, val := range seq {
+			if !yield(unmapPrefix(pfx), val) {
+				return
+			}
+		}
+	}
+}
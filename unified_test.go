@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"iter"
+	"maps"
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/admpub/bart/internal/tests/random"
+)
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	u := new(Unified[string])
+	u.Insert(mpp("10.0.0.0/8"), "v4")
+	u.Insert(mpp("0.0.0.0/0"), "v4 default")
+	u.Insert(mpp("2001:db8::/32"), "v6")
+
+	if u.Size() != 3 {
+		t.Fatalf("Size, got: %d, want: 3", u.Size())
+	}
+	if u.tbl.Size4() != 0 {
+		t.Fatalf("IPv4 root is used, Size4: %d", u.tbl.Size4())
+	}
+
+	if val, ok := u.Get(mpp("10.0.0.0/8")); !ok || val != "v4" {
+		t.Errorf("Get, got: (%q, %v)", val, ok)
+	}
+	if val, ok := u.Lookup(mpa("10.1.2.3")); !ok || val != "v4" {
+		t.Errorf("Lookup, got: (%q, %v)", val, ok)
+	}
+	if val, ok := u.Lookup(mpa("11.1.2.3")); !ok || val != "v4 default" {
+		t.Errorf("Lookup, got: (%q, %v)", val, ok)
+	}
+	if u.Contains(mpa("2001:db9::1")) {
+		t.Error("Contains(2001:db9::1), expected false")
+	}
+	if lpm, _, ok := u.LookupPrefixLPM(mpp("10.1.0.0/16")); !ok || lpm != mpp("10.0.0.0/8") {
+		t.Errorf("LookupPrefixLPM, got: (%s, %v)", lpm, ok)
+	}
+
+	// unified sort order: IPv4 sorts within IPv6 at ::ffff:0:0/96
+	var got []string
+	for pfx := range u.AllSorted() {
+		got = append(got, pfx.String())
+	}
+	want := []string{"0.0.0.0/0", "10.0.0.0/8", "2001:db8::/32"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("AllSorted, got: %v, want: %v", got, want)
+		}
+	}
+
+	// an IPv6 default route also matches IPv4
+	u.Delete(mpp("0.0.0.0/0"))
+	u.Insert(mpp("::/0"), "v6 default")
+	if val, ok := u.Lookup(mpa("11.1.2.3")); !ok || val != "v6 default" {
+		t.Errorf("Lookup via ::/0, got: (%q, %v)", val, ok)
+	}
+
+	o := new(Unified[string])
+	o.Insert(mpp("10.0.0.0/24"), "o")
+	if !u.Overlaps(o) {
+		t.Error("Overlaps, expected true")
+	}
+	u.Union(o)
+	if val, _ := u.Lookup(mpa("10.0.0.1")); val != "o" {
+		t.Errorf("Lookup after Union, got: %q", val)
+	}
+}
+
+func TestUnifiedCompare(t *testing.T) {
+	t.Parallel()
+
+	n := workLoadN()
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	pfxs := random.RealWorldPrefixes(prng, n)
+
+	tbl := new(Table[int])
+	u := new(Unified[int])
+	for i, pfx := range pfxs {
+		tbl.Insert(pfx, i)
+		u.Insert(pfx, i)
+	}
+
+	for range n {
+		ip := random.IP(prng)
+
+		want, wantOK := tbl.Lookup(ip)
+		got, gotOK := u.Lookup(ip)
+		if got != want || gotOK != wantOK {
+			t.Fatalf("Lookup(%s), got: (%d, %v), want: (%d, %v)", ip, got, gotOK, want, wantOK)
+		}
+	}
+
+	for pfx, val := range u.All() {
+		if want, ok := tbl.Get(pfx); !ok || want != val {
+			t.Fatalf("All, %s, got: %d, want: %d", pfx, val, want)
+		}
+	}
+}
+
+func TestUnifiedSetOps(t *testing.T) {
+	t.Parallel()
+
+	a, b := new(Unified[int]), new(Unified[int])
+	a.Insert(mpp("10.0.0.0/8"), 1)
+	a.Insert(mpp("10.1.0.0/16"), 2)
+	a.Insert(mpp("2001:db8::/32"), 3)
+	b.Insert(mpp("10.0.0.0/8"), 10)
+	b.Insert(mpp("192.168.0.0/16"), 20)
+	b.Insert(mpp("2001:db8::/32"), 30)
+
+	collect := func(seq iter.Seq2[netip.Prefix, int]) map[netip.Prefix]int {
+		m := make(map[netip.Prefix]int)
+		for pfx, val := range seq {
+			m[pfx] = val
+		}
+		return m
+	}
+
+	var merged []netip.Prefix
+	inter := a.Intersect(b, func(pfx netip.Prefix, x, y int) int {
+		merged = append(merged, pfx)
+		return x + y
+	})
+	want := map[netip.Prefix]int{mpp("10.0.0.0/8"): 11, mpp("2001:db8::/32"): 33}
+	if got := collect(inter.All()); !maps.Equal(got, want) {
+		t.Errorf("Intersect, got: %v, want: %v", got, want)
+	}
+	if !slices.Contains(merged, mpp("10.0.0.0/8")) {
+		t.Errorf("Intersect, merge got mapped prefixes: %v", merged)
+	}
+
+	onlyA, onlyB := a.SymmetricDifference(b)
+	if got := collect(onlyA.All()); !maps.Equal(got, map[netip.Prefix]int{mpp("10.1.0.0/16"): 2}) {
+		t.Errorf("SymmetricDifference, only a: %v", got)
+	}
+	if got := collect(onlyB.All()); !maps.Equal(got, map[netip.Prefix]int{mpp("192.168.0.0/16"): 20}) {
+		t.Errorf("SymmetricDifference, only b: %v", got)
+	}
+
+	v4 := a.Filter(func(pfx netip.Prefix, _ int) bool { return pfx.Addr().Is4() })
+	if v4.Size() != 2 {
+		t.Errorf("Filter by unmapped IPv4 prefixes, Size: %d, want: 2", v4.Size())
+	}
+
+	if got := collect(a.Subnets(mpp("10.0.0.0/8"))); len(got) != 2 || got[mpp("10.1.0.0/16")] != 2 {
+		t.Errorf("Subnets, got: %v", got)
+	}
+	if got := collect(a.Supernets(mpp("10.1.2.0/24"))); len(got) != 2 || got[mpp("10.0.0.0/8")] != 1 {
+		t.Errorf("Supernets, got: %v", got)
+	}
+
+	a.UnionWith(b, func(existing, incoming int) int { return existing + incoming })
+	if got, _ := a.Get(mpp("10.0.0.0/8")); got != 11 || a.Size() != 4 {
+		t.Errorf("UnionWith, got: %d, Size: %d", got, a.Size())
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// ErrVersionUnknown is returned by [VersionedTable.RollbackTo] for an
+// unknown or already pruned version.
+var ErrVersionUnknown = errors.New("bart: version unknown or pruned")
+
+// VersionedTable is a routing table with a version number, bumped by
+// every mutation, and a bounded [History] of past versions, e.g. for
+// controllers that must revert a bad config push.
+//
+// All mutations are applied with the persistent (copy-on-write)
+// methods, the retained versions share all untouched nodes.
+// [VersionedTable.RollbackTo] restores a past version in O(1), without
+// rebuilding the table.
+//
+// VersionedTable is safe for concurrent use, readers always see a
+// consistent version. Writers are serialized.
+type VersionedTable[V any] struct {
+	mu   sync.Mutex // serializes the writers
+	hist *History[V]
+}
+
+// NewVersionedTable returns an empty table with version 1, the limits
+// of the history are those of [NewHistory].
+func NewVersionedTable[V any](maxVersions int, maxAge time.Duration) *VersionedTable[V] {
+	v := &VersionedTable[V]{hist: NewHistory[V](maxVersions, maxAge)}
+	v.hist.Record(new(Table[V]))
+	return v
+}
+
+// Current returns the current version of the table, e.g. for iterations
+// on a consistent snapshot.
+//
+// The returned table is shared and must not be modified in-place,
+// use the persistent methods or [Table.Clone] first.
+func (v *VersionedTable[V]) Current() *Table[V] {
+	tbl, _ := v.hist.Latest()
+	return tbl
+}
+
+// Version returns the current version number.
+func (v *VersionedTable[V]) Version() uint64 {
+	_, version := v.hist.Latest()
+	return version
+}
+
+// At returns the table with the given version number,
+// see [History.At].
+func (v *VersionedTable[V]) At(version uint64) (*Table[V], bool) {
+	return v.hist.At(version)
+}
+
+// update applies fn to the current version and records the result
+// as new version.
+func (v *VersionedTable[V]) update(fn func(cur *Table[V]) *Table[V]) (version uint64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.hist.Record(fn(v.Current()))
+}
+
+// Insert adds or updates a prefix-value pair and returns the new
+// version number, see [Table.InsertPersist].
+func (v *VersionedTable[V]) Insert(pfx netip.Prefix, val V) (version uint64) {
+	return v.update(func(cur *Table[V]) *Table[V] {
+		return cur.InsertPersist(pfx, val)
+	})
+}
+
+// Delete removes the exact prefix pfx and returns the new version
+// number, see [Table.DeletePersist].
+func (v *VersionedTable[V]) Delete(pfx netip.Prefix) (version uint64) {
+	return v.update(func(cur *Table[V]) *Table[V] {
+		return cur.DeletePersist(pfx)
+	})
+}
+
+// Modify inserts, updates or deletes the prefix pfx and returns the new
+// version number, see [Table.ModifyPersist]. The callback is called with
+// the writer lock held and must not call the mutating methods of v.
+func (v *VersionedTable[V]) Modify(pfx netip.Prefix, cb func(val V, ok bool) (newVal V, del bool)) (version uint64) {
+	return v.update(func(cur *Table[V]) *Table[V] {
+		return cur.ModifyPersist(pfx, cb)
+	})
+}
+
+// Batch applies all mutations in fn as one new version and returns
+// its number. The callback is called with the writer lock held and must
+// not call the mutating methods of v.
+func (v *VersionedTable[V]) Batch(fn func(tx *Tx[V])) (version uint64) {
+	return v.update(func(cur *Table[V]) *Table[V] {
+		tx := &Tx[V]{base: cur, tbl: cur}
+		fn(tx)
+		return tx.tbl
+	})
+}
+
+// RollbackTo restores the table of the given version. The rollback is
+// itself a mutation, the restored table is recorded with a new version
+// number, the version numbers are never reused and the rollback can be
+// rolled back again.
+//
+// It returns the new version number, or [ErrVersionUnknown] if the
+// version is unknown or already pruned.
+func (v *VersionedTable[V]) RollbackTo(version uint64) (uint64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	tbl, ok := v.hist.At(version)
+	if !ok {
+		return 0, ErrVersionUnknown
+	}
+	return v.hist.Record(tbl), nil
+}
+
+// Get returns the value of the exact prefix pfx, see [Table.Get].
+func (v *VersionedTable[V]) Get(pfx netip.Prefix) (val V, exists bool) {
+	return v.Current().Get(pfx)
+}
+
+// Contains reports whether any stored prefix matches ip, see [Table.Contains].
+func (v *VersionedTable[V]) Contains(ip netip.Addr) bool {
+	return v.Current().Contains(ip)
+}
+
+// Lookup performs a longest prefix match for ip, see [Table.Lookup].
+func (v *VersionedTable[V]) Lookup(ip netip.Addr) (val V, ok bool) {
+	return v.Current().Lookup(ip)
+}
+
+// LookupPrefix performs a longest prefix match for pfx, see [Table.LookupPrefix].
+func (v *VersionedTable[V]) LookupPrefix(pfx netip.Prefix) (val V, ok bool) {
+	return v.Current().LookupPrefix(pfx)
+}
+
+// Size returns the prefix count of the current version.
+func (v *VersionedTable[V]) Size() int {
+	return v.Current().Size()
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package bart
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersionedTable(t *testing.T) {
+	t.Parallel()
+
+	v := NewVersionedTable[int](10, 0)
+	if v.Version() != 1 || v.Size() != 0 {
+		t.Fatalf("new table, got version: %d, size: %d, want: 1, 0", v.Version(), v.Size())
+	}
+
+	v.Insert(mpp("10.0.0.0/8"), 1)
+	good := v.Insert(mpp("2001:db8::/32"), 2)
+
+	// a bad push
+	bad := v.Batch(func(tx *Tx[int]) {
+		tx.Delete(mpp("10.0.0.0/8"))
+		tx.Insert(mpp("0.0.0.0/0"), 3)
+	})
+	if bad != good+1 || v.Version() != bad {
+		t.Fatalf("Batch, got version: %d, want: %d", bad, good+1)
+	}
+	if val, _ := v.Lookup(mpa("10.1.2.3")); val != 3 {
+		t.Fatalf("Lookup after Batch, got: %d, want: 3", val)
+	}
+
+	restored, err := v.RollbackTo(good)
+	if err != nil {
+		t.Fatalf("RollbackTo, unexpected error: %v", err)
+	}
+	if restored != bad+1 || v.Version() != restored {
+		t.Errorf("RollbackTo, got version: %d, want: %d", restored, bad+1)
+	}
+	if val, ok := v.Lookup(mpa("10.1.2.3")); !ok || val != 1 || v.Size() != 2 {
+		t.Errorf("Lookup after RollbackTo, got: (%d, %v), size: %d", val, ok, v.Size())
+	}
+
+	// the rollback can be rolled back
+	if _, err := v.RollbackTo(bad); err != nil {
+		t.Fatalf("RollbackTo, unexpected error: %v", err)
+	}
+	if !v.Contains(mpa("8.8.8.8")) {
+		t.Error("RollbackTo the bad version, expected the default route")
+	}
+
+	if _, err := v.RollbackTo(99); !errors.Is(err, ErrVersionUnknown) {
+		t.Errorf("RollbackTo unknown version, got: %v, want: %v", err, ErrVersionUnknown)
+	}
+
+	// the versions are immutable
+	if tbl, ok := v.At(good); !ok || tbl.Size() != 2 {
+		t.Error("At, expected the good version")
+	}
+}
+
+func TestVersionedTablePruned(t *testing.T) {
+	t.Parallel()
+
+	v := NewVersionedTable[int](2, 0)
+	first := v.Insert(mpp("10.0.0.0/8"), 1)
+	v.Delete(mpp("10.0.0.0/8"))
+	v.Modify(mpp("10.0.0.0/8"), func(val int, _ bool) (int, bool) { return val + 5, false })
+
+	if _, err := v.RollbackTo(first); !errors.Is(err, ErrVersionUnknown) {
+		t.Errorf("RollbackTo pruned version, got: %v, want: %v", err, ErrVersionUnknown)
+	}
+	if val, _ := v.Get(mpp("10.0.0.0/8")); val != 5 {
+		t.Errorf("Get, got: %d, want: 5", val)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package vrp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// jsonASN accepts the ASN as number (rpki-client) or as "AS13335" string (routinator).
+type jsonASN uint32
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *jsonASN) UnmarshalJSON(data []byte) error {
+	s := string(bytes.Trim(data, `"`))
+
+	asn, err := parseASN(s)
+	if err != nil {
+		return err
+	}
+
+	*a = jsonASN(asn)
+	return nil
+}
+
+// jsonROA is an entry of the "roas" list.
+type jsonROA struct {
+	ASN       jsonASN `json:"asn"`
+	Prefix    string  `json:"prefix"`
+	MaxLength int     `json:"maxLength"`
+}
+
+// LoadJSON adds all VRPs from the JSON export of rpki-client or routinator,
+// a top-level object with a "roas" list:
+//
+//	{"roas": [{"asn": "AS13335", "prefix": "1.0.0.0/24", "maxLength": 24, "ta": "apnic"}]}
+//
+// Additional members like the metadata or the trust anchor are ignored.
+func (t *Table) LoadJSON(r io.Reader) error {
+	var doc struct {
+		ROAs *[]jsonROA `json:"roas"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return fmt.Errorf("vrp: %w", err)
+	}
+
+	if doc.ROAs == nil {
+		return errors.New("vrp: missing roas list")
+	}
+
+	for _, roa := range *doc.ROAs {
+		pfx, err := netip.ParsePrefix(roa.Prefix)
+		if err != nil {
+			return fmt.Errorf("vrp: %w", err)
+		}
+
+		if err := t.Add(VRP{Prefix: pfx, MaxLength: roa.MaxLength, ASN: uint32(roa.ASN)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadCSV adds all VRPs from the CSV export of routinator or rpki-client:
+//
+//	ASN,IP Prefix,Max Length,Trust Anchor
+//	AS13335,1.0.0.0/24,24,apnic
+//
+// The header line is optional, additional columns are ignored.
+func (t *Table) LoadCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("vrp: %w", err)
+		}
+
+		if len(rec) < 3 {
+			return fmt.Errorf("vrp: line %d: expected at least 3 fields, got %d", line, len(rec))
+		}
+
+		if line == 1 && strings.EqualFold(strings.TrimSpace(rec[0]), "ASN") {
+			continue // header
+		}
+
+		asn, err := parseASN(rec[0])
+		if err != nil {
+			return fmt.Errorf("%w (line %d)", err, line)
+		}
+
+		pfx, err := netip.ParsePrefix(strings.TrimSpace(rec[1]))
+		if err != nil {
+			return fmt.Errorf("vrp: line %d: %w", line, err)
+		}
+
+		maxLen, err := strconv.Atoi(strings.TrimSpace(rec[2]))
+		if err != nil {
+			return fmt.Errorf("vrp: line %d: invalid max length %q", line, rec[2])
+		}
+
+		if err := t.Add(VRP{Prefix: pfx, MaxLength: maxLen, ASN: asn}); err != nil {
+			return fmt.Errorf("%w (line %d)", err, line)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+// Package vrp implements RPKI route origin validation (RFC 6811)
+// on top of bart routing tables.
+//
+// Validated ROA Payloads (VRPs) are loaded from the JSON or CSV
+// exports of relying party software like rpki-client or routinator,
+// routes are then validated with [Table.Validate]:
+//
+//	var tbl vrp.Table
+//	if err := tbl.LoadJSON(file); err != nil { ... }
+//
+//	state := tbl.Validate(netip.MustParsePrefix("1.1.1.0/24"), 13335)
+package vrp
+
+import (
+	"fmt"
+	"iter"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/admpub/bart"
+)
+
+// State is the route origin validation state of a route, RFC 6811.
+type State int
+
+const (
+	// NotFound means no VRP covers the route prefix.
+	NotFound State = iota
+
+	// Valid means at least one covering VRP matches the route.
+	Valid
+
+	// Invalid means covering VRPs exist, but none matches the route.
+	Invalid
+)
+
+// String implements fmt.Stringer.
+func (s State) String() string {
+	switch s {
+	case NotFound:
+		return "NotFound"
+	case Valid:
+		return "Valid"
+	case Invalid:
+		return "Invalid"
+	default:
+		return "State(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// VRP is a Validated ROA Payload.
+type VRP struct {
+	Prefix    netip.Prefix
+	MaxLength int
+	ASN       uint32
+}
+
+// payload is the per prefix value in the table, the prefix is the key.
+type payload struct {
+	asn       uint32
+	maxLength uint8
+}
+
+// Table holds the VRPs for route origin validation.
+//
+// The zero value is ready to use. A Table must not be copied by value.
+// Concurrent reads are safe, writes must be synchronized with
+// reads and other writes by the caller.
+type Table struct {
+	tbl  bart.Table[[]payload]
+	size int
+}
+
+// Add inserts the VRP into the table, duplicates are ignored.
+// The prefix is canonicalized, the max length must be in the range
+// from the prefix length to the address length.
+func (t *Table) Add(v VRP) error {
+	if !v.Prefix.IsValid() {
+		return fmt.Errorf("vrp: invalid prefix %s", v.Prefix)
+	}
+
+	pfx := v.Prefix.Masked()
+	if v.MaxLength < pfx.Bits() || v.MaxLength > pfx.Addr().BitLen() {
+		return fmt.Errorf("vrp: invalid max length %d for %s", v.MaxLength, pfx)
+	}
+
+	p := payload{asn: v.ASN, maxLength: uint8(v.MaxLength)}
+
+	t.tbl.Modify(pfx, func(list []payload, _ bool) ([]payload, bool) {
+		for _, x := range list {
+			if x == p {
+				return list, false
+			}
+		}
+		t.size++
+		return append(list, p), false
+	})
+
+	return nil
+}
+
+// Size returns the number of distinct VRPs in the table.
+func (t *Table) Size() int {
+	return t.size
+}
+
+// Validate returns the origin validation state of the route pfx
+// announced by the origin AS asn, see RFC 6811, section 2.
+//
+// A VRP with AS 0 never matches a route, RFC 6483 section 4.
+func (t *Table) Validate(pfx netip.Prefix, asn uint32) State {
+	if !pfx.IsValid() {
+		return NotFound
+	}
+	pfx = pfx.Masked()
+
+	state := NotFound
+	for _, list := range t.tbl.Supernets(pfx) {
+		state = Invalid
+		for _, p := range list {
+			if p.asn != 0 && p.asn == asn && pfx.Bits() <= int(p.maxLength) {
+				return Valid
+			}
+		}
+	}
+
+	return state
+}
+
+// All returns an iterator over all VRPs in the table,
+// in natural CIDR sort order of the prefixes.
+func (t *Table) All() iter.Seq[VRP] {
+	return func(yield func(VRP) bool) {
+		for pfx, list := range t.tbl.AllSorted() {
+			for _, p := range list {
+				if !yield(VRP{Prefix: pfx, MaxLength: int(p.maxLength), ASN: p.asn}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// parseASN parses "AS13335", "as13335" or "13335".
+func parseASN(s string) (uint32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2 && strings.EqualFold(s[:2], "AS") {
+		s = s[2:]
+	}
+
+	asn, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("vrp: invalid ASN %q", s)
+	}
+
+	return uint32(asn), nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Karl Gaissmaier
+// SPDX-License-Identifier: MIT
+
+package vrp
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+var mpp = netip.MustParsePrefix
+
+const testJSON = `{
+  "metadata": {"buildmachine": "localhost", "roas": 4},
+  "roas": [
+    {"asn": 13335, "prefix": "1.0.0.0/24", "maxLength": 24, "ta": "apnic", "expires": 1700000000},
+    {"asn": "AS65001", "prefix": "10.0.0.0/8", "maxLength": 16, "ta": "ripe"},
+    {"asn": "AS0", "prefix": "192.0.2.0/24", "maxLength": 32, "ta": "arin"},
+    {"asn": 65002, "prefix": "2001:db8::/32", "maxLength": 48, "ta": "ripe"}
+  ]
+}`
+
+const testCSV = `ASN,IP Prefix,Max Length,Trust Anchor
+AS13335,1.0.0.0/24,24,apnic
+AS65001,10.0.0.0/8,16,ripe
+AS0,192.0.2.0/24,32,arin
+AS65002,2001:db8::/32,48,ripe
+AS65002,2001:db8::/32,48,ripe
+`
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	for name, load := range map[string]func(*Table) error{
+		"json": func(tbl *Table) error { return tbl.LoadJSON(strings.NewReader(testJSON)) },
+		"csv":  func(tbl *Table) error { return tbl.LoadCSV(strings.NewReader(testCSV)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tbl := new(Table)
+			if err := load(tbl); err != nil {
+				t.Fatal(err)
+			}
+
+			if tbl.Size() != 4 {
+				t.Fatalf("Size, got: %d, want: 4", tbl.Size())
+			}
+
+			tests := []struct {
+				pfx  string
+				asn  uint32
+				want State
+			}{
+				{"1.0.0.0/24", 13335, Valid},
+				{"1.0.0.0/24", 65001, Invalid},
+				{"1.0.0.0/25", 13335, Invalid}, // too specific
+				{"10.1.0.0/16", 65001, Valid},
+				{"10.1.1.0/24", 65001, Invalid},
+				{"192.0.2.0/24", 0, Invalid}, // AS0 never matches
+				{"192.0.2.0/24", 65001, Invalid},
+				{"2001:db8:1::/48", 65002, Valid},
+				{"2001:db8:1::/64", 65002, Invalid},
+				{"8.8.8.0/24", 15169, NotFound},
+				{"0.0.0.0/0", 13335, NotFound}, // VRPs only cover more specifics
+				{"2001:db9::/32", 65002, NotFound},
+			}
+
+			for _, tt := range tests {
+				if got := tbl.Validate(mpp(tt.pfx), tt.asn); got != tt.want {
+					t.Errorf("Validate(%s, AS%d), got: %v, want: %v", tt.pfx, tt.asn, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAddAndAll(t *testing.T) {
+	t.Parallel()
+
+	tbl := new(Table)
+
+	if err := tbl.Add(VRP{Prefix: mpp("10.0.0.0/8"), MaxLength: 7, ASN: 1}); err == nil {
+		t.Error("Add, expected error for max length < prefix length")
+	}
+	if err := tbl.Add(VRP{Prefix: mpp("10.0.0.0/8"), MaxLength: 33, ASN: 1}); err == nil {
+		t.Error("Add, expected error for max length > 32")
+	}
+	if err := tbl.Add(VRP{MaxLength: 24, ASN: 1}); err == nil {
+		t.Error("Add, expected error for invalid prefix")
+	}
+
+	_ = tbl.Add(VRP{Prefix: mpp("10.0.0.0/8"), MaxLength: 24, ASN: 2})
+	_ = tbl.Add(VRP{Prefix: netip.MustParsePrefix("10.1.2.3/8"), MaxLength: 8, ASN: 1})
+	_ = tbl.Add(VRP{Prefix: mpp("10.0.0.0/8"), MaxLength: 24, ASN: 2})
+
+	var got []VRP
+	for v := range tbl.All() {
+		got = append(got, v)
+	}
+
+	want := []VRP{
+		{mpp("10.0.0.0/8"), 24, 2},
+		{mpp("10.0.0.0/8"), 8, 1},
+	}
+
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("All, got: %v, want: %v", got, want)
+	}
+
+	if !strings.Contains(State(7).String(), "7") || Valid.String() != "Valid" {
+		t.Errorf("State.String, unexpected result")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	jsonTests := []string{
+		`{"metadata": {}}`,
+		`{"roas": [{"asn": "ASxyz", "prefix": "10.0.0.0/8", "maxLength": 8}]}`,
+		`{"roas": [{"asn": 1, "prefix": "10.0.0.0/33", "maxLength": 8}]}`,
+		`{"roas": [{"asn": 1, "prefix": "10.0.0.0/8", "maxLength": 4}]}`,
+		`[`,
+	}
+
+	for _, s := range jsonTests {
+		if err := new(Table).LoadJSON(strings.NewReader(s)); err == nil {
+			t.Errorf("LoadJSON(%s), expected error", s)
+		}
+	}
+
+	csvTests := []string{
+		"AS1,10.0.0.0/8\n",
+		"ASxyz,10.0.0.0/8,8\n",
+		"AS1,10.0.0.0/33,8\n",
+		"AS1,10.0.0.0/8,x\n",
+		"AS1,10.0.0.0/8,4\n",
+		"AS1,\"10.0.0.0/8,8\n",
+	}
+
+	for _, s := range csvTests {
+		if err := new(Table).LoadCSV(strings.NewReader(s)); err == nil {
+			t.Errorf("LoadCSV(%q), expected error", s)
+		}
+	}
+}
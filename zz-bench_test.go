@@ -188,6 +188,46 @@ func BenchmarkFullBartMatch4(b *testing.B) {
 	})
 }
 
+func BenchmarkFullBartBatch(b *testing.B) {
+	bart := new(Table[int])
+	for i, pfx := range tier1.routes() {
+		bart.Insert(pfx, i)
+	}
+
+	prng := rand.New(rand.NewPCG(42, 42))
+	ips := make([]netip.Addr, 1_000)
+	for i := range ips {
+		ips[i] = random.IP(prng)
+	}
+
+	out := make([]int, len(ips))
+	ok := make([]bool, len(ips))
+
+	// baseline for LookupBatch
+	b.Run("LookupLoop", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for i, ip := range ips {
+				out[i], ok[i] = bart.Lookup(ip)
+			}
+		}
+	})
+
+	b.Run("LookupBatch", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			bart.LookupBatch(ips, out, ok)
+		}
+	})
+
+	b.Run("ContainsBatch", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			bart.ContainsBatch(ips, ok)
+		}
+	})
+}
+
 func BenchmarkFullBartMatch6(b *testing.B) {
 	bart := new(Table[struct{}])
 	for _, pfx := range tier1.routes6() {
@@ -290,6 +330,31 @@ func BenchmarkFullBartMiss6(b *testing.B) {
 	})
 }
 
+func BenchmarkFullBartInsert(b *testing.B) {
+	routes := tier1.routes()
+
+	entries := make([]PrefixValue[int], 0, len(routes))
+	for i, pfx := range routes {
+		entries = append(entries, PrefixValue[int]{Prefix: pfx, Value: i})
+	}
+
+	b.Run("Insert", func(b *testing.B) {
+		for b.Loop() {
+			tbl := new(Table[int])
+			for _, e := range entries {
+				tbl.Insert(e.Prefix, e.Value)
+			}
+		}
+	})
+
+	b.Run("InsertBulk", func(b *testing.B) {
+		for b.Loop() {
+			tbl := new(Table[int])
+			tbl.InsertBulk(entries)
+		}
+	})
+}
+
 func BenchmarkBartOverlaps4(b *testing.B) {
 	lt := new(Lite)
 
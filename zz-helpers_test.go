@@ -114,6 +114,12 @@ func noPanicRangeOverFunc[V any](t *testing.T, name string, fn any) {
 		pfx := mpp("1.2.3.4/32")
 		for range iterFunc(pfx) {
 		}
+	case func(netip.Addr) iter.Seq[netip.Prefix]:
+		for range iterFunc(mpa("1.2.3.4")) {
+		}
+	case func(netip.Addr) iter.Seq2[netip.Prefix, V]:
+		for range iterFunc(mpa("1.2.3.4")) {
+		}
 	default:
 		t.Fatalf("%s unknown iter function: %T", name, iterFunc)
 	}